@@ -3,6 +3,7 @@ package config
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"text/tabwriter"
 
@@ -76,6 +77,33 @@ func (opts *Options) loadConfigTolerant(ctx context.Context, extraOverrides ...c
 	return config.Load(ctx, opts.configSource(), overrides...)
 }
 
+// CompleteContexts completes the names of the contexts defined in the configuration file.
+func (opts *Options) CompleteContexts(cmd *cobra.Command, _ []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// The configuration is loaded as-is: the context being completed
+	// doesn't have to be valid, or even selected.
+	cfg, err := config.Load(ctx, opts.configSource())
+	if err != nil {
+		cobra.CompDebugln("could not load the configuration: "+err.Error(), false)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	names := make([]cobra.Completion, 0, len(cfg.Contexts))
+	for name := range cfg.Contexts {
+		if strings.HasPrefix(name, toComplete) {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
 // LoadConfig loads the configuration file (default, or explicitly set via flags) and validates it.
 func (opts *Options) LoadConfig(ctx context.Context) (config.Config, error) {
 	validator := func(cfg *config.Config) error {
@@ -130,6 +158,7 @@ The configuration file to load is chosen as follows:
 	}
 
 	configOpts.BindFlags(cmd.PersistentFlags())
+	_ = cmd.RegisterFlagCompletionFunc("context", configOpts.CompleteContexts)
 
 	cmd.AddCommand(checkCmd(configOpts))
 	cmd.AddCommand(currentContextCmd(configOpts))
@@ -381,6 +410,13 @@ func useContextCmd(configOpts *Options) *cobra.Command {
 		Short:   "Set the current context",
 		Long:    "Set the current context and updates the configuration file.",
 		Example: "\n\tgrafanactl config use-context dev-instance",
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+
+			return configOpts.CompleteContexts(cmd, args, toComplete)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg, err := configOpts.loadConfigTolerant(cmd.Context())
 			if err != nil {
@@ -9,6 +9,7 @@ import (
 
 	"github.com/grafana/grafanactl/internal/config"
 	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/local"
 	k8sapi "k8s.io/apimachinery/pkg/api/errors"
 )
 
@@ -146,6 +147,38 @@ func convertResourcesErrors(err error) (*DetailedError, bool) {
 		}, true
 	}
 
+	templateErr := local.TemplateError{}
+	if errors.As(err, &templateErr) {
+		location := templateErr.File
+		if templateErr.Line > 0 {
+			location = fmt.Sprintf("%s:%d", templateErr.File, templateErr.Line)
+		}
+
+		return &DetailedError{
+			Parent:  templateErr.Err,
+			Summary: "Could not render resource template",
+			Details: fmt.Sprintf("Template error found in '%s'", location),
+			Suggestions: []string{
+				"Define the missing values with --set or --values, or the missing environment variables",
+				"Escape literal environment variable references as $${VAR}",
+			},
+		}, true
+	}
+
+	parseErr := local.ParseError{}
+	if errors.As(err, &parseErr) {
+		location := parseErr.File
+		if parseErr.Line > 0 {
+			location = fmt.Sprintf("%s:%d", parseErr.File, parseErr.Line)
+		}
+
+		return &DetailedError{
+			Parent:  parseErr.Err,
+			Summary: "Could not parse resource",
+			Details: fmt.Sprintf("Invalid resource found in '%s'", location),
+		}, true
+	}
+
 	return nil, false
 }
 
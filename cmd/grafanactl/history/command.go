@@ -0,0 +1,147 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/grafana/grafanactl/cmd/grafanactl/fail"
+	cmdio "github.com/grafana/grafanactl/cmd/grafanactl/io"
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources/journal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type historyOpts struct {
+	IO cmdio.Options
+}
+
+func (opts *historyOpts) setup(flags *pflag.FlagSet) {
+	opts.IO.RegisterCustomCodec("text", &historyTabCodec{})
+	opts.IO.DefaultFormat("text")
+	opts.IO.BindFlags(flags)
+}
+
+func (opts *historyOpts) Validate() error {
+	return opts.IO.Validate()
+}
+
+// Command returns the command listing the operations recorded in the journal.
+func Command() *cobra.Command {
+	opts := &historyOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "journal [OPERATION_ID]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "List the operations made to resources in Grafana",
+		Long: fmt.Sprintf(`List the operations made to resources in Grafana.
+
+Every 'resources push' and 'resources delete' records the state of the resources it
+changed in a journal, so that it can be reverted with 'grafanactl undo'. The last %d
+operations are kept, in $XDG_STATE_HOME/grafanactl/journal.
+
+Given an operation ID, the resources changed by the operation are listed.`, journal.MaxOperations),
+		Example: `
+	# List the operations
+	grafanactl journal
+
+	# List the resources changed by an operation
+	grafanactl journal 20260102-150405-a1b2`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+
+			codec, err := opts.IO.Codec()
+			if err != nil {
+				return err
+			}
+
+			store := journal.Journal{Dir: journal.DefaultDir()}
+
+			if len(args) == 1 {
+				op, err := getOperation(store, args[0])
+				if err != nil {
+					return err
+				}
+
+				return codec.Encode(cmd.OutOrStdout(), op)
+			}
+
+			operations, err := store.List()
+			if err != nil {
+				return err
+			}
+
+			return codec.Encode(cmd.OutOrStdout(), operations)
+		},
+	}
+
+	opts.setup(cmd.Flags())
+
+	return cmd
+}
+
+func getOperation(store journal.Journal, id string) (*journal.Operation, error) {
+	op, err := store.Get(id)
+	if errors.Is(err, journal.ErrNotFound) {
+		return nil, fail.DetailedError{
+			Summary: "Operation not found",
+			Details: fmt.Sprintf("No operation '%s' in the journal.", id),
+			Suggestions: []string{
+				"List the recorded operations with 'grafanactl journal'",
+			},
+		}
+	}
+
+	return op, err
+}
+
+type historyTabCodec struct{}
+
+func (c *historyTabCodec) Format() format.Format {
+	return "text"
+}
+
+func (c *historyTabCodec) Encode(output io.Writer, input any) error {
+	out := tabwriter.NewWriter(output, 0, 4, 2, ' ', tabwriter.TabIndent|tabwriter.DiscardEmptyColumns)
+
+	switch value := input.(type) {
+	case []*journal.Operation:
+		fmt.Fprintf(out, "ID\tTIME\tCOMMAND\tCONTEXT\tCHANGES\tSTATUS\n")
+
+		for _, op := range value {
+			fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%d\t%s\n",
+				op.ID, op.Time.Local().Format(time.DateTime), op.Command, op.Context, len(op.Changes), operationStatus(op),
+			)
+		}
+	case *journal.Operation:
+		fmt.Fprintf(out, "ACTION\tKIND\tNAME\n")
+
+		for _, change := range value.Changes {
+			fmt.Fprintf(out, "%s\t%s\t%s\n", change.Action, change.Kind, change.Name)
+		}
+	default:
+		return fmt.Errorf("expected journal operations, got %T", input)
+	}
+
+	return out.Flush()
+}
+
+func (c *historyTabCodec) Decode(io.Reader, any) error {
+	return errors.New("tab codec does not support decoding")
+}
+
+func operationStatus(op *journal.Operation) string {
+	switch {
+	case op.UndoneBy != "":
+		return "undone by " + op.UndoneBy
+	case op.Undoes != "":
+		return "undoes " + op.Undoes
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,264 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	cmdconfig "github.com/grafana/grafanactl/cmd/grafanactl/config"
+	"github.com/grafana/grafanactl/cmd/grafanactl/fail"
+	cmdio "github.com/grafana/grafanactl/cmd/grafanactl/io"
+	"github.com/grafana/grafanactl/internal/config"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/journal"
+	"github.com/grafana/grafanactl/internal/resources/remote"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type undoOpts struct {
+	DryRun        bool
+	MaxConcurrent int
+}
+
+func (opts *undoOpts) setup(flags *pflag.FlagSet) {
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun, "If set, the operation will be simulated")
+	flags.IntVar(&opts.MaxConcurrent, "max-concurrent", 10, "Maximum number of concurrent operations")
+}
+
+func (opts *undoOpts) Validate() error {
+	if opts.MaxConcurrent < 1 {
+		return errors.New("max-concurrent must be greater than zero")
+	}
+
+	return nil
+}
+
+// UndoCommand returns the command reverting an operation recorded in the journal.
+func UndoCommand() *cobra.Command {
+	opts := &undoOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "undo [OPERATION_ID]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "Revert an operation made to resources in Grafana",
+		Long: `Revert an operation made to resources in Grafana.
+
+Resources updated or deleted by the operation are restored to their previous state,
+and resources created by the operation are deleted. Without an operation ID, the last
+operation made to the Grafana instance of the current context that wasn't already
+undone is reverted.
+
+Undoing an operation is itself recorded in the journal: it can be undone to apply the
+original operation again.`,
+		Example: `
+	# Revert the last push or delete
+	grafanactl undo
+
+	# Revert a given operation
+	grafanactl undo 20260102-150405-a1b2`,
+	}
+
+	configOpts := &cmdconfig.Options{}
+	configOpts.BindFlags(cmd.Flags())
+	_ = cmd.RegisterFlagCompletionFunc("context", configOpts.CompleteContexts)
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if err := opts.Validate(); err != nil {
+			return err
+		}
+
+		return undo(cmd.Context(), cmd.OutOrStdout(), configOpts, opts, args)
+	}
+
+	opts.setup(cmd.Flags())
+
+	return cmd
+}
+
+func undo(ctx context.Context, output io.Writer, configOpts *cmdconfig.Options, opts *undoOpts, args []string) error {
+	fullConfig, err := configOpts.LoadConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := configOpts.LoadRESTConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	store := journal.Journal{Dir: journal.DefaultDir()}
+
+	op, err := operationToUndo(store, cfg, args)
+	if err != nil {
+		return err
+	}
+
+	if op.UndoneBy != "" {
+		return fail.DetailedError{
+			Summary: "Operation already undone",
+			Details: fmt.Sprintf("Operation %s was undone by operation %s.", op.ID, op.UndoneBy),
+			Suggestions: []string{
+				fmt.Sprintf("Apply the operation again with 'grafanactl undo %s'", op.UndoneBy),
+			},
+		}
+	}
+
+	if op.Server != cfg.Host || op.Namespace != cfg.Namespace {
+		return fail.DetailedError{
+			Summary: "Operation made on another Grafana instance",
+			Details: fmt.Sprintf("Operation %s was made on %s (namespace %s), in the context '%s'.", op.ID, op.Server, op.Namespace, op.Context),
+			Suggestions: []string{
+				fmt.Sprintf("Select the context of the operation with --context %s", op.Context),
+			},
+		}
+	}
+
+	restore, remove, err := op.Reversal()
+	if err != nil {
+		return err
+	}
+
+	if opts.DryRun {
+		cmdio.Info(output, "Dry-run mode enabled")
+	}
+
+	reversal := journal.NewOperation("undo", fullConfig.CurrentContext, cfg)
+	reversal.Undoes = op.ID
+
+	var recorder remote.ChangeRecorder
+	if !opts.DryRun {
+		recorder = reversal
+	}
+
+	pusher, err := remote.NewDefaultPusher(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	restored, err := pusher.Push(ctx, remote.PushRequest{
+		Resources:        restore,
+		MaxConcurrency:   opts.MaxConcurrent,
+		DryRun:           opts.DryRun,
+		NoPushFailureLog: true,
+		// Resources are restored as they were, whatever their manager.
+		IncludeManaged: true,
+		Recorder:       recorder,
+		OnPush: func(res *resources.Resource, action remote.PushAction) {
+			if action == remote.PushActionNotValidated {
+				cmdio.Warning(output, "%s/%s not validated: the legacy HTTP API does not support dry-runs", res.Kind(), res.Name())
+				return
+			}
+
+			cmdio.Success(output, "%s/%s restored", res.Kind(), res.Name())
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	deleter, err := remote.NewDeleter(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	// Created resources are deleted one at a time, children before their parents,
+	// as a folder can not be deleted while it still holds resources.
+	deletedCount := 0
+	failures := restored.Failures()
+	for _, res := range remove {
+		deleted, err := deleter.Delete(ctx, remote.DeleteRequest{
+			Resources:      resources.NewResources(res),
+			MaxConcurrency: 1,
+			DryRun:         opts.DryRun,
+			Recorder:       recorder,
+		})
+		if err != nil {
+			return err
+		}
+
+		deletedCount += deleted.SuccessCount()
+		failures = append(failures, deleted.Failures()...)
+	}
+
+	for _, failure := range failures {
+		if failure.Resource == nil {
+			cmdio.Error(output, "%s", failure.Error)
+			continue
+		}
+
+		cmdio.Error(output, "%s/%s: %s", failure.Resource.Kind(), failure.Resource.Name(), failure.Error)
+	}
+
+	if !opts.DryRun {
+		saveReversal(output, store, op, reversal, len(failures) == 0)
+	}
+
+	printer := cmdio.Success
+	if len(failures) != 0 {
+		printer = cmdio.Warning
+	}
+
+	printer(output, "%d resources restored, %d resources deleted, %d errors",
+		restored.SuccessCount(), deletedCount, len(failures),
+	)
+
+	if len(failures) != 0 {
+		return fmt.Errorf("%d resource(s) could not be reverted", len(failures))
+	}
+
+	return nil
+}
+
+// operationToUndo returns the operation designated by the arguments, or the last
+// operation made to the given instance that can be undone.
+func operationToUndo(store journal.Journal, cfg config.NamespacedRESTConfig, args []string) (*journal.Operation, error) {
+	if len(args) == 1 {
+		return getOperation(store, args[0])
+	}
+
+	operations, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range operations {
+		if op.Server != cfg.Host || op.Namespace != cfg.Namespace {
+			continue
+		}
+
+		// Undoing an undo is done explicitly, by ID.
+		if op.UndoneBy == "" && op.Undoes == "" {
+			return op, nil
+		}
+	}
+
+	return nil, fail.DetailedError{
+		Summary: "Nothing to undo",
+		Details: "No operation made to the Grafana instance of the current context can be undone.",
+		Suggestions: []string{
+			"List the recorded operations with 'grafanactl journal'",
+		},
+	}
+}
+
+// saveReversal records the reversal of an operation in the journal.
+// The operation is only marked as undone if it was entirely reverted.
+func saveReversal(output io.Writer, store journal.Journal, op *journal.Operation, reversal *journal.Operation, complete bool) {
+	if !reversal.IsEmpty() {
+		if err := store.Save(reversal); err != nil {
+			cmdio.Warning(output, "The operation could not be recorded in the journal: %s", err)
+			return
+		}
+	}
+
+	if !complete || reversal.IsEmpty() {
+		return
+	}
+
+	op.UndoneBy = reversal.ID
+	if err := store.Save(op); err != nil {
+		cmdio.Warning(output, "The operation could not be marked as undone in the journal: %s", err)
+	}
+}
@@ -11,11 +11,17 @@ import (
 	"github.com/spf13/pflag"
 )
 
+// Options describes the output format of a command.
+//
+// On top of the built-in and custom codecs, commands get the jsonpath,
+// go-template, custom-columns and name printers, unless DisablePrinters is
+// called: printers can only encode data, not be read back.
 type Options struct {
 	OutputFormat string
 
-	customCodecs  map[string]format.Codec
-	defaultFormat string
+	customCodecs     map[string]format.Codec
+	defaultFormat    string
+	printersDisabled bool
 }
 
 func (opts *Options) RegisterCustomCodec(name string, codec format.Codec) {
@@ -30,6 +36,12 @@ func (opts *Options) DefaultFormat(name string) {
 	opts.defaultFormat = name
 }
 
+// DisablePrinters restricts the output formats to the ones that can be decoded,
+// for commands writing files that are read again later.
+func (opts *Options) DisablePrinters() {
+	opts.printersDisabled = true
+}
+
 func (opts *Options) BindFlags(flags *pflag.FlagSet) {
 	defaultFormat := "json"
 	if opts.defaultFormat != "" {
@@ -40,19 +52,19 @@ func (opts *Options) BindFlags(flags *pflag.FlagSet) {
 }
 
 func (opts *Options) Validate() error {
-	codec := opts.codecFor(opts.OutputFormat)
-	if codec == nil {
-		return fmt.Errorf("unknown output format '%s'. Valid formats are: %s", opts.OutputFormat, strings.Join(opts.allowedCodecs(), ", "))
-	}
-
-	return nil
+	_, err := opts.Codec()
+	return err
 }
 
 // We have to return an interface here.
 //
 //nolint:ireturn
 func (opts *Options) Codec() (format.Codec, error) {
-	codec := opts.codecFor(opts.OutputFormat)
+	codec, err := opts.codecFor(opts.OutputFormat)
+	if err != nil {
+		return nil, err
+	}
+
 	if codec == nil {
 		return nil, fmt.Errorf(
 			"unknown output format '%s'. Valid formats are: %s", opts.OutputFormat, strings.Join(opts.allowedCodecs(), ", "),
@@ -65,12 +77,20 @@ func (opts *Options) Codec() (format.Codec, error) {
 // We have to return an interface here.
 //
 //nolint:ireturn
-func (opts *Options) codecFor(format string) format.Codec {
-	if opts.customCodecs != nil && opts.customCodecs[format] != nil {
-		return opts.customCodecs[format]
+func (opts *Options) codecFor(name string) (format.Codec, error) {
+	if opts.customCodecs != nil && opts.customCodecs[name] != nil {
+		return opts.customCodecs[name], nil
 	}
 
-	return opts.builtinCodecs()[format]
+	if codec, ok := opts.builtinCodecs()[name]; ok {
+		return codec, nil
+	}
+
+	if opts.printersDisabled {
+		return nil, nil //nolint:nilnil
+	}
+
+	return printerFor(name)
 }
 
 func (opts *Options) builtinCodecs() map[string]format.Codec {
@@ -86,9 +106,21 @@ func (opts *Options) allowedCodecs() []string {
 		allowedCodecs = append(allowedCodecs, name)
 	}
 
+	if !opts.printersDisabled {
+		allowedCodecs = append(allowedCodecs, NameFormat)
+	}
+
 	// the allowed codecs are stored in a map: let's sort them to make the
 	// return value of this function deterministic
 	sort.Strings(allowedCodecs)
 
+	if !opts.printersDisabled {
+		allowedCodecs = append(allowedCodecs,
+			JSONPathFormat+"=...",
+			GoTemplateFormat+"=...",
+			CustomColumnsFormat+"=...",
+		)
+	}
+
 	return allowedCodecs
 }
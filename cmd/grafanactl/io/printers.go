@@ -0,0 +1,347 @@
+package io
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/grafana/grafanactl/internal/format"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// Printers are output-only formats, configured by an argument given after
+// their name: `-o jsonpath={.metadata.name}`.
+const (
+	JSONPathFormat      = "jsonpath"
+	GoTemplateFormat    = "go-template"
+	CustomColumnsFormat = "custom-columns"
+	NameFormat          = "name"
+)
+
+// printers maps the name of the printers to their constructor.
+//
+//nolint:gochecknoglobals
+var printers = map[string]func(arg string) (format.Codec, error){
+	JSONPathFormat:      newJSONPathCodec,
+	GoTemplateFormat:    newGoTemplateCodec,
+	CustomColumnsFormat: newCustomColumnsCodec,
+}
+
+// printerFor returns the printer described by an output format of the form `name=argument`.
+// It returns nil if the output format doesn't designate a printer.
+//
+//nolint:ireturn
+func printerFor(outputFormat string) (format.Codec, error) {
+	name, arg, hasArg := strings.Cut(outputFormat, "=")
+
+	if name == NameFormat && !hasArg {
+		return &nameCodec{}, nil
+	}
+
+	constructor, ok := printers[name]
+	if !ok {
+		return nil, nil //nolint:nilnil
+	}
+
+	if !hasArg || arg == "" {
+		return nil, fmt.Errorf("output format '%s' requires an argument: -o %s=...", name, name)
+	}
+
+	codec, err := constructor(arg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s output format: %w", name, err)
+	}
+
+	return codec, nil
+}
+
+// NewJSONPath parses a JSONPath template, as used by kubectl.
+// The surrounding braces are optional for templates made of a single expression:
+// `.metadata.name` is equivalent to `{.metadata.name}`.
+func NewJSONPath(expr string) (*jsonpath.JSONPath, error) {
+	parser := jsonpath.New("output").AllowMissingKeys(true)
+	if err := parser.Parse(relaxedJSONPath(expr)); err != nil {
+		return nil, err
+	}
+
+	return parser, nil
+}
+
+var jsonPathExprRegexp = regexp.MustCompile(`^\{\.?([^{}]+)\}$|^\.?([^{}]+)$`)
+
+func relaxedJSONPath(expr string) string {
+	if strings.Contains(expr, "{") {
+		return expr
+	}
+
+	submatches := jsonPathExprRegexp.FindStringSubmatch(expr)
+	if submatches == nil {
+		return expr
+	}
+
+	return "{." + submatches[2] + "}"
+}
+
+// printable converts a value into its generic JSON representation
+// (maps, slices, strings, float64, ...), which printers operate on.
+func printable(input any) (any, error) {
+	switch typed := input.(type) {
+	case unstructured.UnstructuredList:
+		items := make([]any, 0, len(typed.Items))
+		for _, item := range typed.Items {
+			items = append(items, item.Object)
+		}
+		return map[string]any{"items": items}, nil
+	case *unstructured.UnstructuredList:
+		return printable(*typed)
+	case unstructured.Unstructured:
+		input = typed.Object
+	case *unstructured.Unstructured:
+		input = typed.Object
+	}
+
+	raw, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var out any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// printableItems returns the items of a list, or the value itself if it isn't a list.
+func printableItems(input any) ([]any, error) {
+	value, err := printable(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if object, ok := value.(map[string]any); ok {
+		if items, ok := object["items"].([]any); ok {
+			return items, nil
+		}
+	}
+
+	if items, ok := value.([]any); ok {
+		return items, nil
+	}
+
+	return []any{value}, nil
+}
+
+type jsonPathCodec struct {
+	parser *jsonpath.JSONPath
+}
+
+//nolint:ireturn
+func newJSONPathCodec(expr string) (format.Codec, error) {
+	parser, err := NewJSONPath(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jsonPathCodec{parser: parser}, nil
+}
+
+func (c *jsonPathCodec) Format() format.Format {
+	return JSONPathFormat
+}
+
+func (c *jsonPathCodec) Encode(output io.Writer, input any) error {
+	value, err := printable(input)
+	if err != nil {
+		return err
+	}
+
+	return c.parser.Execute(output, value)
+}
+
+func (c *jsonPathCodec) Decode(io.Reader, any) error {
+	return errors.New("jsonpath codec does not support decoding")
+}
+
+type goTemplateCodec struct {
+	tmpl *template.Template
+}
+
+//nolint:ireturn
+func newGoTemplateCodec(text string) (format.Codec, error) {
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	return &goTemplateCodec{tmpl: tmpl}, nil
+}
+
+func (c *goTemplateCodec) Format() format.Format {
+	return GoTemplateFormat
+}
+
+func (c *goTemplateCodec) Encode(output io.Writer, input any) error {
+	value, err := printable(input)
+	if err != nil {
+		return err
+	}
+
+	return c.tmpl.Execute(output, value)
+}
+
+func (c *goTemplateCodec) Decode(io.Reader, any) error {
+	return errors.New("go-template codec does not support decoding")
+}
+
+type column struct {
+	header string
+	parser *jsonpath.JSONPath
+}
+
+type customColumnsCodec struct {
+	columns []column
+}
+
+//nolint:ireturn
+func newCustomColumnsCodec(spec string) (format.Codec, error) {
+	parts := strings.Split(spec, ",")
+	columns := make([]column, 0, len(parts))
+
+	for _, part := range parts {
+		header, expr, ok := strings.Cut(part, ":")
+		if !ok || header == "" || expr == "" {
+			return nil, fmt.Errorf("expected HEADER:JSONPATH, got '%s'", part)
+		}
+
+		parser, err := NewJSONPath(expr)
+		if err != nil {
+			return nil, fmt.Errorf("column '%s': %w", header, err)
+		}
+
+		columns = append(columns, column{header: header, parser: parser})
+	}
+
+	return &customColumnsCodec{columns: columns}, nil
+}
+
+func (c *customColumnsCodec) Format() format.Format {
+	return CustomColumnsFormat
+}
+
+func (c *customColumnsCodec) Encode(output io.Writer, input any) error {
+	items, err := printableItems(input)
+	if err != nil {
+		return err
+	}
+
+	out := tabwriter.NewWriter(output, 0, 4, 3, ' ', 0)
+
+	headers := make([]string, 0, len(c.columns))
+	for _, col := range c.columns {
+		headers = append(headers, col.header)
+	}
+	fmt.Fprintln(out, strings.Join(headers, "\t"))
+
+	for _, item := range items {
+		cells := make([]string, 0, len(c.columns))
+
+		for _, col := range c.columns {
+			cell, err := columnValue(col.parser, item)
+			if err != nil {
+				return fmt.Errorf("column '%s': %w", col.header, err)
+			}
+
+			cells = append(cells, cell)
+		}
+
+		fmt.Fprintln(out, strings.Join(cells, "\t"))
+	}
+
+	return out.Flush()
+}
+
+func (c *customColumnsCodec) Decode(io.Reader, any) error {
+	return errors.New("custom-columns codec does not support decoding")
+}
+
+func columnValue(parser *jsonpath.JSONPath, item any) (string, error) {
+	results, err := parser.FindResults(item)
+	if err != nil {
+		return "", err
+	}
+
+	values := make([]string, 0)
+	for _, result := range results {
+		for _, value := range result {
+			if !value.IsValid() || (value.Kind() == reflect.Interface && value.IsNil()) {
+				continue
+			}
+
+			buffer := &bytes.Buffer{}
+			if err := parser.PrintResults(buffer, []reflect.Value{value}); err != nil {
+				return "", err
+			}
+
+			values = append(values, buffer.String())
+		}
+	}
+
+	if len(values) == 0 {
+		return "<none>", nil
+	}
+
+	return strings.Join(values, ","), nil
+}
+
+// nameCodec prints resources as selectors that can be given to other commands:
+// `dashboard.v1.dashboard.grafana.app/foo`.
+type nameCodec struct{}
+
+func (c *nameCodec) Format() format.Format {
+	return NameFormat
+}
+
+func (c *nameCodec) Encode(output io.Writer, input any) error {
+	items, err := printableItems(input)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		object, ok := item.(map[string]any)
+		if !ok {
+			return errors.New("the name output format is only supported for resources")
+		}
+
+		resource := unstructured.Unstructured{Object: object}
+		gvk := resource.GroupVersionKind()
+		if gvk.Kind == "" || resource.GetName() == "" {
+			return errors.New("the name output format is only supported for resources")
+		}
+
+		selector := strings.ToLower(gvk.Kind)
+		if gvk.Group != "" {
+			selector += "." + gvk.Version + "." + gvk.Group
+		}
+
+		if _, err := fmt.Fprintf(output, "%s/%s\n", selector, resource.GetName()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *nameCodec) Decode(io.Reader, any) error {
+	return errors.New("name codec does not support decoding")
+}
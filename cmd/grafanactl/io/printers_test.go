@@ -0,0 +1,137 @@
+package io_test
+
+import (
+	"bytes"
+	"testing"
+
+	cmdio "github.com/grafana/grafanactl/cmd/grafanactl/io"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func dashboards() unstructured.UnstructuredList {
+	dashboard := func(name string, title string) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "dashboard.grafana.app/v1",
+			"kind":       "Dashboard",
+			"metadata":   map[string]any{"name": name},
+			"spec":       map[string]any{"title": title},
+		}}
+	}
+
+	return unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{
+			dashboard("foo", "Foo"),
+			{Object: map[string]any{
+				"apiVersion": "dashboard.grafana.app/v1",
+				"kind":       "Dashboard",
+				"metadata":   map[string]any{"name": "bar"},
+			}},
+		},
+	}
+}
+
+func encode(t *testing.T, outputFormat string, input any) string {
+	t.Helper()
+
+	opts := cmdio.Options{OutputFormat: outputFormat}
+	require.NoError(t, opts.Validate())
+
+	codec, err := opts.Codec()
+	require.NoError(t, err)
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, codec.Encode(buffer, input))
+
+	return buffer.String()
+}
+
+func TestOptions_Printers(t *testing.T) {
+	tests := []struct {
+		name         string
+		outputFormat string
+		input        any
+		want         string
+	}{
+		{
+			name:         "jsonpath on a list",
+			outputFormat: `jsonpath={range .items[*]}{.metadata.name}{"\n"}{end}`,
+			input:        dashboards(),
+			want:         "foo\nbar\n",
+		},
+		{
+			name:         "jsonpath without braces",
+			outputFormat: "jsonpath=.spec.title",
+			input:        dashboards().Items[0].Object,
+			want:         "Foo",
+		},
+		{
+			name:         "jsonpath on a struct",
+			outputFormat: "jsonpath={.items[0].spec.title}",
+			input: struct {
+				Items []map[string]any `json:"items"`
+			}{Items: []map[string]any{dashboards().Items[0].Object}},
+			want: "Foo",
+		},
+		{
+			name:         "go-template",
+			outputFormat: `go-template={{range .items}}{{.metadata.name}} {{end}}`,
+			input:        dashboards(),
+			want:         "foo bar ",
+		},
+		{
+			name:         "custom-columns",
+			outputFormat: "custom-columns=NAME:.metadata.name,TITLE:{.spec.title}",
+			input:        dashboards(),
+			want:         "NAME   TITLE\nfoo    Foo\nbar    <none>\n",
+		},
+		{
+			name:         "custom-columns on a single resource",
+			outputFormat: "custom-columns=NAME:.metadata.name",
+			input:        dashboards().Items[0].Object,
+			want:         "NAME\nfoo\n",
+		},
+		{
+			name:         "name",
+			outputFormat: "name",
+			input:        dashboards(),
+			want:         "dashboard.v1.dashboard.grafana.app/foo\ndashboard.v1.dashboard.grafana.app/bar\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, encode(t, tc.outputFormat, tc.input))
+		})
+	}
+}
+
+func TestOptions_PrintersValidation(t *testing.T) {
+	tests := []struct {
+		outputFormat string
+		wantErr      string
+	}{
+		{outputFormat: "jsonpath", wantErr: "output format 'jsonpath' requires an argument: -o jsonpath=..."},
+		{outputFormat: "jsonpath={.metadata.name", wantErr: "invalid jsonpath output format: unclosed action"},
+		{outputFormat: "go-template={{.foo", wantErr: "invalid go-template output format: template: output:1: unclosed action"},
+		{outputFormat: "custom-columns=NAME", wantErr: "invalid custom-columns output format: expected HEADER:JSONPATH, got 'NAME'"},
+		{outputFormat: "name=foo", wantErr: "unknown output format 'name=foo'"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.outputFormat, func(t *testing.T) {
+			opts := cmdio.Options{OutputFormat: tc.outputFormat}
+			err := opts.Validate()
+
+			require.Error(t, err)
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestOptions_DisablePrinters(t *testing.T) {
+	opts := cmdio.Options{OutputFormat: "name"}
+	opts.DisablePrinters()
+
+	require.EqualError(t, opts.Validate(), "unknown output format 'name'. Valid formats are: json, yaml")
+}
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/grafana/grafanactl/cmd/grafanactl/fail"
+	"github.com/grafana/grafanactl/cmd/grafanactl/plugin"
 	"github.com/grafana/grafanactl/cmd/grafanactl/root"
+	"github.com/grafana/grafanactl/internal/plugins"
 )
 
 // Version variables which are set at build time.
@@ -18,7 +21,10 @@ var (
 )
 
 func main() {
-	handleError(root.Command(formatVersion()).Execute())
+	rootCmd := root.Command(formatVersion())
+	plugin.AddCommands(rootCmd)
+
+	handleError(rootCmd.Execute())
 }
 
 func handleError(err error) {
@@ -26,6 +32,12 @@ func handleError(err error) {
 		return
 	}
 
+	// Plugins report their own errors.
+	pluginErr := plugins.ExitError{}
+	if errors.As(err, &pluginErr) {
+		os.Exit(pluginErr.Code)
+	}
+
 	exitCode := 1
 	detailedErr := fail.ErrorToDetailedError(err)
 
@@ -0,0 +1,293 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	cmdconfig "github.com/grafana/grafanactl/cmd/grafanactl/config"
+	cmdio "github.com/grafana/grafanactl/cmd/grafanactl/io"
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/logs"
+	"github.com/grafana/grafanactl/internal/plugins"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// pathAnnotation marks the commands provided by plugins, with the path of their executable.
+const pathAnnotation = "grafanactl.grafana.app/plugin-path"
+
+// Command returns the command managing plugins.
+func Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plugin",
+		Short: "Manage grafanactl plugins",
+		Long: fmt.Sprintf(`Manage grafanactl plugins.
+
+Any executable named '%[1]s<name>' found in $PATH is a plugin, run as 'grafanactl <name>'.
+Plugins are given their arguments as-is, and the current context through environment variables:
+
+  %[2]s     path of the configuration file in use
+  %[3]s    name of the current context
+  %[4]s     address of the Grafana server
+  %[5]s  namespace targeted by the context
+  %[6]s   file descriptor (handle on Windows) from which the API token can be read, if one is configured
+  %[7]s        path of the grafanactl executable
+
+The --config and --context flags given before the other arguments of a plugin select
+the context given to it, as for built-in commands: they are not passed to the plugin.
+
+Plugins can't override built-in commands. When several executables provide the same
+plugin, the first one found in $PATH is used.`,
+			plugins.Prefix, plugins.ConfigEnvVar, plugins.ContextEnvVar, plugins.ServerEnvVar,
+			plugins.NamespaceEnvVar, plugins.TokenFDEnvVar, plugins.BinaryEnvVar,
+		),
+	}
+
+	cmd.AddCommand(listCmd())
+
+	return cmd
+}
+
+type listOpts struct {
+	IO cmdio.Options
+}
+
+func (opts *listOpts) setup(flags *pflag.FlagSet) {
+	opts.IO.RegisterCustomCodec("text", &pluginsTabCodec{})
+	opts.IO.DefaultFormat("text")
+	opts.IO.BindFlags(flags)
+}
+
+func (opts *listOpts) Validate() error {
+	return opts.IO.Validate()
+}
+
+type pluginInfo struct {
+	Name string `json:"name" yaml:"name"`
+	Path string `json:"path" yaml:"path"`
+	// Shadowed lists the executables ignored in favor of this plugin.
+	Shadowed []string `json:"shadowed,omitempty" yaml:"shadowed,omitempty"`
+	// Builtin is true if the plugin is ignored in favor of a built-in command.
+	Builtin bool `json:"builtin,omitempty" yaml:"builtin,omitempty"`
+}
+
+func listCmd() *cobra.Command {
+	opts := &listOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Args:  cobra.NoArgs,
+		Short: "List the plugins found in $PATH",
+		Long: `List the plugins found in $PATH.
+
+A warning is printed for every plugin that is not run: plugins named after a built-in
+command, and executables shadowed by another one found earlier in $PATH.`,
+		Example: `
+	# List the installed plugins
+	grafanactl plugin list`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+
+			codec, err := opts.IO.Codec()
+			if err != nil {
+				return err
+			}
+
+			builtins := builtinCommands(cmd.Root())
+			found := plugins.Discover(os.Getenv("PATH"))
+
+			items := make([]pluginInfo, 0, len(found))
+			for _, plugin := range found {
+				_, builtin := builtins[plugin.Name]
+				items = append(items, pluginInfo{
+					Name:     plugin.Name,
+					Path:     plugin.Path,
+					Shadowed: plugin.Shadowed,
+					Builtin:  builtin,
+				})
+
+				if builtin {
+					cmdio.Warning(cmd.ErrOrStderr(), "%s is ignored: it is shadowed by the built-in command '%s'", plugin.Path, plugin.Name)
+				}
+
+				for _, shadowed := range plugin.Shadowed {
+					cmdio.Warning(cmd.ErrOrStderr(), "%s is ignored: it is shadowed by %s", shadowed, plugin.Path)
+				}
+			}
+
+			if len(items) == 0 && opts.IO.OutputFormat == "text" {
+				cmdio.Info(cmd.OutOrStdout(), "No plugins found in $PATH.")
+				return nil
+			}
+
+			return codec.Encode(cmd.OutOrStdout(), items)
+		},
+	}
+
+	opts.setup(cmd.Flags())
+
+	return cmd
+}
+
+// AddCommands adds a command to root for every plugin found in $PATH,
+// unless its name is taken by a built-in command.
+func AddCommands(root *cobra.Command) {
+	builtins := builtinCommands(root)
+
+	for _, plugin := range plugins.Discover(os.Getenv("PATH")) {
+		if _, builtin := builtins[plugin.Name]; builtin {
+			continue
+		}
+
+		root.AddCommand(pluginCmd(plugin))
+	}
+}
+
+func pluginCmd(plugin plugins.Plugin) *cobra.Command {
+	configOpts := &cmdconfig.Options{}
+
+	return &cobra.Command{
+		Use:                plugin.Name,
+		Short:              "Plugin provided by " + plugin.Path,
+		DisableFlagParsing: true,
+		Annotations: map[string]string{
+			pathAnnotation: plugin.Path,
+		},
+		ValidArgsFunction: func(*cobra.Command, []string, string) ([]cobra.Completion, cobra.ShellCompDirective) {
+			return nil, cobra.ShellCompDirectiveDefault
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			args, err := parseConfigFlags(configOpts, args)
+			if err != nil {
+				return err
+			}
+
+			pluginCtx, err := pluginContext(cmd, configOpts)
+			if err != nil {
+				return err
+			}
+
+			return plugins.Run(cmd.Context(), plugin, args, pluginCtx)
+		},
+	}
+}
+
+// parseConfigFlags consumes the --config and --context flags leading the arguments
+// of a plugin, given before or right after its name: they select the context given
+// to the plugin, and are not passed to it.
+func parseConfigFlags(configOpts *cmdconfig.Options, args []string) ([]string, error) {
+	for len(args) != 0 {
+		name, value, hasValue := strings.Cut(args[0], "=")
+		if name != "--config" && name != "--context" {
+			return args, nil
+		}
+
+		args = args[1:]
+		if !hasValue {
+			if len(args) == 0 {
+				return nil, fmt.Errorf("flag needs an argument: %s", name)
+			}
+
+			value, args = args[0], args[1:]
+		}
+
+		if name == "--config" {
+			configOpts.ConfigFile = value
+		} else {
+			configOpts.Context = value
+		}
+	}
+
+	return args, nil
+}
+
+// pluginContext resolves the context given to plugins.
+// Plugins can run without a valid configuration: in that case, no context is given,
+// unless a configuration file or a context was explicitly selected.
+func pluginContext(cmd *cobra.Command, configOpts *cmdconfig.Options) (plugins.Context, error) {
+	ctx := cmd.Context()
+
+	cfg, err := configOpts.LoadConfig(ctx)
+	if err != nil {
+		if configOpts.ConfigFile != "" || configOpts.Context != "" {
+			return plugins.Context{}, err
+		}
+
+		logging.FromContext(ctx).Debug("Running plugin without context", logs.Err(err))
+		return plugins.Context{}, nil
+	}
+
+	current := cfg.GetCurrentContext()
+	logging.FromContext(ctx).Debug("Running plugin", slog.String("context", current.Name))
+
+	return plugins.Context{
+		ConfigFile: cfg.Source,
+		Name:       current.Name,
+		Server:     current.Grafana.Server,
+		Namespace:  current.ToRESTConfig(ctx).Namespace,
+		Token:      current.Grafana.APIToken,
+	}, nil
+}
+
+func builtinCommands(root *cobra.Command) map[string]struct{} {
+	builtins := make(map[string]struct{})
+
+	for _, cmd := range root.Commands() {
+		if _, isPlugin := cmd.Annotations[pathAnnotation]; isPlugin {
+			continue
+		}
+
+		builtins[cmd.Name()] = struct{}{}
+		for _, alias := range cmd.Aliases {
+			builtins[alias] = struct{}{}
+		}
+	}
+
+	// Commands added by cobra when the command is executed.
+	builtins["help"] = struct{}{}
+	builtins["completion"] = struct{}{}
+	builtins[cobra.ShellCompRequestCmd] = struct{}{}
+	builtins[cobra.ShellCompNoDescRequestCmd] = struct{}{}
+
+	return builtins
+}
+
+type pluginsTabCodec struct{}
+
+func (c *pluginsTabCodec) Format() format.Format {
+	return "text"
+}
+
+func (c *pluginsTabCodec) Encode(output io.Writer, input any) error {
+	items, ok := input.([]pluginInfo)
+	if !ok {
+		return fmt.Errorf("expected plugins, got %T", input)
+	}
+
+	out := tabwriter.NewWriter(output, 0, 4, 2, ' ', tabwriter.TabIndent|tabwriter.DiscardEmptyColumns)
+
+	fmt.Fprintf(out, "NAME\tPATH\tSTATUS\n")
+
+	for _, item := range items {
+		status := "active"
+		if item.Builtin {
+			status = "shadowed by built-in"
+		}
+
+		fmt.Fprintf(out, "%s\t%s\t%s\n", item.Name, item.Path, status)
+	}
+
+	return out.Flush()
+}
+
+func (c *pluginsTabCodec) Decode(io.Reader, any) error {
+	return errors.New("tab codec does not support decoding")
+}
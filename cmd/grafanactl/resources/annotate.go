@@ -0,0 +1,46 @@
+package resources
+
+import (
+	cmdconfig "github.com/grafana/grafanactl/cmd/grafanactl/config"
+	"github.com/spf13/cobra"
+)
+
+func annotateCmd(configOpts *cmdconfig.Options) *cobra.Command {
+	opts := &metadataOpts{}
+
+	cmd := &cobra.Command{
+		Use:               "annotate [RESOURCE_SELECTOR]... KEY=VALUE... [KEY-]...",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeSelectors(configOpts),
+		Short:             "Update the annotations of resources",
+		Long: `Update the annotations of resources, in Grafana or in local files.
+
+Annotations are set with KEY=VALUE, and removed with KEY-. Existing annotations are
+only given a new value if --overwrite is set.
+
+By default, the annotations of the resources matching the selectors are updated in Grafana.
+With --path, the resources read from the given paths are updated instead: files are
+modified in place, and selectors only filter the resources to update.
+
+The annotations holding the manager and source properties of resources are set by
+the tools managing them: they can only be modified with --force.
+`,
+		Example: `
+	# Annotate a dashboard
+	grafanactl resources annotate dashboards/foo owner=jane@example.com
+
+	# Move the dashboards defined in a directory to another folder
+	grafanactl resources annotate -p ./resources dashboards grafana.app/folder=new-folder --overwrite
+
+	# Remove an annotation from every folder
+	grafanactl resources annotate folders description-
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMetadataCmd(cmd, configOpts, opts, annotationsField, args)
+		},
+	}
+
+	opts.setup(cmd.Flags(), annotationsField)
+
+	return cmd
+}
@@ -0,0 +1,148 @@
+package resources
+
+import (
+	"errors"
+
+	cmdio "github.com/grafana/grafanactl/cmd/grafanactl/io"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/grafana/grafanactl/internal/resources/overlay"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type buildOpts struct {
+	IO cmdio.Options
+
+	Paths         []string
+	MaxConcurrent int
+	CUETags       []string
+	Template      templateOpts
+}
+
+func (opts *buildOpts) setup(flags *pflag.FlagSet) {
+	opts.IO.DefaultFormat("yaml")
+	opts.IO.BindFlags(flags)
+
+	flags.StringSliceVarP(&opts.Paths, "path", "p", []string{defaultResourcesPath}, "Paths on disk from which to read the resources. Paths can point to overlays")
+	flags.IntVar(&opts.MaxConcurrent, "max-concurrent", 10, "Maximum number of concurrent operations")
+	bindCUETagsFlag(flags, &opts.CUETags)
+	opts.Template.setup(flags)
+}
+
+func (opts *buildOpts) Validate() error {
+	if err := opts.IO.Validate(); err != nil {
+		return err
+	}
+
+	if len(opts.Paths) == 0 {
+		return errors.New("at least one path is required")
+	}
+
+	if opts.MaxConcurrent < 1 {
+		return errors.New("max-concurrent must be greater than zero")
+	}
+
+	return opts.Template.Validate()
+}
+
+func buildCmd() *cobra.Command {
+	opts := &buildOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "build",
+		Args:  cobra.NoArgs,
+		Short: "Render resources and overlays from disk",
+		Long: `Render resources and overlays from disk.
+
+Overlays are described by a '` + overlay.FileName + `' file. They reference base resources
+(files, directories or other overlays) and describe how to patch them.
+The resources rendered by this command are the ones that commands like 'push' or 'validate'
+would act upon.
+
+Example overlay:
+
+	resources:
+	  - ../base
+	patches:
+	  # JSON merge patch
+	  - target:
+	      kind: Dashboard
+	      name: overview
+	    patch: |
+	      spec:
+	        title: Overview (prod)
+	  # JSON patch (RFC 6902)
+	  - target:
+	      kind: Dashboard
+	    patch: |
+	      - op: replace
+	        path: /metadata/annotations/grafana.app~1folder
+	        value: prod-folder
+	replacements:
+	  - source:
+	      value: prometheus-prod
+	    targets:
+	      - select:
+	          kind: Dashboard
+	        fieldPaths:
+	          - spec.panels.*.datasource.uid
+
+This command does not require access to a Grafana instance.
+`,
+		Example: `
+	# Render an overlay
+	grafanactl resources build -p ./overlays/prod
+
+	# Render an overlay as JSON
+	grafanactl resources build -p ./overlays/prod -o json
+`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+
+			codec, err := opts.IO.Codec()
+			if err != nil {
+				return err
+			}
+
+			tmpl, err := opts.Template.template()
+			if err != nil {
+				return err
+			}
+
+			renderer := overlay.Renderer{
+				Reader: &local.FSReader{
+					Decoders:           decoders(opts.CUETags),
+					Template:           tmpl,
+					MaxConcurrentReads: opts.MaxConcurrent,
+					StopOnError:        true,
+				},
+			}
+
+			rendered := resources.NewResources()
+			if err := renderer.Read(ctx, rendered, nil, opts.Paths); err != nil {
+				return err
+			}
+
+			output := rendered.ToUnstructuredList()
+			resources.SortUnstructured(output.Items)
+
+			formatted := printItems{
+				Items: make([]map[string]any, len(output.Items)),
+			}
+			for i, item := range output.Items {
+				formatted.Items[i] = item.Object
+			}
+
+			return codec.Encode(cmd.OutOrStdout(), formatted)
+		},
+	}
+
+	opts.setup(cmd.Flags())
+
+	return cmd
+}
@@ -15,14 +15,28 @@ func Command() *cobra.Command {
 	}
 
 	configOpts.BindFlags(cmd.PersistentFlags())
+	_ = cmd.RegisterFlagCompletionFunc("context", configOpts.CompleteContexts)
 
+	cmd.AddCommand(adoptCmd(configOpts))
+	cmd.AddCommand(annotateCmd(configOpts))
+	cmd.AddCommand(buildCmd())
 	cmd.AddCommand(deleteCmd(configOpts))
 	cmd.AddCommand(editCmd(configOpts))
+	cmd.AddCommand(encryptCmd())
+	cmd.AddCommand(fmtCmd())
 	cmd.AddCommand(getCmd(configOpts))
+	cmd.AddCommand(historyCmd(configOpts))
+	cmd.AddCommand(labelCmd(configOpts))
+	cmd.AddCommand(lintCmd())
 	cmd.AddCommand(listCmd(configOpts))
+	cmd.AddCommand(patchCmd(configOpts))
 	cmd.AddCommand(pullCmd(configOpts))
 	cmd.AddCommand(pushCmd(configOpts))
+	cmd.AddCommand(releaseCmd(configOpts))
+	cmd.AddCommand(rollbackCmd(configOpts))
 	cmd.AddCommand(serveCmd(configOpts))
+	cmd.AddCommand(statusCmd(configOpts))
+	cmd.AddCommand(transformCmd(configOpts))
 	cmd.AddCommand(validateCmd(configOpts))
 
 	return cmd
@@ -0,0 +1,166 @@
+package resources
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	cmdconfig "github.com/grafana/grafanactl/cmd/grafanactl/config"
+	"github.com/grafana/grafanactl/internal/completion"
+	"github.com/grafana/grafanactl/internal/config"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/discovery"
+	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// Kinds rarely change: they are cached longer than resource names.
+	kindsCompletionTTL = 10 * time.Minute
+	namesCompletionTTL = time.Minute
+)
+
+// completeSelectors completes resource selectors: kinds, from the resources
+// discovered in Grafana, then resource names once a kind is followed by "/".
+// Names are read from the local files when --path is given, and listed from
+// Grafana otherwise.
+func completeSelectors(configOpts *cmdconfig.Options) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, _ []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		cache := completion.Cache{Dir: completion.DefaultDir()}
+
+		// Kinds are needed to match local resources against the selector, but
+		// local names can still be completed without them.
+		cfg, err := configOpts.LoadRESTConfig(ctx)
+		var kinds []completion.Kind
+		if err == nil {
+			kinds, err = completionKinds(ctx, cache, cfg)
+		}
+		if err != nil {
+			cobra.CompDebugln("could not complete kinds: "+err.Error(), false)
+		}
+
+		kind, _, named := strings.Cut(toComplete, "/")
+		if !named {
+			return completion.Complete(kinds, toComplete), cobra.ShellCompDirectiveNoFileComp
+		}
+
+		var names []string
+		if paths, ok := completionPaths(cmd.Flags()); ok {
+			names = localNames(ctx, paths, kinds, kind)
+		} else if cfg.Host != "" {
+			names, err = remoteNames(ctx, cache, cfg, kind)
+			if err != nil {
+				cobra.CompDebugln("could not complete names: "+err.Error(), false)
+			}
+		}
+
+		return completion.Names(toComplete, names), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func completionKinds(
+	ctx context.Context, cache completion.Cache, cfg config.NamespacedRESTConfig,
+) ([]completion.Kind, error) {
+	key := strings.Join([]string{"kinds", cfg.Host, cfg.Namespace}, "|")
+
+	return completion.Load(cache, key, kindsCompletionTTL, func() ([]completion.Kind, error) {
+		reg, err := discovery.NewDefaultRegistry(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		return completion.Kinds(reg.PreferredResources()), nil
+	})
+}
+
+func remoteNames(
+	ctx context.Context, cache completion.Cache, cfg config.NamespacedRESTConfig, kind string,
+) ([]string, error) {
+	key := strings.Join([]string{"names", cfg.Host, cfg.Namespace, kind}, "|")
+
+	return completion.Load(cache, key, namesCompletionTTL, func() ([]string, error) {
+		res, err := fetchResources(ctx, fetchRequest{Config: cfg}, []string{kind})
+		if err != nil {
+			return nil, err
+		}
+
+		return resourceNames(&res.Resources, func(*resources.Resource) bool { return true }), nil
+	})
+}
+
+// localNames returns the names of the resources of the given kind defined in
+// the files found in paths. Local files are read every time: they are never cached.
+func localNames(ctx context.Context, paths []string, kinds []completion.Kind, kind string) []string {
+	reader := local.FSReader{
+		Decoders:           decoders(nil),
+		MaxConcurrentReads: 10,
+	}
+
+	res := resources.NewResources()
+	if err := reader.Read(ctx, res, nil, paths); err != nil {
+		cobra.CompDebugln("could not read local resources: "+err.Error(), false)
+	}
+
+	matches := func(r *resources.Resource) bool {
+		return strings.EqualFold(r.Kind(), kind)
+	}
+
+	for _, candidate := range kinds {
+		if candidate.Matches(kind) {
+			matches = func(r *resources.Resource) bool {
+				return r.Kind() == candidate.Kind
+			}
+
+			break
+		}
+	}
+
+	return resourceNames(res, matches)
+}
+
+// completionPaths returns the paths given with --path, if any.
+func completionPaths(flags *pflag.FlagSet) ([]string, bool) {
+	flag := flags.Lookup("path")
+	if flag == nil || !flag.Changed {
+		return nil, false
+	}
+
+	if values, ok := flag.Value.(pflag.SliceValue); ok {
+		return values.GetSlice(), true
+	}
+
+	return []string{flag.Value.String()}, true
+}
+
+func resourceNames(res *resources.Resources, keep func(*resources.Resource) bool) []string {
+	var names []string
+
+	_ = res.ForEach(func(r *resources.Resource) error {
+		if keep(r) {
+			names = append(names, r.Name())
+		}
+
+		return nil
+	})
+
+	return names
+}
+
+// completeSingleSelector completes the selector of commands expecting a single one.
+func completeSingleSelector(configOpts *cmdconfig.Options) cobra.CompletionFunc {
+	complete := completeSelectors(configOpts)
+
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return complete(cmd, args, toComplete)
+	}
+}
@@ -0,0 +1,26 @@
+package resources
+
+import (
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/spf13/pflag"
+)
+
+// bindCUETagsFlag registers the --tag flag on the given flag set.
+func bindCUETagsFlag(flags *pflag.FlagSet, target *[]string) {
+	flags.StringArrayVarP(
+		target,
+		"tag",
+		"t",
+		nil,
+		"Tags injected in fields annotated with @tag() when evaluating CUE packages. Example: -t env=prod",
+	)
+}
+
+// decoders returns the codecs used to read resources from disk,
+// configured with the given CUE tags.
+func decoders(cueTags []string) map[format.Format]format.Codec {
+	codecs := format.Codecs()
+	codecs[format.CUE] = format.NewCUECodec(cueTags...)
+
+	return codecs
+}
@@ -9,10 +9,11 @@ import (
 	"github.com/grafana/grafanactl/cmd/grafanactl/fail"
 	cmdio "github.com/grafana/grafanactl/cmd/grafanactl/io"
 	"github.com/grafana/grafanactl/internal/config"
-	"github.com/grafana/grafanactl/internal/format"
 	"github.com/grafana/grafanactl/internal/resources"
 	"github.com/grafana/grafanactl/internal/resources/discovery"
+	"github.com/grafana/grafanactl/internal/resources/journal"
 	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/grafana/grafanactl/internal/resources/overlay"
 	"github.com/grafana/grafanactl/internal/resources/remote"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -24,6 +25,8 @@ type deleteOpts struct {
 	MaxConcurrent int
 	DryRun        bool
 	Path          []string
+	CUETags       []string
+	Template      templateOpts
 }
 
 func (opts *deleteOpts) setup(flags *pflag.FlagSet) {
@@ -32,6 +35,8 @@ func (opts *deleteOpts) setup(flags *pflag.FlagSet) {
 	flags.BoolVar(&opts.Force, "force", opts.Force, "Delete all resources of the specified resource types")
 	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun, "If set, the delete operation will be simulated")
 	flags.StringSliceVarP(&opts.Path, "path", "p", nil, "Path on disk containing the resources to delete")
+	bindCUETagsFlag(flags, &opts.CUETags)
+	opts.Template.setup(flags)
 }
 
 func (opts *deleteOpts) Validate(args []string) error {
@@ -43,6 +48,10 @@ func (opts *deleteOpts) Validate(args []string) error {
 		return errors.New("either --path or resource selectors need to be specified")
 	}
 
+	if err := opts.Template.Validate(); err != nil {
+		return err
+	}
+
 	return opts.OnError.Validate()
 }
 
@@ -50,10 +59,11 @@ func deleteCmd(configOpts *cmdconfig.Options) *cobra.Command {
 	opts := &deleteOpts{}
 
 	cmd := &cobra.Command{
-		Use:   "delete [RESOURCE_SELECTOR]...",
-		Args:  cobra.ArbitraryArgs,
-		Short: "Delete resources from Grafana",
-		Long:  "Delete resources from Grafana.",
+		Use:               "delete [RESOURCE_SELECTOR]...",
+		Args:              cobra.ArbitraryArgs,
+		ValidArgsFunction: completeSelectors(configOpts),
+		Short:             "Delete resources from Grafana",
+		Long:              "Delete resources from Grafana.",
 		Example: `
 	# Delete a single dashboard
 	grafanactl resources delete dashboards/some-dashboard
@@ -138,7 +148,16 @@ func deleteCmd(configOpts *cmdconfig.Options) *cobra.Command {
 				DryRun:         opts.DryRun,
 			}
 
+			var operation *journal.Operation
+			if !opts.DryRun {
+				operation = newOperation(ctx, configOpts, cfg, "delete")
+				req.Recorder = operation
+			}
+
 			summary, err := deleter.Delete(ctx, req)
+			if operation != nil {
+				saveOperation(cmd.OutOrStdout(), operation)
+			}
 			if err != nil {
 				if summary != nil {
 					cmdio.Warning(cmd.OutOrStdout(), "%d resources deleted, %d errors (aborted)", summary.SuccessCount(), summary.FailedCount())
@@ -176,8 +195,14 @@ func loadResourcesFromDirectories(ctx context.Context, cfg config.NamespacedREST
 		return err
 	}
 
+	tmpl, err := opts.Template.template()
+	if err != nil {
+		return err
+	}
+
 	reader := local.FSReader{
-		Decoders:           format.Codecs(),
+		Decoders:           decoders(opts.CUETags),
+		Template:           tmpl,
 		MaxConcurrentReads: opts.MaxConcurrent,
 		StopOnError:        opts.OnError.StopOnError(),
 	}
@@ -189,5 +214,7 @@ func loadResourcesFromDirectories(ctx context.Context, cfg config.NamespacedREST
 		return err
 	}
 
-	return reader.Read(ctx, res, filters, opts.Path)
+	renderer := overlay.Renderer{Reader: &reader}
+
+	return renderer.Read(ctx, res, filters, opts.Path)
 }
@@ -2,32 +2,55 @@ package resources
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
 
+	"github.com/goccy/go-yaml"
 	cmdconfig "github.com/grafana/grafanactl/cmd/grafanactl/config"
 	cmdio "github.com/grafana/grafanactl/cmd/grafanactl/io"
 	"github.com/grafana/grafanactl/internal/format"
 	"github.com/grafana/grafanactl/internal/resources"
-	"github.com/grafana/grafanactl/internal/resources/local"
 	"github.com/grafana/grafanactl/internal/resources/remote"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
+const editHeader = `# Please edit the resources below. Lines beginning with a '#' will be ignored,
+# and an empty file will cancel the edit. If an error occurs while saving,
+# this file will be reopened with the relevant failures.
+#
+`
+
 type editOpts struct {
-	IO cmdio.Options
+	IO    cmdio.Options
+	Paths []string
 }
 
 func (opts *editOpts) setup(flags *pflag.FlagSet) {
+	// Resources are written in files that are read again later
+	opts.IO.DisablePrinters()
+
 	// Bind all the flags
 	opts.IO.BindFlags(flags)
+
+	flags.StringSliceVarP(&opts.Paths, "path", "p", nil, "Paths on disk of the resources to edit. If not set, the resources are edited in Grafana")
 }
 
-func (opts *editOpts) Validate() error {
+func (opts *editOpts) Validate(args []string) error {
 	if err := opts.IO.Validate(); err != nil {
 		return err
 	}
 
+	if len(opts.Paths) == 0 && len(args) == 0 {
+		return errors.New("at least one resource selector is required")
+	}
+
 	return nil
 }
 
@@ -35,120 +58,476 @@ func editCmd(configOpts *cmdconfig.Options) *cobra.Command {
 	opts := &editOpts{}
 
 	cmd := &cobra.Command{
-		Use:   "edit RESOURCE_SELECTOR",
-		Args:  cobra.ExactArgs(1),
-		Short: "Edit resources from Grafana",
+		Use:               "edit [RESOURCE_SELECTOR]...",
+		Args:              cobra.ArbitraryArgs,
+		ValidArgsFunction: completeSelectors(configOpts),
+		Short:             "Edit resources from Grafana",
 		Long: `Edit resources from Grafana using the default editor.
 
 This command allows the edition of any resource that can be accessed by this CLI tool.
+Several resources can be edited at once: they are presented as a multi-document YAML
+file, or as a List in JSON. Only the resources that were changed are pushed.
 
 It will open the default editor as configured by the EDITOR environment variable, or fall back to 'vi' for Linux or 'notepad' for Windows.
 The editor will be started in the shell set by the SHELL environment variable. If undefined, '/bin/bash' is used for Linux or 'cmd' for Windows.
 
 The edition will be cancelled if no changes are written to the file or if the file after edition is empty.
+
+With --path, the resources read from the given paths are edited instead of the ones in
+Grafana, and selectors only filter the resources to edit. The changes are written back
+to the files the resources were read from: only the modified fields are updated, keeping
+the comments and formatting of YAML files.
+
+If the edited resources can not be parsed or pushed, the editor is reopened with the
+errors shown as comments at the top of the file. Cancelling the edit at that point
+saves the changes to a temporary file, so that they can be recovered.
 `,
 		Example: `
 	# Editing a dashboard
-	grafanactl resources dashboard/foo
+	grafanactl resources edit dashboard/foo
 
 	# Editing a dashboard in JSON
-	grafanactl resources -o json dashboard/foo
+	grafanactl resources edit -o json dashboard/foo
+
+	# Editing several resources at once
+	grafanactl resources edit dashboards/foo,bar folders
 
 	# Using an alternative editor
-	EDITOR=nvim grafanactl resources dashboard/foo
+	EDITOR=nvim grafanactl resources edit dashboard/foo
+
+	# Editing the dashboards defined in a directory
+	grafanactl resources edit -p ./resources dashboards
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
-			edit := editorFromEnv()
 
-			cfg, err := configOpts.LoadRESTConfig(ctx)
-			if err != nil {
+			if err := opts.Validate(args); err != nil {
 				return err
 			}
 
-			pusher, err := remote.NewDefaultPusher(ctx, cfg)
+			codec, err := opts.IO.Codec()
 			if err != nil {
 				return err
 			}
 
-			reader := local.FSReader{
-				Decoders:           format.Codecs(),
-				MaxConcurrentReads: 1,
-				StopOnError:        true,
+			session := &editSession{
+				editor: editorFromEnv(),
+				codec:  codec,
+				format: opts.IO.OutputFormat,
+				output: cmd.OutOrStdout(),
 			}
 
-			// Fetch the resource
-			res, err := fetchResources(ctx, fetchRequest{
-				Config:             cfg,
-				StopOnError:        true,
-				ExpectSingleTarget: true,
-			}, args)
+			if len(opts.Paths) != 0 {
+				list, err := session.readSources(ctx, configOpts, opts.Paths, args)
+				if err != nil {
+					return err
+				}
+
+				return session.run(ctx, list)
+			}
+
+			cfg, err := configOpts.LoadRESTConfig(ctx)
 			if err != nil {
 				return err
 			}
 
-			codec, err := opts.IO.Codec()
+			pusher, err := remote.NewDefaultPusher(ctx, cfg)
+			if err != nil {
+				return err
+			}
+
+			// Fetch the resources
+			res, err := fetchResources(ctx, fetchRequest{
+				Config:      cfg,
+				StopOnError: true,
+			}, args)
 			if err != nil {
 				return err
 			}
 
-			// Will contain the initial state of the resource to edit
-			buffer := &bytes.Buffer{}
+			session.pusher = pusher
 
-			if opts.IO.OutputFormat == "yaml" {
-				buffer.WriteString(`# Please edit the resource below. Lines beginning with a '#' will be ignored,
-# and an empty file will cancel the edit.
+			return session.run(ctx, res.Resources.AsList())
+		},
+	}
+
+	opts.setup(cmd.Flags())
+
+	return cmd
+}
+
+// editSession edits resources until they are pushed successfully,
+// or until the edit is cancelled.
+type editSession struct {
+	editor editor
+	pusher *remote.Pusher
+	codec  format.Codec
+	format string
+	output io.Writer
+
+	// edited holds the last state of every resource known to Grafana:
+	// only resources that differ from it are pushed.
+	edited map[string]*unstructured.Unstructured
+
+	// sources holds the resources read from local files, when they are
+	// edited instead of the resources in Grafana.
+	sources map[string]*resources.Resource
+}
 
-`)
+// readSources reads the local resources to edit.
+// Resources that can't be written back to their files are skipped.
+func (s *editSession) readSources(
+	ctx context.Context, configOpts *cmdconfig.Options, paths []string, args []string,
+) ([]*resources.Resource, error) {
+	list, err := readSources(ctx, configOpts, sourcesRequest{
+		Paths:         paths,
+		MaxConcurrent: 10,
+		StopOnError:   true,
+	}, args)
+	if err != nil {
+		return nil, err
+	}
+
+	s.sources = make(map[string]*resources.Resource, len(list))
+	editable := make([]*resources.Resource, 0, len(list))
+
+	for _, res := range list {
+		if err := res.Source.Writable(); err != nil {
+			cmdio.Warning(s.output, "%s: %s", res.SourcePath(), err)
+			continue
+		}
+
+		s.sources[resourceKey(res)] = res
+		editable = append(editable, res)
+	}
+
+	return editable, nil
+}
+
+func (s *editSession) run(ctx context.Context, list []*resources.Resource) error {
+	if len(list) == 0 {
+		return errors.New("no resources found")
+	}
+
+	s.edited = make(map[string]*unstructured.Unstructured, len(list))
+	for _, r := range list {
+		obj := r.ToUnstructured()
+		s.edited[resourceKey(r)] = &obj
+	}
+
+	body, err := s.encode(list)
+	if err != nil {
+		return err
+	}
+
+	content := append([]byte(editHeader), body...)
+	retrying := false
+
+	for {
+		cleanup, edited, err := s.editor.OpenInTempFile(ctx, bytes.NewReader(content), s.format)
+		cleanup()
+		if err != nil {
+			if retrying {
+				return s.recover(content, err)
 			}
 
-			list := res.Resources.AsList()
-			if len(list) != 1 {
-				return fmt.Errorf("expected exactly one resource, got %d", len(list))
+			return err
+		}
+
+		if len(bytes.TrimSpace(stripComments(edited))) == 0 {
+			if retrying {
+				return s.recover(content, errors.New("edit cancelled: empty file"))
 			}
 
-			obj := list[0].ToUnstructured()
-			if err := codec.Encode(buffer, &obj); err != nil {
-				return err
+			cmdio.Info(s.output, "Edit cancelled: empty file.")
+			return nil
+		}
+
+		if bytes.Equal(content, edited) {
+			if retrying {
+				return s.recover(content, errors.New("edit cancelled: no changes were made after the failure"))
 			}
 
-			original := buffer.Bytes()
-			cleanup, edited, err := edit.OpenInTempFile(ctx, buffer, opts.IO.OutputFormat)
+			cmdio.Info(s.output, "Edit cancelled: no changes were made.")
+			return nil
+		}
+
+		var failures []string
+
+		objects, err := s.decode(edited)
+		if err != nil {
+			failures = []string{err.Error()}
+		} else {
+			failures, err = s.push(ctx, objects)
 			if err != nil {
-				return err
+				return s.recover(edited, err)
 			}
-			defer cleanup()
+		}
 
-			if len(edited) == 0 {
-				cmdio.Info(cmd.OutOrStdout(), "Edit cancelled: empty file.")
-				return nil
-			}
+		if len(failures) == 0 {
+			return nil
+		}
 
-			if bytes.Equal(original, edited) {
-				cmdio.Info(cmd.OutOrStdout(), "Edit cancelled: no changes were made.")
-				return nil
-			}
+		content = append(errorHeader(failures), stripHeader(edited)...)
+		retrying = true
+	}
+}
 
-			tmpRes := resources.NewResources()
-			if err := reader.ReadBytes(ctx, tmpRes, edited, opts.IO.OutputFormat); err != nil {
-				return err
-			}
+// encode encodes resources as a multi-document YAML file, or as a JSON List.
+func (s *editSession) encode(list []*resources.Resource) ([]byte, error) {
+	buffer := &bytes.Buffer{}
 
-			if _, err := pusher.Push(ctx, remote.PushRequest{
-				Resources:      tmpRes,
-				MaxConcurrency: 1,
-				StopOnError:    true,
-			}); err != nil {
-				return err
-			}
+	if s.codec.Format() != format.YAML {
+		if len(list) == 1 {
+			obj := list[0].ToUnstructured()
+			err := s.codec.Encode(buffer, &obj)
 
-			cmdio.Success(cmd.OutOrStdout(), "Edited!")
+			return buffer.Bytes(), err
+		}
 
+		items := make([]any, 0, len(list))
+		for _, r := range list {
+			items = append(items, r.ToUnstructured().Object)
+		}
+
+		err := s.codec.Encode(buffer, map[string]any{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items":      items,
+		})
+
+		return buffer.Bytes(), err
+	}
+
+	for i, r := range list {
+		if i != 0 {
+			buffer.WriteString("---\n")
+		}
+
+		obj := r.ToUnstructured()
+		if err := s.codec.Encode(buffer, &obj); err != nil {
+			return nil, err
+		}
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// decode decodes the edited resources. Lists are expanded.
+func (s *editSession) decode(raw []byte) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+
+	add := func(obj *unstructured.Unstructured) error {
+		if !obj.IsList() {
+			objects = append(objects, obj)
 			return nil
+		}
+
+		list, err := obj.ToList()
+		if err != nil {
+			return err
+		}
+
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+
+		return nil
+	}
+
+	if s.codec.Format() != format.YAML {
+		obj := &unstructured.Unstructured{}
+		if err := s.codec.Decode(bytes.NewReader(stripComments(raw)), obj); err != nil {
+			return nil, fmt.Errorf("could not parse the edited resources: %w", err)
+		}
+
+		if err := add(obj); err != nil {
+			return nil, err
+		}
+
+		return objects, nil
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(raw), yaml.Strict(), yaml.UseJSONUnmarshaler())
+	for {
+		obj := &unstructured.Unstructured{}
+		err := decoder.Decode(obj)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not parse the edited resources: %w", err)
+		}
+
+		// Empty documents.
+		if obj.Object == nil {
+			continue
+		}
+
+		if err := add(obj); err != nil {
+			return nil, err
+		}
+	}
+
+	return objects, nil
+}
+
+// push pushes the resources that changed, and returns the failures.
+// The error is only set when the push could not be attempted.
+func (s *editSession) push(ctx context.Context, objects []*unstructured.Unstructured) ([]string, error) {
+	changed := resources.NewResources()
+	// Resources are modified by the pusher: keep them as edited.
+	snapshots := make(map[string]*unstructured.Unstructured)
+	var failures []string
+
+	for _, obj := range objects {
+		res, err := resources.FromUnstructured(obj)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s/%s: %s", obj.GetKind(), obj.GetName(), err))
+			continue
+		}
+
+		if previous, ok := s.edited[resourceKey(res)]; ok && reflect.DeepEqual(previous.Object, obj.Object) {
+			continue
+		}
+
+		snapshots[resourceKey(res)] = obj.DeepCopy()
+		changed.Add(res)
+	}
+
+	if len(failures) != 0 {
+		return failures, nil
+	}
+
+	if changed.Len() == 0 {
+		cmdio.Info(s.output, "Edit cancelled: no changes were made.")
+		return nil, nil
+	}
+
+	if s.sources != nil {
+		return s.writeSources(changed, snapshots), nil
+	}
+
+	summary, err := s.pusher.Push(ctx, remote.PushRequest{
+		Resources:        changed,
+		MaxConcurrency:   1,
+		NoPushFailureLog: true,
+		OnPush: func(res *resources.Resource, _ remote.PushAction) {
+			s.edited[resourceKey(res)] = snapshots[resourceKey(res)]
+
+			cmdio.Success(s.output, "%s/%s edited", res.Kind(), res.Name())
 		},
+	})
+	if err != nil && summary.FailedCount() == 0 {
+		return nil, err
 	}
 
-	opts.setup(cmd.Flags())
+	for _, failure := range summary.Failures() {
+		if failure.Resource == nil {
+			failures = append(failures, failure.Error.Error())
+			continue
+		}
 
-	return cmd
+		failures = append(failures, fmt.Sprintf("%s/%s: %s", failure.Resource.Kind(), failure.Resource.Name(), failure.Error))
+	}
+
+	return failures, nil
+}
+
+// writeSources writes the resources that changed back to the files they were
+// read from, and returns the failures.
+func (s *editSession) writeSources(changed *resources.Resources, snapshots map[string]*unstructured.Unstructured) []string {
+	var failures []string
+
+	for _, res := range changed.AsList() {
+		key := resourceKey(res)
+
+		source, ok := s.sources[key]
+		if !ok {
+			failures = append(failures, fmt.Sprintf("%s/%s: resources can not be added or renamed when editing local files", res.Kind(), res.Name()))
+			continue
+		}
+
+		edited := *source
+		if err := edited.SetUnstructured(&res.Object); err != nil {
+			failures = append(failures, fmt.Sprintf("%s/%s: %s", res.Kind(), res.Name(), err))
+			continue
+		}
+
+		if _, err := rewriteSource(s.output, &edited, false); err != nil {
+			failures = append(failures, fmt.Sprintf("%s/%s: %s", res.Kind(), res.Name(), err))
+			continue
+		}
+
+		s.edited[key] = snapshots[key]
+
+		cmdio.Success(s.output, "%s/%s edited in %s", res.Kind(), res.Name(), source.SourcePath())
+	}
+
+	return failures
+}
+
+// recover saves the edited resources to a file that won't be deleted,
+// and returns the error that ended the edit.
+func (s *editSession) recover(content []byte, cause error) error {
+	file, err := os.CreateTemp("", "grafanactl-edit-*."+s.format)
+	if err != nil {
+		return fmt.Errorf("%w (the changes could not be saved: %w)", cause, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(content); err != nil {
+		return fmt.Errorf("%w (the changes could not be saved: %w)", cause, err)
+	}
+
+	cmdio.Warning(s.output, "A copy of your changes has been stored to %s", file.Name())
+
+	return cause
+}
+
+// errorHeader builds the header shown when the editor is reopened after a failure.
+func errorHeader(failures []string) []byte {
+	buffer := &bytes.Buffer{}
+	buffer.WriteString(editHeader)
+	buffer.WriteString("# The edited resources could not be saved:\n")
+
+	for _, failure := range failures {
+		for _, line := range strings.Split(strings.TrimRight(failure, "\n"), "\n") {
+			buffer.WriteString("# " + line + "\n")
+		}
+	}
+
+	buffer.WriteString("#\n")
+
+	return buffer.Bytes()
+}
+
+// stripHeader removes the comments at the top of an edited file.
+func stripHeader(raw []byte) []byte {
+	for len(raw) != 0 && raw[0] == '#' {
+		end := bytes.IndexByte(raw, '\n')
+		if end == -1 {
+			return nil
+		}
+
+		raw = raw[end+1:]
+	}
+
+	return raw
+}
+
+// stripComments removes the lines beginning with a '#', which JSON doesn't support.
+func stripComments(raw []byte) []byte {
+	lines := bytes.Split(raw, []byte("\n"))
+	kept := make([][]byte, 0, len(lines))
+
+	for _, line := range lines {
+		if bytes.HasPrefix(bytes.TrimSpace(line), []byte("#")) {
+			continue
+		}
+
+		kept = append(kept, line)
+	}
+
+	return bytes.Join(kept, []byte("\n"))
 }
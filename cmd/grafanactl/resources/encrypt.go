@@ -0,0 +1,163 @@
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"filippo.io/age"
+	"github.com/grafana/grafanactl/cmd/grafanactl/fail"
+	cmdio "github.com/grafana/grafanactl/cmd/grafanactl/io"
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/grafana/grafanactl/internal/secrets"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type encryptOpts struct {
+	Paths      []string
+	Fields     []string
+	Recipients []string
+}
+
+func (opts *encryptOpts) setup(flags *pflag.FlagSet) {
+	flags.StringSliceVarP(&opts.Paths, "path", "p", []string{defaultResourcesPath}, "Paths on disk of the files to encrypt")
+	flags.StringArrayVar(&opts.Fields, "field", nil, "Path of a field to mark as secret and encrypt, e.g. spec.settings.password. Can be repeated")
+	flags.StringArrayVar(&opts.Recipients, "recipient", nil, "age recipient to encrypt values for. Can be repeated. Defaults to the recipients of the configured age identities")
+}
+
+func (opts *encryptOpts) Validate() error {
+	if len(opts.Paths) == 0 {
+		return errors.New("at least one path is required")
+	}
+
+	return nil
+}
+
+func encryptCmd() *cobra.Command {
+	opts := &encryptOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "encrypt",
+		Args:  cobra.NoArgs,
+		Short: "Encrypt the secret fields of resource files",
+		Long: fmt.Sprintf(`Encrypt the secret fields of resource files with age.
+
+Secret fields are 'spec.secureJsonData', and the fields listed in the
+'%[1]s' annotation of resources. Fields given with --field
+are added to the annotation. Values already encrypted are left untouched.
+
+Encrypted values can be committed: they are decrypted in memory by 'push',
+'serve', 'status' and 'validate', and never written to disk. Files encrypted
+with SOPS are also decrypted, with the sops command.
+
+age identities are read from, in order: $%[2]s, $%[3]s,
+$SOPS_AGE_KEY, $SOPS_AGE_KEY_FILE and $XDG_CONFIG_HOME/sops/age/keys.txt.
+`, secrets.FieldsAnnotation, secrets.KeyEnvVar, secrets.KeyFileEnvVar),
+		Example: `
+	# Generate an age identity
+	age-keygen -o ~/.config/sops/age/keys.txt
+
+	# Encrypt the secure JSON data of datasources, for the configured identity
+	grafanactl resources encrypt -p ./resources/datasources
+
+	# Encrypt the password of contact points for a given recipient
+	grafanactl resources encrypt --field spec.settings.password --recipient age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p
+`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+
+			recipients, err := opts.recipients()
+			if err != nil {
+				return err
+			}
+
+			files, err := formattableFiles(opts.Paths)
+			if err != nil {
+				return err
+			}
+
+			encrypter := local.Encrypter{
+				Codecs:     format.Codecs(),
+				Recipients: recipients,
+				Fields:     opts.Fields,
+			}
+			output := cmd.OutOrStdout()
+			encrypted := 0
+			failed := 0
+
+			for _, file := range files {
+				result, err := encrypter.Encrypt(file)
+				if errors.Is(err, local.ErrSOPSEncrypted) {
+					cmdio.Info(output, "%s is encrypted with SOPS, skipping", file)
+					continue
+				}
+
+				if err == nil && result.Encrypted != 0 {
+					err = os.WriteFile(file, result.Content, 0644)
+				}
+
+				if err != nil {
+					cmdio.Error(output, "%s: %s", file, err)
+					failed++
+					continue
+				}
+
+				if result.Encrypted == 0 {
+					continue
+				}
+
+				encrypted++
+				cmdio.Success(output, "%s: %d value(s) encrypted", file, result.Encrypted)
+			}
+
+			if failed != 0 {
+				return fmt.Errorf("%d file(s) could not be encrypted", failed)
+			}
+
+			if encrypted == 0 {
+				cmdio.Info(output, "%d file(s) checked, no value to encrypt", len(files))
+			}
+
+			return nil
+		},
+	}
+
+	opts.setup(cmd.Flags())
+
+	return cmd
+}
+
+func (opts *encryptOpts) recipients() ([]age.Recipient, error) {
+	if len(opts.Recipients) != 0 {
+		recipients, err := secrets.ParseRecipients(opts.Recipients)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient: %w", err)
+		}
+
+		return recipients, nil
+	}
+
+	identities, err := secrets.LoadIdentities()
+	if err != nil {
+		return nil, fail.DetailedError{
+			Summary: "No age recipient",
+			Details: "Values can not be encrypted: no recipient was given, and no age identity is configured.",
+			Parent:  err,
+			Suggestions: []string{
+				"Give a recipient with --recipient",
+				fmt.Sprintf("Set %s to an age identity, or %s to the path of an age identities file", secrets.KeyEnvVar, secrets.KeyFileEnvVar),
+			},
+		}
+	}
+
+	recipients := secrets.RecipientsOf(identities)
+	if len(recipients) == 0 {
+		return nil, errors.New("the configured age identities have no recipient: give one with --recipient")
+	}
+
+	return recipients, nil
+}
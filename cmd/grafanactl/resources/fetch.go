@@ -21,31 +21,12 @@ type fetchRequest struct {
 type fetchResponse struct {
 	Resources      resources.Resources
 	IsSingleTarget bool
+	Filters        resources.Filters
 	PullSummary    *remote.OperationSummary
 }
 
 func fetchResources(ctx context.Context, opts fetchRequest, args []string) (*fetchResponse, error) {
-	sels, err := resources.ParseSelectors(args)
-	if err != nil {
-		return nil, err
-	}
-
-	if opts.ExpectSingleTarget && !sels.IsSingleTarget() {
-		return nil, fail.DetailedError{
-			Summary: "Invalid resource selector",
-			Details: "Expected a resource selector targeting a single resource. Example: dashboard/some-dashboard",
-		}
-	}
-
-	reg, err := discovery.NewDefaultRegistry(ctx, opts.Config)
-	if err != nil {
-		return nil, err
-	}
-
-	filters, err := reg.MakeFilters(discovery.MakeFiltersOptions{
-		Selectors:            sels,
-		PreferredVersionOnly: true,
-	})
+	sels, filters, err := fetchFilters(ctx, opts, args)
 	if err != nil {
 		return nil, err
 	}
@@ -57,6 +38,7 @@ func fetchResources(ctx context.Context, opts fetchRequest, args []string) (*fet
 
 	res := fetchResponse{
 		IsSingleTarget: sels.IsSingleTarget(),
+		Filters:        filters,
 	}
 
 	req := remote.PullRequest{
@@ -76,3 +58,57 @@ func fetchResources(ctx context.Context, opts fetchRequest, args []string) (*fet
 
 	return &res, nil
 }
+
+// watchResources watches the resources designated by the given selectors,
+// until the context is cancelled or onEvent returns an error.
+func watchResources(
+	ctx context.Context, opts fetchRequest, args []string, sendInitialEvents bool, onEvent func(remote.WatchEvent) error,
+) error {
+	_, filters, err := fetchFilters(ctx, opts, args)
+	if err != nil {
+		return err
+	}
+
+	pull, err := remote.NewDefaultPuller(ctx, opts.Config)
+	if err != nil {
+		return err
+	}
+
+	return pull.Watch(ctx, remote.WatchRequest{
+		Filters:           filters,
+		Processors:        opts.Processors,
+		ExcludeManaged:    opts.ExcludeManaged,
+		StopOnError:       opts.StopOnError,
+		SendInitialEvents: sendInitialEvents,
+		OnEvent:           onEvent,
+	})
+}
+
+func fetchFilters(ctx context.Context, opts fetchRequest, args []string) (resources.Selectors, resources.Filters, error) {
+	sels, err := resources.ParseSelectors(args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if opts.ExpectSingleTarget && !sels.IsSingleTarget() {
+		return nil, nil, fail.DetailedError{
+			Summary: "Invalid resource selector",
+			Details: "Expected a resource selector targeting a single resource. Example: dashboard/some-dashboard",
+		}
+	}
+
+	reg, err := discovery.NewDefaultRegistry(ctx, opts.Config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filters, err := reg.MakeFilters(discovery.MakeFiltersOptions{
+		Selectors:            sels,
+		PreferredVersionOnly: true,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return sels, filters, nil
+}
@@ -0,0 +1,205 @@
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	cmdio "github.com/grafana/grafanactl/cmd/grafanactl/io"
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/grafana/grafanactl/internal/resources/overlay"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type fmtOpts struct {
+	Paths []string
+	Check bool
+	To    string
+}
+
+func (opts *fmtOpts) setup(flags *pflag.FlagSet) {
+	flags.StringSliceVarP(&opts.Paths, "path", "p", []string{defaultResourcesPath}, "Paths on disk of the files to format")
+	flags.BoolVar(&opts.Check, "check", opts.Check, "Only check that files are formatted, without modifying them. Exits with an error if they are not")
+	flags.StringVar(&opts.To, "to", opts.To, "Convert files to the given format. One of: json, yaml. By default, files keep their format")
+}
+
+func (opts *fmtOpts) Validate() error {
+	if len(opts.Paths) == 0 {
+		return errors.New("at least one path is required")
+	}
+
+	if opts.To != "" && opts.To != string(format.JSON) && opts.To != string(format.YAML) {
+		return fmt.Errorf("unsupported format '%s'. Valid formats are: json, yaml", opts.To)
+	}
+
+	return nil
+}
+
+func fmtCmd() *cobra.Command {
+	opts := &fmtOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "fmt",
+		Args:  cobra.NoArgs,
+		Short: "Format resource files",
+		Long: `Format resource files.
+
+Files are rewritten in a canonical form, the one used by 'pull': keys are sorted,
+the indentation is normalized and fields set by Grafana (resource version,
+status, ...) are removed.
+
+JSON and YAML files are formatted. CUE files, overlays and hidden files are ignored.
+`,
+		Example: `
+	# Format all resources in the default directory
+	grafanactl resources fmt
+
+	# Check that resources are formatted, in CI for example
+	grafanactl resources fmt --check
+
+	# Convert JSON files to YAML
+	grafanactl resources fmt -p ./dashboards --to yaml
+`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+
+			files, err := formattableFiles(opts.Paths)
+			if err != nil {
+				return err
+			}
+
+			formatter := local.Formatter{Codecs: format.Codecs()}
+			output := cmd.OutOrStdout()
+			changed := 0
+			failed := 0
+
+			for _, file := range files {
+				result, err := formatter.Format(file, format.Format(opts.To))
+				if err == nil && result.Changed && !opts.Check {
+					err = writeFormatted(result)
+				}
+
+				if err != nil {
+					cmdio.Error(output, "%s: %s", file, err)
+					failed++
+					continue
+				}
+
+				if !result.Changed {
+					continue
+				}
+
+				changed++
+
+				switch {
+				case opts.Check:
+					cmdio.Warning(output, "%s is not formatted", file)
+				case result.Target != file:
+					cmdio.Success(output, "%s converted to %s", file, result.Target)
+				default:
+					cmdio.Success(output, "%s formatted", file)
+				}
+			}
+
+			if failed != 0 {
+				return fmt.Errorf("%d file(s) could not be formatted", failed)
+			}
+
+			if opts.Check {
+				if changed != 0 {
+					return fmt.Errorf("%d file(s) are not formatted", changed)
+				}
+
+				cmdio.Success(output, "%d file(s) checked, all formatted", len(files))
+				return nil
+			}
+
+			if changed == 0 {
+				cmdio.Info(output, "%d file(s) already formatted", len(files))
+			}
+
+			return nil
+		},
+	}
+
+	opts.setup(cmd.Flags())
+
+	return cmd
+}
+
+// formattableFiles lists the JSON and YAML files that describe resources.
+func formattableFiles(paths []string) ([]string, error) {
+	var files []string
+
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, err
+		}
+
+		// Files given explicitly are always formatted.
+		if !info.IsDir() {
+			files = append(files, root)
+			continue
+		}
+
+		err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			hidden := path != root && strings.HasPrefix(entry.Name(), ".")
+
+			if entry.IsDir() {
+				if hidden || entry.Name() == "cue.mod" {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
+			if hidden || entry.Name() == overlay.FileName {
+				return nil
+			}
+
+			switch strings.TrimPrefix(filepath.Ext(path), ".") {
+			case "json", "yaml", "yml":
+				files = append(files, path)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	slices.Sort(files)
+
+	return slices.Compact(files), nil
+}
+
+func writeFormatted(result local.FormattedFile) error {
+	if result.Target == result.Path {
+		return os.WriteFile(result.Path, result.Content, 0644)
+	}
+
+	// Converted files must not overwrite existing ones.
+	if _, err := os.Stat(result.Target); err == nil {
+		return fmt.Errorf("can not convert to %s: the file already exists", result.Target)
+	}
+
+	if err := os.WriteFile(result.Target, result.Content, 0644); err != nil {
+		return err
+	}
+
+	return os.Remove(result.Path)
+}
@@ -1,15 +1,19 @@
 package resources
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 	"io"
+	"slices"
+	"strings"
 	"time"
 
 	cmdconfig "github.com/grafana/grafanactl/cmd/grafanactl/config"
 	cmdio "github.com/grafana/grafanactl/cmd/grafanactl/io"
 	"github.com/grafana/grafanactl/internal/format"
 	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/remote"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -20,8 +24,12 @@ import (
 )
 
 type getOpts struct {
-	IO      cmdio.Options
-	OnError OnErrorMode
+	IO        cmdio.Options
+	OnError   OnErrorMode
+	Watch     bool
+	WatchOnly bool
+	SortBy    string
+	Version   int64
 }
 
 func (opts *getOpts) setup(flags *pflag.FlagSet) {
@@ -33,6 +41,11 @@ func (opts *getOpts) setup(flags *pflag.FlagSet) {
 
 	// Bind all the flags
 	opts.IO.BindFlags(flags)
+
+	flags.BoolVarP(&opts.Watch, "watch", "w", opts.Watch, "After listing the requested resources, watch for changes")
+	flags.BoolVar(&opts.WatchOnly, "watch-only", opts.WatchOnly, "Watch for changes to the requested resources, without listing them first")
+	flags.StringVar(&opts.SortBy, "sort-by", opts.SortBy, "Sort resources using a JSONPath expression, e.g. '.metadata.name' or '{.spec.title}'")
+	flags.Int64Var(&opts.Version, "version", opts.Version, "Get a single resource as it was at the given version, as listed by 'resources history'")
 }
 
 func (opts *getOpts) Validate() error {
@@ -40,6 +53,24 @@ func (opts *getOpts) Validate() error {
 		return err
 	}
 
+	if opts.SortBy != "" {
+		if opts.Watch || opts.WatchOnly {
+			return errors.New("--sort-by can not be used with --watch or --watch-only")
+		}
+
+		if _, err := cmdio.NewJSONPath(opts.SortBy); err != nil {
+			return fmt.Errorf("invalid --sort-by expression: %w", err)
+		}
+	}
+
+	if opts.Version < 0 {
+		return errors.New("--version must be greater than zero")
+	}
+
+	if opts.Version != 0 && (opts.Watch || opts.WatchOnly) {
+		return errors.New("--version can not be used with --watch or --watch-only")
+	}
+
 	return opts.OnError.Validate()
 }
 
@@ -47,10 +78,14 @@ func getCmd(configOpts *cmdconfig.Options) *cobra.Command {
 	opts := &getOpts{}
 
 	cmd := &cobra.Command{
-		Use:   "get [RESOURCE_SELECTOR]...",
-		Args:  cobra.ArbitraryArgs,
-		Short: "Get resources from Grafana",
-		Long:  "Get resources from Grafana using a specific format. See examples below for more details.",
+		Use:               "get [RESOURCE_SELECTOR]...",
+		Args:              cobra.ArbitraryArgs,
+		ValidArgsFunction: completeSelectors(configOpts),
+		Short:             "Get resources from Grafana",
+		Long: `Get resources from Grafana using a specific format. See examples below for more details.
+
+In the text and wide formats, resources are printed in one table per kind,
+with the columns declared by Grafana for that kind (e.g. the title of dashboards).`,
 		Example: `
 	# Everything:
 
@@ -88,19 +123,55 @@ func getCmd(configOpts *cmdconfig.Options) *cobra.Command {
 
 	# Multiple resource kinds, long kind format with version:
 
-	grafanactl resources get dashboards.v1alpha1.dashboard.grafana.app/foo folders.v1alpha1.folder.grafana.app/qux`,
+	grafanactl resources get dashboards.v1alpha1.dashboard.grafana.app/foo folders.v1alpha1.folder.grafana.app/qux
+
+	# Print the title of every dashboard:
+
+	grafanactl resources get dashboards -o custom-columns=NAME:.metadata.name,TITLE:.spec.title --sort-by=.spec.title
+	grafanactl resources get dashboards -o jsonpath='{range .items[*]}{.spec.title}{"\n"}{end}'
+
+	# Watch for changes made to dashboards:
+
+	grafanactl resources get dashboards --watch
+
+	# Print a previous version of a dashboard:
+
+	grafanactl resources get dashboards/foo --version 3`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+
 			cfg, err := configOpts.LoadRESTConfig(ctx)
 			if err != nil {
 				return err
 			}
 
-			res, err := fetchResources(ctx, fetchRequest{
-				Config:      cfg,
-				StopOnError: opts.OnError.StopOnError(),
-			}, args)
+			if opts.Watch || opts.WatchOnly {
+				codec, err := opts.IO.Codec()
+				if err != nil {
+					return err
+				}
+
+				return watchResources(ctx, fetchRequest{
+					Config:      cfg,
+					StopOnError: opts.OnError.StopOnError(),
+				}, args, !opts.WatchOnly, func(event remote.WatchEvent) error {
+					return opts.printEvent(cmd.OutOrStdout(), codec, event)
+				})
+			}
+
+			var res *fetchResponse
+			if opts.Version != 0 {
+				res, err = fetchVersion(ctx, configOpts, args, opts.Version)
+			} else {
+				res, err = fetchResources(ctx, fetchRequest{
+					Config:      cfg,
+					StopOnError: opts.OnError.StopOnError(),
+				}, args)
+			}
 			if err != nil {
 				return err
 			}
@@ -113,6 +184,12 @@ func getCmd(configOpts *cmdconfig.Options) *cobra.Command {
 			output := res.Resources.ToUnstructuredList()
 			resources.SortUnstructured(output.Items)
 
+			if opts.SortBy != "" {
+				if err := sortByJSONPath(output.Items, opts.SortBy); err != nil {
+					return err
+				}
+			}
+
 			var encodeErr error
 			if opts.IO.OutputFormat != "text" && opts.IO.OutputFormat != "wide" {
 				// Avoid printing a list of results if a single resource is being pulled,
@@ -131,7 +208,7 @@ func getCmd(configOpts *cmdconfig.Options) *cobra.Command {
 					encodeErr = codec.Encode(cmd.OutOrStdout(), formatted)
 				}
 			} else {
-				encodeErr = codec.Encode(cmd.OutOrStdout(), output)
+				encodeErr = codec.Encode(cmd.OutOrStdout(), fetchTables(ctx, cfg, res.Filters, output.Items))
 			}
 
 			if encodeErr != nil {
@@ -151,14 +228,111 @@ func getCmd(configOpts *cmdconfig.Options) *cobra.Command {
 	return cmd
 }
 
+// printEvent prints a change observed while watching resources.
+func (opts *getOpts) printEvent(output io.Writer, codec format.Codec, event remote.WatchEvent) error {
+	switch codec.Format() {
+	case "text", "wide":
+		return codec.Encode(output, event)
+	case cmdio.JSONPathFormat, cmdio.GoTemplateFormat, cmdio.CustomColumnsFormat, cmdio.NameFormat:
+		// Printers describe resources, not events.
+		return codec.Encode(output, event.Resource.Object.Object)
+	}
+
+	// Events are printed as a stream of documents.
+	if opts.IO.OutputFormat == string(format.YAML) {
+		if _, err := io.WriteString(output, "---\n"); err != nil {
+			return err
+		}
+	}
+
+	return codec.Encode(output, map[string]any{
+		"type":   event.Type,
+		"object": event.Resource.Object.Object,
+	})
+}
+
+// sortByJSONPath sorts resources by the value found at the given JSONPath.
+// The sort is stable, and resources without a value come first.
+func sortByJSONPath(items []unstructured.Unstructured, expr string) error {
+	parser, err := cmdio.NewJSONPath(expr)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]any, len(items))
+	for i := range items {
+		results, err := parser.FindResults(items[i].Object)
+		if err != nil {
+			return fmt.Errorf("could not sort by '%s': %w", expr, err)
+		}
+
+		if len(results) != 0 && len(results[0]) != 0 && results[0][0].IsValid() && results[0][0].CanInterface() {
+			keys[i] = results[0][0].Interface()
+		}
+	}
+
+	indexes := make([]int, len(items))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	slices.SortStableFunc(indexes, func(a, b int) int {
+		return compareSortKeys(keys[a], keys[b])
+	})
+
+	sorted := make([]unstructured.Unstructured, len(items))
+	for i, index := range indexes {
+		sorted[i] = items[index]
+	}
+	copy(items, sorted)
+
+	return nil
+}
+
+func compareSortKeys(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	aNumber, aIsNumber := toFloat(a)
+	bNumber, bIsNumber := toFloat(b)
+	if aIsNumber && bIsNumber {
+		return cmp.Compare(aNumber, bNumber)
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func toFloat(value any) (float64, bool) {
+	switch number := value.(type) {
+	case int64:
+		return float64(number), true
+	case int:
+		return float64(number), true
+	case float64:
+		return number, true
+	default:
+		return 0, false
+	}
+}
+
 // hack: unstructured objects are serialized with a top-level "object" key,
 // which we don't want, so instead we have a different type for JSON / YAML outputs.
 type printItems struct {
 	Items []map[string]any `json:"items" yaml:"items"`
 }
 
+// tableCodec prints resources as tables, one per kind of resource.
 type tableCodec struct {
 	wide bool
+
+	// Whether headers were printed for watch events.
+	headersPrinted bool
 }
 
 func (c *tableCodec) Format() format.Format {
@@ -170,24 +344,49 @@ func (c *tableCodec) Format() format.Format {
 }
 
 func (c *tableCodec) Encode(output io.Writer, input any) error {
-	//nolint:forcetypeassert
-	items := input.(unstructured.UnstructuredList)
-
-	// TODO: support per-kind column definitions.
-	//
-	// Read more about type & format here:
-	// https://github.com/OAI/OpenAPI-Specification/blob/main/versions/2.0.md#data-types
-	//
-	// Priority is 0-based (from most important to least important)
-	// and controls whether columns are omitted in (wide: false) tables.
+	switch typed := input.(type) {
+	case []*metav1.Table:
+		return c.printTables(output, typed)
+	case unstructured.UnstructuredList:
+		return c.printTables(output, clientTables(typed.Items))
+	case remote.WatchEvent:
+		return c.printEvent(output, typed)
+	default:
+		return fmt.Errorf("table codec cannot encode %T", input)
+	}
+}
+
+// printTables prints one table per kind of resource, separated by an empty line.
+func (c *tableCodec) printTables(output io.Writer, tables []*metav1.Table) error {
+	for i, table := range tables {
+		if i != 0 {
+			if _, err := io.WriteString(output, "\n"); err != nil {
+				return err
+			}
+		}
+
+		if err := c.print(output, table, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printEvent prints a watch event.
+// Events can be about resources of any kind: only the generic columns are printed.
+func (c *tableCodec) printEvent(output io.Writer, event remote.WatchEvent) error {
+	obj := event.Resource.ToUnstructured()
+
 	table := &metav1.Table{
 		ColumnDefinitions: []metav1.TableColumnDefinition{
 			{
-				Name:        "KIND",
+				Name:        "EVENT",
 				Type:        "string",
 				Priority:    0,
-				Description: "The kind of the resource.",
+				Description: "The type of change made to the resource.",
 			},
+			kindColumnDefinition(),
 			{
 				Name:        "NAME",
 				Type:        "string",
@@ -208,55 +407,52 @@ func (c *tableCodec) Encode(output io.Writer, input any) error {
 				Description: "The age of the resource.",
 			},
 		},
+		Rows: []metav1.TableRow{
+			{
+				Cells: []any{
+					string(event.Type),
+					obj.GetKind(),
+					obj.GetName(),
+					obj.GetNamespace(),
+					duration.HumanDuration(time.Since(obj.GetCreationTimestamp().Time)),
+				},
+				Object: runtime.RawExtension{
+					Object: &obj,
+				},
+			},
+		},
 	}
 
+	// Watch events are printed one at a time: the headers must only be
+	// printed for the first one.
+	noHeaders := c.headersPrinted
+	c.headersPrinted = true
+
+	return c.print(output, table, noHeaders)
+}
+
+func (c *tableCodec) print(output io.Writer, table *metav1.Table, noHeaders bool) error {
 	if c.wide {
 		table.ColumnDefinitions = append(table.ColumnDefinitions, metav1.TableColumnDefinition{
 			Name:     "GROUPVERSION",
 			Type:     "string",
 			Priority: 0,
 		})
-	}
 
-	for _, r := range items.Items {
-		age := duration.HumanDuration(time.Since(r.GetCreationTimestamp().Time))
-		var row metav1.TableRow
-		if c.wide {
-			row = metav1.TableRow{
-				Cells: []any{
-					r.GetKind(),
-					r.GetName(),
-					r.GetNamespace(),
-					age,
-					r.GroupVersionKind().GroupVersion().String(),
-				},
-				Object: runtime.RawExtension{
-					Object: &r,
-				},
-			}
-		} else {
-			row = metav1.TableRow{
-				Cells: []any{
-					r.GetKind(),
-					r.GetName(),
-					r.GetNamespace(),
-					age,
-				},
-				Object: runtime.RawExtension{
-					Object: &r,
-				},
+		for i, row := range table.Rows {
+			groupVersion := ""
+			if row.Object.Object != nil {
+				groupVersion = row.Object.Object.GetObjectKind().GroupVersionKind().GroupVersion().String()
 			}
-		}
 
-		table.Rows = append(table.Rows, row)
+			table.Rows[i].Cells = append(row.Cells, groupVersion)
+		}
 	}
 
 	printer := printers.NewTablePrinter(printers.PrintOptions{
+		NoHeaders:  noHeaders,
 		Wide:       c.wide,
 		ShowLabels: c.wide,
-		// TODO: sorting doesn't actually do anything,
-		// though it is supported in the options.
-		// SortBy:     "name",
 	})
 
 	return printer.PrintObj(table, output)
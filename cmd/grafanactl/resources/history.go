@@ -0,0 +1,254 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	cmdconfig "github.com/grafana/grafanactl/cmd/grafanactl/config"
+	"github.com/grafana/grafanactl/cmd/grafanactl/fail"
+	cmdio "github.com/grafana/grafanactl/cmd/grafanactl/io"
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/grafana"
+	"github.com/grafana/grafanactl/internal/logs"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/remote"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type historyOpts struct {
+	IO cmdio.Options
+}
+
+func (opts *historyOpts) setup(flags *pflag.FlagSet) {
+	opts.IO.RegisterCustomCodec("text", &versionsTabCodec{})
+	opts.IO.DefaultFormat("text")
+	opts.IO.BindFlags(flags)
+}
+
+func (opts *historyOpts) Validate() error {
+	return opts.IO.Validate()
+}
+
+func historyCmd(configOpts *cmdconfig.Options) *cobra.Command {
+	opts := &historyOpts{}
+
+	cmd := &cobra.Command{
+		Use:               "history RESOURCE_SELECTOR",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSingleSelector(configOpts),
+		Short:             "List the versions of a resource in Grafana",
+		Long: `List the versions of a resource in Grafana, most recent first.
+
+The history of resources is read from the Kubernetes-style API. For dashboards, the
+legacy versions API is used on Grafana instances that don't expose it.
+
+A version can be printed with 'resources get --version', and restored with 'resources rollback'.`,
+		Example: `
+	# List the versions of a dashboard
+	grafanactl resources history dashboards/foo`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+
+			codec, err := opts.IO.Codec()
+			if err != nil {
+				return err
+			}
+
+			history, target, err := historyTarget(ctx, configOpts, args)
+			if err != nil {
+				return err
+			}
+
+			versions, err := history.Versions(ctx, target.Descriptor, target.ResourceUIDs[0])
+			if err != nil {
+				return err
+			}
+
+			return codec.Encode(cmd.OutOrStdout(), versions)
+		},
+	}
+
+	opts.setup(cmd.Flags())
+
+	return cmd
+}
+
+type rollbackOpts struct {
+	To int64
+}
+
+func (opts *rollbackOpts) setup(flags *pflag.FlagSet) {
+	flags.Int64Var(&opts.To, "to", opts.To, "Version to restore the resource to")
+}
+
+func (opts *rollbackOpts) Validate() error {
+	if opts.To < 1 {
+		return errors.New("--to must be set to a version greater than zero")
+	}
+
+	return nil
+}
+
+func rollbackCmd(configOpts *cmdconfig.Options) *cobra.Command {
+	opts := &rollbackOpts{}
+
+	cmd := &cobra.Command{
+		Use:               "rollback RESOURCE_SELECTOR --to VERSION",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSingleSelector(configOpts),
+		Short:             "Restore a resource to a previous version",
+		Long: `Restore a resource to a previous version.
+
+The content of the resource at the given version is saved as a new version: the
+rollback itself appears in the history of the resource. Versions are listed with
+'resources history'.`,
+		Example: `
+	# Restore a dashboard to its third version
+	grafanactl resources rollback dashboards/foo --to 3`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+
+			history, target, err := historyTarget(ctx, configOpts, args)
+			if err != nil {
+				return err
+			}
+
+			name := target.ResourceUIDs[0]
+			if err := history.Rollback(ctx, target.Descriptor, name, opts.To); err != nil {
+				return versionError(err, target.Descriptor, name, opts.To)
+			}
+
+			cmdio.Success(cmd.OutOrStdout(), "%s/%s rolled back to version %d", target.Descriptor.Kind, name, opts.To)
+
+			return nil
+		},
+	}
+
+	opts.setup(cmd.Flags())
+
+	return cmd
+}
+
+// fetchVersion fetches a single resource as it was at the given version.
+func fetchVersion(
+	ctx context.Context, configOpts *cmdconfig.Options, args []string, version int64,
+) (*fetchResponse, error) {
+	history, target, err := historyTarget(ctx, configOpts, args)
+	if err != nil {
+		return nil, err
+	}
+
+	name := target.ResourceUIDs[0]
+
+	res, err := history.Get(ctx, target.Descriptor, name, version)
+	if err != nil {
+		return nil, versionError(err, target.Descriptor, name, version)
+	}
+
+	return &fetchResponse{
+		Resources:      *resources.NewResources(res),
+		IsSingleTarget: true,
+		Filters:        resources.Filters{target},
+		PullSummary:    &remote.OperationSummary{},
+	}, nil
+}
+
+// historyTarget returns the history of the Grafana instance of the current
+// context, and the filter designating the single resource selected by args.
+func historyTarget(
+	ctx context.Context, configOpts *cmdconfig.Options, args []string,
+) (*remote.History, resources.Filter, error) {
+	fullConfig, err := configOpts.LoadConfig(ctx)
+	if err != nil {
+		return nil, resources.Filter{}, err
+	}
+
+	cfg, err := configOpts.LoadRESTConfig(ctx)
+	if err != nil {
+		return nil, resources.Filter{}, err
+	}
+
+	_, filters, err := fetchFilters(ctx, fetchRequest{
+		Config:             cfg,
+		ExpectSingleTarget: true,
+	}, args)
+	if err != nil {
+		return nil, resources.Filter{}, err
+	}
+
+	// The legacy versions API is only a fallback: the history can still be
+	// read from the Kubernetes-style API without it.
+	var legacy remote.LegacyDashboardVersions
+	if versions, err := grafana.NewDashboardVersionsClient(fullConfig.GetCurrentContext()); err != nil {
+		logging.FromContext(ctx).Debug("Legacy versions API unavailable", logs.Err(err))
+	} else {
+		legacy = versions
+	}
+
+	history, err := remote.NewHistory(cfg, legacy)
+	if err != nil {
+		return nil, resources.Filter{}, err
+	}
+
+	return history, filters[0], nil
+}
+
+func versionError(err error, desc resources.Descriptor, name string, version int64) error {
+	if !errors.Is(err, remote.ErrVersionNotFound) {
+		return err
+	}
+
+	return fail.DetailedError{
+		Summary: "Version not found",
+		Details: fmt.Sprintf("%s/%s has no version %d.", desc.Kind, name, version),
+		Suggestions: []string{
+			fmt.Sprintf("List the versions of the resource with 'grafanactl resources history %s/%s'", desc.Plural, name),
+		},
+	}
+}
+
+type versionsTabCodec struct{}
+
+func (c *versionsTabCodec) Format() format.Format {
+	return "text"
+}
+
+func (c *versionsTabCodec) Encode(output io.Writer, input any) error {
+	versions, ok := input.([]remote.Version)
+	if !ok {
+		return fmt.Errorf("expected []remote.Version, got %T", input)
+	}
+
+	out := tabwriter.NewWriter(output, 0, 4, 2, ' ', tabwriter.TabIndent|tabwriter.DiscardEmptyColumns)
+
+	fmt.Fprintf(out, "VERSION\tAUTHOR\tTIMESTAMP\tMESSAGE\n")
+
+	for _, version := range versions {
+		timestamp := ""
+		if !version.Timestamp.IsZero() {
+			timestamp = version.Timestamp.Local().Format(time.DateTime)
+		}
+
+		fmt.Fprintf(out, "%d\t%s\t%s\t%s\n", version.Version, version.Author, timestamp, version.Message)
+	}
+
+	return out.Flush()
+}
+
+func (c *versionsTabCodec) Decode(io.Reader, any) error {
+	return errors.New("tab codec does not support decoding")
+}
@@ -0,0 +1,39 @@
+package resources
+
+import (
+	"context"
+	"io"
+
+	cmdconfig "github.com/grafana/grafanactl/cmd/grafanactl/config"
+	cmdio "github.com/grafana/grafanactl/cmd/grafanactl/io"
+	"github.com/grafana/grafanactl/internal/config"
+	"github.com/grafana/grafanactl/internal/resources/journal"
+)
+
+// newOperation creates a journal operation for a command changing resources in Grafana.
+func newOperation(
+	ctx context.Context, configOpts *cmdconfig.Options, cfg config.NamespacedRESTConfig, command string,
+) *journal.Operation {
+	contextName := ""
+	if fullConfig, err := configOpts.LoadConfig(ctx); err == nil {
+		contextName = fullConfig.CurrentContext
+	}
+
+	return journal.NewOperation(command, contextName, cfg)
+}
+
+// saveOperation records an operation in the journal, if it changed anything.
+// Failing to record an operation doesn't fail the command: the resources
+// were already changed.
+func saveOperation(output io.Writer, op *journal.Operation) {
+	if op.IsEmpty() {
+		return
+	}
+
+	if err := (journal.Journal{Dir: journal.DefaultDir()}).Save(op); err != nil {
+		cmdio.Warning(output, "The operation could not be recorded in the journal: %s", err)
+		return
+	}
+
+	cmdio.Info(output, "Operation recorded as %s. Revert it with 'grafanactl undo %[1]s'.", op.ID)
+}
@@ -0,0 +1,43 @@
+package resources
+
+import (
+	cmdconfig "github.com/grafana/grafanactl/cmd/grafanactl/config"
+	"github.com/spf13/cobra"
+)
+
+func labelCmd(configOpts *cmdconfig.Options) *cobra.Command {
+	opts := &metadataOpts{}
+
+	cmd := &cobra.Command{
+		Use:               "label [RESOURCE_SELECTOR]... KEY=VALUE... [KEY-]...",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeSelectors(configOpts),
+		Short:             "Update the labels of resources",
+		Long: `Update the labels of resources, in Grafana or in local files.
+
+Labels are set with KEY=VALUE, and removed with KEY-. Existing labels are only
+given a new value if --overwrite is set.
+
+By default, the labels of the resources matching the selectors are updated in Grafana.
+With --path, the resources read from the given paths are updated instead: files are
+modified in place, and selectors only filter the resources to update.
+`,
+		Example: `
+	# Label a dashboard
+	grafanactl resources label dashboards/foo team=platform
+
+	# Change the label of every dashboard, and remove another one
+	grafanactl resources label dashboards tier=critical service- --overwrite
+
+	# Label the dashboards defined in a directory
+	grafanactl resources label -p ./resources dashboards team=platform
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMetadataCmd(cmd, configOpts, opts, labelsField, args)
+		},
+	}
+
+	opts.setup(cmd.Flags(), labelsField)
+
+	return cmd
+}
@@ -0,0 +1,274 @@
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	cmdio "github.com/grafana/grafanactl/cmd/grafanactl/io"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/lint"
+	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/grafana/grafanactl/internal/resources/overlay"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const defaultLintConfigFile = ".grafanactl-lint.yaml"
+
+type lintOpts struct {
+	IO cmdio.Options
+
+	Paths         []string
+	MaxConcurrent int
+	CUETags       []string
+	Template      templateOpts
+
+	ConfigFile string
+	Enable     []string
+	Disable    []string
+	ListRules  bool
+}
+
+func (opts *lintOpts) setup(flags *pflag.FlagSet) {
+	opts.IO.RegisterCustomCodec("text", &validationTableCodec{})
+	opts.IO.RegisterCustomCodec(sarifFormat, &sarifCodec{})
+	opts.IO.DefaultFormat("text")
+
+	opts.IO.BindFlags(flags)
+
+	flags.StringSliceVarP(&opts.Paths, "path", "p", []string{defaultResourcesPath}, "Paths on disk from which to read the resources.")
+	flags.IntVar(&opts.MaxConcurrent, "max-concurrent", 10, "Maximum number of concurrent operations")
+	bindCUETagsFlag(flags, &opts.CUETags)
+	opts.Template.setup(flags)
+
+	flags.StringVar(&opts.ConfigFile, "lint-config", "", "Path to the linter configuration file. Defaults to '"+defaultLintConfigFile+"' if it exists")
+	flags.StringSliceVar(&opts.Enable, "enable", nil, "IDs of rules to enable, in addition to the configured ones")
+	flags.StringSliceVar(&opts.Disable, "disable", nil, "IDs of rules to disable")
+	flags.BoolVar(&opts.ListRules, "list-rules", opts.ListRules, "List the available rules and exit")
+}
+
+func (opts *lintOpts) Validate() error {
+	if err := opts.IO.Validate(); err != nil {
+		return err
+	}
+
+	if len(opts.Paths) == 0 {
+		return errors.New("at least one path is required")
+	}
+
+	if opts.MaxConcurrent < 1 {
+		return errors.New("max-concurrent must be greater than zero")
+	}
+
+	return opts.Template.Validate()
+}
+
+// linter creates the linter described by the configuration file and flags,
+// along with the registry holding every known rule.
+func (opts *lintOpts) linter() (*lint.Registry, *lint.Linter, error) {
+	cfg := lint.Config{}
+
+	configFile := opts.ConfigFile
+	if configFile == "" {
+		if _, err := os.Stat(defaultLintConfigFile); err == nil {
+			configFile = defaultLintConfigFile
+		}
+	}
+
+	if configFile != "" {
+		loaded, err := lint.LoadConfig(configFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		cfg = loaded
+	}
+
+	for _, id := range opts.Enable {
+		cfg.Enable(id, true)
+	}
+	for _, id := range opts.Disable {
+		cfg.Enable(id, false)
+	}
+
+	registry := lint.NewDefaultRegistry()
+
+	linter, err := lint.NewLinter(registry, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return registry, linter, nil
+}
+
+func lintCmd() *cobra.Command {
+	opts := &lintOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Args:  cobra.NoArgs,
+		Short: "Check that resources follow conventions",
+		Long: `Check that resources follow conventions.
+
+Resources are checked against a set of rules. Each rule reports findings with a
+severity: error, warning or info. The command fails if at least one finding has
+the "error" severity.
+
+Rules can be enabled, disabled or have their severity changed in a configuration
+file ('` + defaultLintConfigFile + `' by default), which can also define custom
+rules as CEL expressions (https://cel.dev):
+
+	rules:
+	  dashboard-tags:
+	    severity: error
+	  dashboard-unique-title:
+	    enabled: false
+	customRules:
+	  - id: dashboard-refresh
+	    description: Dashboards must not refresh more often than every minute
+	    match:
+	      kind: Dashboard
+	    expr: "!has(object.spec.refresh) || object.spec.refresh in ['', '1m', '5m']"
+
+Custom rule expressions must evaluate to true for resources following the rule.
+They can use the 'object' variable (the resource being checked) and the
+'resources' variable (all the resources being linted).
+
+This command does not require access to a Grafana instance.
+`,
+		Example: `
+	# Lint all resources in the default directory
+	grafanactl resources lint
+
+	# List the available rules
+	grafanactl resources lint --list-rules
+
+	# Disable a rule
+	grafanactl resources lint --disable dashboard-tags
+
+	# Write the findings as SARIF, for code scanning tools
+	grafanactl resources lint -o sarif > grafanactl.sarif
+`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+
+			codec, err := opts.IO.Codec()
+			if err != nil {
+				return err
+			}
+
+			registry, linter, err := opts.linter()
+			if err != nil {
+				return err
+			}
+
+			if opts.ListRules {
+				return printRules(cmd.OutOrStdout(), registry, linter)
+			}
+
+			tmpl, err := opts.Template.template()
+			if err != nil {
+				return err
+			}
+
+			reader := local.FSReader{
+				Decoders:           decoders(opts.CUETags),
+				Template:           tmpl,
+				MaxConcurrentReads: opts.MaxConcurrent,
+				StopOnError:        true,
+			}
+
+			resourcesList := resources.NewResources()
+
+			renderer := overlay.Renderer{Reader: &reader}
+			if err := renderer.Read(ctx, resourcesList, nil, opts.Paths); err != nil {
+				return err
+			}
+
+			findings, err := linter.Lint(ctx, resourcesList)
+			if err != nil {
+				return err
+			}
+
+			failures := make([]validationFailure, 0, len(findings))
+			errorsCount := 0
+			for _, finding := range findings {
+				if finding.Severity == lint.SeverityError {
+					errorsCount++
+				}
+
+				file := ""
+				if finding.Resource != nil {
+					file = finding.Resource.SourcePath()
+				}
+
+				failures = append(failures, validationFailure{
+					Resource: finding.Resource,
+					File:     file,
+					Line:     finding.Line,
+					Path:     finding.Path,
+					Rule:     finding.Rule,
+					Severity: string(finding.Severity),
+					Error:    finding.Message,
+				})
+			}
+
+			switch opts.IO.OutputFormat {
+			case "text":
+				if len(failures) == 0 {
+					cmdio.Success(cmd.OutOrStdout(), "No findings.")
+					return nil
+				}
+
+				if err := codec.Encode(cmd.OutOrStdout(), failures); err != nil {
+					return err
+				}
+			case sarifFormat:
+				if err := codec.Encode(cmd.OutOrStdout(), newSARIFLog(linter, failures)); err != nil {
+					return err
+				}
+			default:
+				if err := codec.Encode(cmd.OutOrStdout(), printableFailures(failures)); err != nil {
+					return err
+				}
+			}
+
+			if errorsCount > 0 {
+				return fmt.Errorf("%d error(s) found", errorsCount)
+			}
+
+			return nil
+		},
+	}
+
+	opts.setup(cmd.Flags())
+
+	return cmd
+}
+
+func printRules(output io.Writer, registry *lint.Registry, linter *lint.Linter) error {
+	enabled := make(map[string]bool)
+	for _, rule := range linter.Rules() {
+		enabled[rule.ID()] = true
+	}
+
+	tab := tabwriter.NewWriter(output, 0, 4, 2, ' ', tabwriter.TabIndent|tabwriter.DiscardEmptyColumns)
+
+	fmt.Fprintf(tab, "ID\tENABLED\tSEVERITY\tDESCRIPTION\n")
+	for _, rule := range registry.Rules() {
+		severity := rule.Severity()
+		if enabled[rule.ID()] {
+			severity = linter.Severity(rule)
+		}
+
+		fmt.Fprintf(tab, "%s\t%t\t%s\t%s\n", rule.ID(), enabled[rule.ID()], severity, rule.Description())
+	}
+
+	return tab.Flush()
+}
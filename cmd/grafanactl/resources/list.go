@@ -38,7 +38,15 @@ func listCmd(configOpts *cmdconfig.Options) *cobra.Command {
 		Use:   "list",
 		Args:  cobra.NoArgs,
 		Short: "List available Grafana API resources",
-		Long:  "List available Grafana API resources.",
+		Long: `List available Grafana API resources.
+
+Resources are served by the Kubernetes-style API of Grafana, except those of the
+legacy.grafana.app group: they are served by the legacy HTTP API, for kinds that are
+not available through the Kubernetes-style API yet (data sources, teams, service
+accounts, alert rules and contact points). They are marked "legacy" in the API column.
+
+Resources served by the legacy HTTP API are only pulled when selected explicitly,
+e.g. with 'grafanactl resources pull datasources.legacy'.`,
 		Example: `
 	grafanactl resources list
 `,
@@ -97,17 +105,23 @@ func (c *tabCodec) Encode(output io.Writer, input any) error {
 
 	out := tabwriter.NewWriter(output, 0, 4, 2, ' ', tabwriter.TabIndent|tabwriter.DiscardEmptyColumns)
 	if c.wide {
-		fmt.Fprintf(out, "GROUP\tVERSION\tPLURAL\tSINGULAR\tKIND\n")
+		fmt.Fprintf(out, "GROUP\tVERSION\tPLURAL\tSINGULAR\tKIND\tAPI\n")
 	} else {
-		fmt.Fprintf(out, "GROUP\tVERSION\tPLURAL\n")
+		fmt.Fprintf(out, "GROUP\tVERSION\tPLURAL\tAPI\n")
 	}
 
 	for _, r := range descs {
 		gv := r.GroupVersion
+
+		api := "kubernetes"
+		if r.IsLegacy() {
+			api = "legacy"
+		}
+
 		if c.wide {
-			fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\n", gv.Group, gv.Version, r.Plural, r.Singular, r.Kind)
+			fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\t%s\n", gv.Group, gv.Version, r.Plural, r.Singular, r.Kind, api)
 		} else {
-			fmt.Fprintf(out, "%s\t%s\t%s\n", gv.Group, gv.Version, r.Plural)
+			fmt.Fprintf(out, "%s\t%s\t%s\t%s\n", gv.Group, gv.Version, r.Plural, api)
 		}
 	}
 
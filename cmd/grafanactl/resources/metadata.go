@@ -0,0 +1,407 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	cmdconfig "github.com/grafana/grafanactl/cmd/grafanactl/config"
+	"github.com/grafana/grafanactl/cmd/grafanactl/fail"
+	cmdio "github.com/grafana/grafanactl/cmd/grafanactl/io"
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/legacy"
+	"github.com/grafana/grafanactl/internal/resources/remote"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// metadataField describes the metadata modified by the label and annotate commands.
+type metadataField struct {
+	// Field of the metadata: labels or annotations.
+	Field string
+	// Name of a single entry of the field.
+	Name string
+	// Verb describing the modification of a resource.
+	Verb string
+}
+
+var (
+	labelsField      = metadataField{Field: "labels", Name: "label", Verb: "labeled"}
+	annotationsField = metadataField{Field: "annotations", Name: "annotation", Verb: "annotated"}
+)
+
+// metadataChanges are the changes made to the labels or annotations of resources.
+type metadataChanges struct {
+	field  metadataField
+	set    map[string]string
+	remove []string
+}
+
+// parseMetadataArgs splits the arguments of the label and annotate commands
+// into resource selectors and changes: `key=value` sets a value, `key-` removes it.
+func parseMetadataArgs(field metadataField, args []string) ([]string, metadataChanges, error) {
+	changes := metadataChanges{
+		field: field,
+		set:   make(map[string]string),
+	}
+
+	var selectors []string
+
+	for _, arg := range args {
+		key, value, isSet := strings.Cut(arg, "=")
+		isRemove := !isSet && strings.HasSuffix(arg, "-")
+
+		if !isSet && !isRemove {
+			selectors = append(selectors, arg)
+			continue
+		}
+
+		if isRemove {
+			key = strings.TrimSuffix(arg, "-")
+		}
+
+		if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+			return nil, changes, fmt.Errorf("invalid %s key '%s': %s", field.Name, key, strings.Join(errs, "; "))
+		}
+
+		if _, ok := changes.set[key]; ok || slices.Contains(changes.remove, key) {
+			return nil, changes, fmt.Errorf("%s '%s' is modified more than once", field.Name, key)
+		}
+
+		if isRemove {
+			changes.remove = append(changes.remove, key)
+			continue
+		}
+
+		if field == labelsField {
+			if errs := validation.IsValidLabelValue(value); len(errs) != 0 {
+				return nil, changes, fmt.Errorf("invalid value for label '%s': %s", key, strings.Join(errs, "; "))
+			}
+		}
+
+		changes.set[key] = value
+	}
+
+	if len(changes.set) == 0 && len(changes.remove) == 0 {
+		return nil, changes, fmt.Errorf("at least one %s to set (key=value) or to remove (key-) is required", field.Name)
+	}
+
+	return selectors, changes, nil
+}
+
+// reserved returns the keys of the reserved annotations modified by the changes.
+func (c metadataChanges) reserved() []string {
+	if c.field != annotationsField {
+		return nil
+	}
+
+	var keys []string
+	for _, key := range slices.Concat(slices.Collect(maps.Keys(c.set)), c.remove) {
+		if resources.IsReservedAnnotation(key) {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+// apply applies the changes to an object, and returns true if it was modified.
+// Existing values are only replaced if overwrite is set.
+func (c metadataChanges) apply(obj *unstructured.Unstructured, overwrite bool) (bool, error) {
+	values, _, err := unstructured.NestedStringMap(obj.Object, "metadata", c.field.Field)
+	if err != nil {
+		return false, err
+	}
+
+	if values == nil {
+		values = make(map[string]string)
+	}
+
+	changed := false
+
+	for _, key := range slices.Sorted(maps.Keys(c.set)) {
+		current, ok := values[key]
+		if ok && current == c.set[key] {
+			continue
+		}
+
+		if ok && !overwrite {
+			return false, fmt.Errorf("%s '%s' already has a value (%s), and --overwrite is not set", c.field.Name, key, current)
+		}
+
+		values[key] = c.set[key]
+		changed = true
+	}
+
+	for _, key := range c.remove {
+		if _, ok := values[key]; ok {
+			delete(values, key)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return false, nil
+	}
+
+	if len(values) == 0 {
+		unstructured.RemoveNestedField(obj.Object, "metadata", c.field.Field)
+		return true, nil
+	}
+
+	return true, unstructured.SetNestedStringMap(obj.Object, values, "metadata", c.field.Field)
+}
+
+// metadataSupported returns an error for the resources served by the legacy HTTP API:
+// they can't hold labels nor annotations once pushed.
+func metadataSupported(res *resources.Resource) error {
+	if res.Group() == resources.LegacyGroup {
+		return legacy.ErrMetadataNotSupported
+	}
+
+	return nil
+}
+
+// patch returns the JSON merge patch applying the changes.
+func (c metadataChanges) patch() ([]byte, error) {
+	values := make(map[string]any, len(c.set)+len(c.remove))
+	for key, value := range c.set {
+		values[key] = value
+	}
+
+	for _, key := range c.remove {
+		values[key] = nil
+	}
+
+	return json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			c.field.Field: values,
+		},
+	})
+}
+
+type metadataOpts struct {
+	OnError        OnErrorMode
+	Paths          []string
+	Overwrite      bool
+	Force          bool
+	DryRun         bool
+	IncludeManaged bool
+	MaxConcurrent  int
+}
+
+func (opts *metadataOpts) setup(flags *pflag.FlagSet, field metadataField) {
+	bindOnErrorFlag(flags, &opts.OnError)
+	flags.StringSliceVarP(&opts.Paths, "path", "p", nil, fmt.Sprintf("Paths on disk of the resources to modify. If not set, the %s of the resources in Grafana are modified", field.Field))
+	flags.BoolVar(&opts.Overwrite, "overwrite", opts.Overwrite, fmt.Sprintf("If set, existing %s can be given a new value", field.Field))
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun, "If set, the operation will be simulated. For local files, the changes are printed as a diff")
+	flags.BoolVar(&opts.IncludeManaged, "include-managed", opts.IncludeManaged, "If set, resources managed by other tools will be modified in Grafana")
+	flags.IntVar(&opts.MaxConcurrent, "max-concurrent", 10, "Maximum number of concurrent operations")
+
+	if field == annotationsField {
+		flags.BoolVar(&opts.Force, "force", opts.Force, "If set, the annotations holding manager and source properties can be modified")
+	}
+}
+
+func (opts *metadataOpts) Validate(selectors []string, changes metadataChanges) error {
+	if opts.MaxConcurrent < 1 {
+		return errors.New("max-concurrent must be greater than zero")
+	}
+
+	if len(opts.Paths) == 0 && len(selectors) == 0 {
+		return errors.New("either --path or resource selectors need to be specified")
+	}
+
+	if reserved := changes.reserved(); len(reserved) != 0 && !opts.Force {
+		return fail.DetailedError{
+			Summary: "Reserved annotations",
+			Details: fmt.Sprintf(
+				"The following annotations hold manager or source properties and should not be modified by hand: %s",
+				strings.Join(reserved, ", "),
+			),
+			Suggestions: []string{
+				"Specify the --force flag to modify them anyway.",
+			},
+		}
+	}
+
+	return opts.OnError.Validate()
+}
+
+// runMetadataCmd modifies the labels or annotations of resources,
+// in Grafana or in local files.
+func runMetadataCmd(
+	cmd *cobra.Command, configOpts *cmdconfig.Options, opts *metadataOpts, field metadataField, args []string,
+) error {
+	selectors, changes, err := parseMetadataArgs(field, args)
+	if err != nil {
+		return err
+	}
+
+	if err := opts.Validate(selectors, changes); err != nil {
+		return err
+	}
+
+	if len(opts.Paths) != 0 {
+		return updateLocalMetadata(cmd.Context(), cmd.OutOrStdout(), configOpts, opts, changes, selectors)
+	}
+
+	return updateRemoteMetadata(cmd.Context(), cmd.OutOrStdout(), configOpts, opts, changes, selectors)
+}
+
+func updateLocalMetadata(
+	ctx context.Context, output io.Writer, configOpts *cmdconfig.Options, opts *metadataOpts, changes metadataChanges, selectors []string,
+) error {
+	list, err := readSources(ctx, configOpts, sourcesRequest{
+		Paths:         opts.Paths,
+		MaxConcurrent: opts.MaxConcurrent,
+		StopOnError:   opts.OnError.StopOnError(),
+	}, selectors)
+	if err != nil {
+		return err
+	}
+
+	changed := 0
+	failed := 0
+
+	for _, res := range list {
+		if res.SourceFormat() == format.CUE {
+			cmdio.Warning(output, "%s: resources evaluated from CUE packages can not be %s", res.SourcePath(), changes.field.Verb)
+			continue
+		}
+
+		var modified bool
+		err := metadataSupported(res)
+		if err == nil {
+			modified, err = changes.apply(&res.Object, opts.Overwrite)
+		}
+
+		if err == nil && modified {
+			modified, err = rewriteSource(output, res, opts.DryRun)
+		}
+
+		if err != nil {
+			if opts.OnError.StopOnError() {
+				return fmt.Errorf("%s: %w", res.SourcePath(), err)
+			}
+
+			cmdio.Error(output, "%s: %s", res.SourcePath(), err)
+			failed++
+			continue
+		}
+
+		if !modified {
+			continue
+		}
+
+		changed++
+
+		if !opts.DryRun {
+			cmdio.Success(output, "%s %s", res.SourcePath(), changes.field.Verb)
+		}
+	}
+
+	return reportMetadataSummary(output, opts, changes, changed, failed)
+}
+
+func updateRemoteMetadata(
+	ctx context.Context, output io.Writer, configOpts *cmdconfig.Options, opts *metadataOpts, changes metadataChanges, selectors []string,
+) error {
+	cfg, err := configOpts.LoadRESTConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	fetched, err := fetchResources(ctx, fetchRequest{
+		Config:      cfg,
+		StopOnError: opts.OnError.StopOnError(),
+	}, selectors)
+	if err != nil {
+		return err
+	}
+
+	// Conflicts are detected before patching, on the current state of resources.
+	toPatch := resources.NewResources()
+	failed := 0
+
+	for _, res := range fetched.Resources.AsList() {
+		modified, err := changes.apply(res.Object.DeepCopy(), opts.Overwrite)
+		if err != nil {
+			if opts.OnError.StopOnError() {
+				return fmt.Errorf("%s/%s: %w", res.Kind(), res.Name(), err)
+			}
+
+			cmdio.Error(output, "%s/%s: %s", res.Kind(), res.Name(), err)
+			failed++
+			continue
+		}
+
+		if modified {
+			toPatch.Add(res)
+		}
+	}
+
+	patch, err := changes.patch()
+	if err != nil {
+		return err
+	}
+
+	if opts.DryRun {
+		cmdio.Info(output, "Dry-run mode enabled")
+	}
+
+	patcher, err := remote.NewPatcher(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	summary, err := patcher.Patch(ctx, remote.PatchRequest{
+		Resources:      toPatch,
+		Type:           types.MergePatchType,
+		Patch:          patch,
+		MaxConcurrency: opts.MaxConcurrent,
+		StopOnError:    opts.OnError.StopOnError(),
+		DryRun:         opts.DryRun,
+		IncludeManaged: opts.IncludeManaged,
+		OnPatch: func(res *resources.Resource) {
+			cmdio.Success(output, "%s/%s %s", res.Kind(), res.Name(), changes.field.Verb)
+		},
+	})
+	if err != nil {
+		if summary != nil {
+			cmdio.Warning(output, "%d resources %s, %d errors (aborted)", summary.SuccessCount(), changes.field.Verb, failed+summary.FailedCount())
+		}
+		return err
+	}
+
+	return reportMetadataSummary(output, opts, changes, summary.SuccessCount(), failed+summary.FailedCount())
+}
+
+func reportMetadataSummary(output io.Writer, opts *metadataOpts, changes metadataChanges, changed int, failed int) error {
+	printer := cmdio.Success
+	if failed != 0 {
+		printer = cmdio.Warning
+		if changed == 0 {
+			printer = cmdio.Error
+		}
+	}
+
+	printer(output, "%d resources %s, %d errors", changed, changes.field.Verb, failed)
+
+	if opts.OnError.FailOnErrors() && failed > 0 {
+		return fmt.Errorf("%d resource(s) could not be %s", failed, changes.field.Verb)
+	}
+
+	return nil
+}
@@ -0,0 +1,319 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	cmdconfig "github.com/grafana/grafanactl/cmd/grafanactl/config"
+	cmdio "github.com/grafana/grafanactl/cmd/grafanactl/io"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/remote"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type ownershipOpts struct {
+	OnError       OnErrorMode
+	DryRun        bool
+	MaxConcurrent int
+	Suspend       bool
+}
+
+func (opts *ownershipOpts) setup(flags *pflag.FlagSet) {
+	bindOnErrorFlag(flags, &opts.OnError)
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun, "If set, the operation will be simulated")
+	flags.IntVar(&opts.MaxConcurrent, "max-concurrent", 10, "Maximum number of concurrent operations")
+}
+
+func (opts *ownershipOpts) Validate() error {
+	if opts.MaxConcurrent < 1 {
+		return errors.New("max-concurrent must be greater than zero")
+	}
+
+	return opts.OnError.Validate()
+}
+
+func adoptCmd(configOpts *cmdconfig.Options) *cobra.Command {
+	opts := &ownershipOpts{}
+
+	cmd := &cobra.Command{
+		Use:               "adopt RESOURCE_SELECTOR...",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeSelectors(configOpts),
+		Short:             "Make grafanactl the manager of resources",
+		Long: `Make grafanactl the manager of resources.
+
+The manager properties of the resources are rewritten in Grafana to designate grafanactl,
+so that 'push' updates them. The previous manager of a resource, if any, is recorded
+in the grafana.app/adoptedFrom annotation. Resources whose management by grafanactl
+was suspended are resumed.
+
+Local files pulled before the adoption still designate the previous manager: pull
+the resources again before pushing them.
+`,
+		Example: `
+	# Adopt a dashboard managed by Terraform
+	grafanactl resources adopt dashboards/foo
+
+	# Adopt every dashboard and folder
+	grafanactl resources adopt dashboards folders
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+
+			return updateOwnership(cmd.Context(), cmd.OutOrStdout(), configOpts, opts, args, adoption{})
+		},
+	}
+
+	opts.setup(cmd.Flags())
+
+	return cmd
+}
+
+func releaseCmd(configOpts *cmdconfig.Options) *cobra.Command {
+	opts := &ownershipOpts{}
+
+	cmd := &cobra.Command{
+		Use:               "release RESOURCE_SELECTOR...",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeSelectors(configOpts),
+		Short:             "Stop managing resources with grafanactl",
+		Long: `Stop managing resources with grafanactl.
+
+The manager and source properties of the resources managed by grafanactl are removed
+in Grafana, so that they can be adopted by another tool, or edited in the UI.
+
+With --suspend, grafanactl keeps the ownership of the resources, but their management
+is suspended: 'push' leaves them untouched until they are adopted again.
+`,
+		Example: `
+	# Release a dashboard
+	grafanactl resources release dashboards/foo
+
+	# Temporarily stop pushing a dashboard
+	grafanactl resources release dashboards/foo --suspend
+
+	# Resume the management of the dashboard
+	grafanactl resources adopt dashboards/foo
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+
+			var change ownershipChange = release{}
+			if opts.Suspend {
+				change = suspension{}
+			}
+
+			return updateOwnership(cmd.Context(), cmd.OutOrStdout(), configOpts, opts, args, change)
+		},
+	}
+
+	opts.setup(cmd.Flags())
+	cmd.Flags().BoolVar(&opts.Suspend, "suspend", opts.Suspend, "If set, the management of the resources is suspended rather than released")
+
+	return cmd
+}
+
+// ownershipChange describes a change of the manager properties of resources.
+type ownershipChange interface {
+	// Verb describing the change.
+	Verb() string
+	// Skip returns the reason for which a resource must be left untouched, if any.
+	Skip(res *resources.Resource) string
+	// Annotations returns the annotations to update on a resource.
+	// Annotations with a nil value are removed.
+	Annotations(res *resources.Resource) map[string]any
+}
+
+type adoption struct{}
+
+func (adoption) Verb() string {
+	return "adopted"
+}
+
+func (adoption) Skip(res *resources.Resource) string {
+	props, ok := res.Raw.GetManagerProperties()
+	if ok && props.Kind == resources.ResourceManagerKind && !props.Suspended {
+		return "already managed by grafanactl"
+	}
+
+	return ""
+}
+
+func (adoption) Annotations(res *resources.Resource) map[string]any {
+	annotations := map[string]any{
+		utils.AnnoKeyManagerKind:        string(resources.ResourceManagerKind),
+		utils.AnnoKeyManagerIdentity:    resources.ResourceManagerIdentity,
+		utils.AnnoKeyManagerAllowsEdits: nil,
+		utils.AnnoKeyManagerSuspended:   nil,
+	}
+
+	props, ok := res.Raw.GetManagerProperties()
+	if !ok || props.Kind == resources.ResourceManagerKind {
+		return annotations
+	}
+
+	annotations[resources.AnnotationAdoptedFrom] = previousManager(props)
+
+	// Source properties describe the files of the previous manager.
+	annotations[utils.AnnoKeySourcePath] = nil
+	annotations[utils.AnnoKeySourceChecksum] = nil
+	annotations[utils.AnnoKeySourceTimestamp] = nil
+
+	return annotations
+}
+
+type release struct{}
+
+func (release) Verb() string {
+	return "released"
+}
+
+func (release) Skip(res *resources.Resource) string {
+	props, ok := res.Raw.GetManagerProperties()
+	if !ok {
+		return "not managed by any tool"
+	}
+
+	if props.Kind != resources.ResourceManagerKind {
+		return fmt.Sprintf("managed by %s", props.Kind)
+	}
+
+	return ""
+}
+
+func (release) Annotations(*resources.Resource) map[string]any {
+	return map[string]any{
+		utils.AnnoKeyManagerKind:        nil,
+		utils.AnnoKeyManagerIdentity:    nil,
+		utils.AnnoKeyManagerAllowsEdits: nil,
+		utils.AnnoKeyManagerSuspended:   nil,
+		utils.AnnoKeySourcePath:         nil,
+		utils.AnnoKeySourceChecksum:     nil,
+		utils.AnnoKeySourceTimestamp:    nil,
+		resources.AnnotationAdoptedFrom: nil,
+	}
+}
+
+type suspension struct{}
+
+func (suspension) Verb() string {
+	return "suspended"
+}
+
+func (suspension) Skip(res *resources.Resource) string {
+	props, ok := res.Raw.GetManagerProperties()
+
+	switch {
+	case !ok:
+		return "not explicitly managed by grafanactl: adopt it first"
+	case props.Kind != resources.ResourceManagerKind:
+		return fmt.Sprintf("managed by %s", props.Kind)
+	case props.Suspended:
+		return "already suspended"
+	default:
+		return ""
+	}
+}
+
+func (suspension) Annotations(*resources.Resource) map[string]any {
+	return map[string]any{
+		utils.AnnoKeyManagerSuspended: "true",
+	}
+}
+
+// previousManager describes the manager of a resource, for the audit annotation.
+func previousManager(props utils.ManagerProperties) string {
+	if props.Identity == "" {
+		return string(props.Kind)
+	}
+
+	return string(props.Kind) + "/" + props.Identity
+}
+
+func updateOwnership(
+	ctx context.Context, output io.Writer, configOpts *cmdconfig.Options, opts *ownershipOpts, args []string, change ownershipChange,
+) error {
+	cfg, err := configOpts.LoadRESTConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	fetched, err := fetchResources(ctx, fetchRequest{
+		Config:      cfg,
+		StopOnError: opts.OnError.StopOnError(),
+	}, args)
+	if err != nil {
+		return err
+	}
+
+	toPatch := resources.NewResources()
+	for _, res := range fetched.Resources.AsList() {
+		if reason := change.Skip(res); reason != "" {
+			cmdio.Info(output, "%s/%s skipped: %s", res.Kind(), res.Name(), reason)
+			continue
+		}
+
+		toPatch.Add(res)
+	}
+
+	if opts.DryRun {
+		cmdio.Info(output, "Dry-run mode enabled")
+	}
+
+	patcher, err := remote.NewPatcher(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	summary, err := patcher.Patch(ctx, remote.PatchRequest{
+		Resources: toPatch,
+		Type:      types.MergePatchType,
+		PatchFor: func(res *resources.Resource) ([]byte, error) {
+			return json.Marshal(map[string]any{
+				"metadata": map[string]any{
+					"annotations": change.Annotations(res),
+				},
+			})
+		},
+		MaxConcurrency: opts.MaxConcurrent,
+		StopOnError:    opts.OnError.StopOnError(),
+		DryRun:         opts.DryRun,
+		// The manager of resources is checked by the ownership change itself.
+		IncludeManaged: true,
+		OnPatch: func(res *resources.Resource) {
+			cmdio.Success(output, "%s/%s %s", res.Kind(), res.Name(), change.Verb())
+		},
+	})
+	if err != nil {
+		if summary != nil {
+			cmdio.Warning(output, "%d resources %s, %d errors (aborted)", summary.SuccessCount(), change.Verb(), summary.FailedCount())
+		}
+		return err
+	}
+
+	printer := cmdio.Success
+	if summary.FailedCount() != 0 {
+		printer = cmdio.Warning
+		if summary.SuccessCount() == 0 {
+			printer = cmdio.Error
+		}
+	}
+
+	printer(output, "%d resources %s, %d errors", summary.SuccessCount(), change.Verb(), summary.FailedCount())
+
+	if opts.OnError.FailOnErrors() && summary.FailedCount() > 0 {
+		return fmt.Errorf("%d resource(s) could not be %s", summary.FailedCount(), change.Verb())
+	}
+
+	return nil
+}
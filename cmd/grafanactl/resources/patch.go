@@ -0,0 +1,219 @@
+package resources
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/goccy/go-yaml"
+	cmdconfig "github.com/grafana/grafanactl/cmd/grafanactl/config"
+	cmdio "github.com/grafana/grafanactl/cmd/grafanactl/io"
+	"github.com/grafana/grafanactl/internal/resources/remote"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var patchTypes = map[string]types.PatchType{
+	"json":      types.JSONPatchType,
+	"merge":     types.MergePatchType,
+	"strategic": types.StrategicMergePatchType,
+}
+
+type patchOpts struct {
+	OnError        OnErrorMode
+	Type           string
+	Patch          string
+	PatchFile      string
+	MaxConcurrent  int
+	DryRun         bool
+	IncludeManaged bool
+}
+
+func (opts *patchOpts) setup(flags *pflag.FlagSet) {
+	bindOnErrorFlag(flags, &opts.OnError)
+	flags.StringVar(&opts.Type, "type", "merge", "The type of patch. One of: json, merge, strategic")
+	flags.StringVarP(&opts.Patch, "patch", "p", opts.Patch, "The patch to apply, in JSON or YAML")
+	flags.StringVar(&opts.PatchFile, "patch-file", opts.PatchFile, "A file containing the patch to apply, in JSON or YAML. Use '-' to read it from stdin")
+	flags.IntVar(&opts.MaxConcurrent, "max-concurrent", 10, "Maximum number of concurrent operations")
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun, "If set, the patch operation will be simulated")
+	flags.BoolVar(&opts.IncludeManaged, "include-managed", opts.IncludeManaged, "If set, resources managed by other tools will be patched")
+}
+
+func (opts *patchOpts) Validate() error {
+	if _, ok := patchTypes[opts.Type]; !ok {
+		return fmt.Errorf("unsupported patch type '%s'. Valid types are: json, merge, strategic", opts.Type)
+	}
+
+	if opts.Patch == "" && opts.PatchFile == "" {
+		return errors.New("either --patch or --patch-file is required")
+	}
+
+	if opts.Patch != "" && opts.PatchFile != "" {
+		return errors.New("--patch and --patch-file are mutually exclusive")
+	}
+
+	if opts.MaxConcurrent < 1 {
+		return errors.New("max-concurrent must be greater than zero")
+	}
+
+	return opts.OnError.Validate()
+}
+
+// patch reads the patch, and converts it to JSON.
+func (opts *patchOpts) patch(stdin io.Reader) ([]byte, error) {
+	raw := []byte(opts.Patch)
+
+	if opts.PatchFile != "" {
+		var err error
+
+		if opts.PatchFile == "-" {
+			raw, err = io.ReadAll(stdin)
+		} else {
+			raw, err = os.ReadFile(opts.PatchFile)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	patch, err := yaml.YAMLToJSON(raw)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the patch: %w", err)
+	}
+
+	var value any
+	if err := json.Unmarshal(patch, &value); err != nil {
+		return nil, fmt.Errorf("could not parse the patch: %w", err)
+	}
+
+	// JSON patches are lists of operations, the other types are partial objects.
+	switch value.(type) {
+	case []any:
+		if opts.Type != "json" {
+			return nil, fmt.Errorf("a %s patch must be an object", opts.Type)
+		}
+	case map[string]any:
+		if opts.Type == "json" {
+			return nil, errors.New("a json patch must be a list of operations")
+		}
+	default:
+		return nil, errors.New("the patch must be an object or a list of operations")
+	}
+
+	compact := &bytes.Buffer{}
+	if err := json.Compact(compact, patch); err != nil {
+		return nil, err
+	}
+
+	return compact.Bytes(), nil
+}
+
+func patchCmd(configOpts *cmdconfig.Options) *cobra.Command {
+	opts := &patchOpts{}
+
+	cmd := &cobra.Command{
+		Use:               "patch RESOURCE_SELECTOR... (-p PATCH | --patch-file FILE)",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeSelectors(configOpts),
+		Short:             "Patch resources in Grafana",
+		Long: `Patch resources in Grafana.
+
+The patch is applied to every resource matching the selectors, directly by Grafana.
+Three types of patches are supported:
+
+  - merge: a JSON merge patch (RFC 7386), describing the fields to change.
+    Fields set to null are removed.
+  - json: a JSON patch (RFC 6902), describing a list of operations.
+  - strategic: a strategic merge patch, if supported by the resource's API.
+
+Patches can be written in JSON or YAML.
+
+Like 'push', resources managed by other tools are not modified unless --include-managed is set.
+`,
+		Example: `
+	# Change the title of a dashboard
+	grafanactl resources patch dashboards/foo -p '{"spec":{"title":"Foo"}}'
+
+	# Add a tag to a dashboard with a JSON patch
+	grafanactl resources patch dashboards/foo --type json -p '[{"op":"add","path":"/spec/tags/-","value":"prod"}]'
+
+	# Apply a patch read from a file to every dashboard, without modifying them
+	grafanactl resources patch dashboards --patch-file ./patch.yaml --dry-run
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+
+			patch, err := opts.patch(cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+
+			cfg, err := configOpts.LoadRESTConfig(ctx)
+			if err != nil {
+				return err
+			}
+
+			res, err := fetchResources(ctx, fetchRequest{
+				Config:      cfg,
+				StopOnError: opts.OnError.StopOnError(),
+			}, args)
+			if err != nil {
+				return err
+			}
+
+			if opts.DryRun {
+				cmdio.Info(cmd.OutOrStdout(), "Dry-run mode enabled")
+			}
+
+			patcher, err := remote.NewPatcher(ctx, cfg)
+			if err != nil {
+				return err
+			}
+
+			summary, err := patcher.Patch(ctx, remote.PatchRequest{
+				Resources:      &res.Resources,
+				Type:           patchTypes[opts.Type],
+				Patch:          patch,
+				MaxConcurrency: opts.MaxConcurrent,
+				StopOnError:    opts.OnError.StopOnError(),
+				DryRun:         opts.DryRun,
+				IncludeManaged: opts.IncludeManaged,
+			})
+			if err != nil {
+				if summary != nil {
+					cmdio.Warning(cmd.OutOrStdout(), "%d resources patched, %d errors (aborted)", summary.SuccessCount(), summary.FailedCount())
+				}
+				return err
+			}
+
+			// Reporting time.
+			printer := cmdio.Success
+			if summary.FailedCount() != 0 {
+				printer = cmdio.Warning
+				if summary.SuccessCount() == 0 {
+					printer = cmdio.Error
+				}
+			}
+
+			printer(cmd.OutOrStdout(), "%d resources patched, %d errors", summary.SuccessCount(), summary.FailedCount())
+
+			if opts.OnError.FailOnErrors() && summary.FailedCount() > 0 {
+				return fmt.Errorf("%d resource(s) failed to patch", summary.FailedCount())
+			}
+
+			return nil
+		},
+	}
+
+	opts.setup(cmd.Flags())
+
+	return cmd
+}
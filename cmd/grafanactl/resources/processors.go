@@ -0,0 +1,138 @@
+package resources
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/grafana/grafanactl/internal/config"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/grafana/grafanactl/internal/resources/process"
+	"github.com/grafana/grafanactl/internal/resources/remote"
+	"github.com/spf13/pflag"
+)
+
+// processorOpts declares processors from the command line.
+// They run at the stage of the command they are given to: before resources
+// are pushed for push, after they are pulled for pull.
+type processorOpts struct {
+	SetLabels      map[string]string
+	RemoveLabels   []string
+	StripIDs       bool
+	DatasourceUIDs map[string]string
+	Exec           []string
+}
+
+func (opts *processorOpts) setup(flags *pflag.FlagSet) {
+	flags.StringToStringVar(&opts.SetLabels, "set-label", nil, "Set a label on the resources, e.g. env=prod. Can be repeated")
+	flags.StringSliceVar(&opts.RemoveLabels, "remove-label", nil, "Remove a label from the resources. Can be repeated")
+	flags.BoolVar(&opts.StripIDs, "strip-ids", opts.StripIDs, "Remove the numeric database id from the spec of the resources")
+	flags.StringToStringVar(&opts.DatasourceUIDs, "map-datasource", nil, "Rewrite the UID of a datasource referenced by the resources, e.g. prometheus-dev=prometheus-prod. Can be repeated")
+	flags.StringArrayVar(&opts.Exec, "exec", nil, "Process the resources with an external command, reading and writing JSON on its standard input and output. Arguments can be quoted as in a shell. Can be repeated")
+}
+
+// processors returns the processors declared on the command line.
+func (opts *processorOpts) processors() ([]remote.Processor, error) {
+	return buildProcessors(config.Processor{
+		SetLabels:      opts.SetLabels,
+		RemoveLabels:   opts.RemoveLabels,
+		StripIDs:       opts.StripIDs,
+		DatasourceUIDs: opts.DatasourceUIDs,
+	}, opts.Exec)
+}
+
+// contextProcessors returns the processors declared in the context for the given stage.
+func contextProcessors(context *config.Context, stage config.ProcessorStage) ([]remote.Processor, error) {
+	var procs []remote.Processor
+
+	for _, declared := range context.ProcessorsFor(stage) {
+		built, err := buildProcessors(declared, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, proc := range built {
+			if len(declared.Kinds) != 0 {
+				proc = &kindProcessor{kinds: declared.Kinds, processor: proc}
+			}
+
+			procs = append(procs, proc)
+		}
+	}
+
+	return procs, nil
+}
+
+// readProcessors returns the processors declared in the context for the read
+// stage, to be given to a local.FSReader.
+func readProcessors(context *config.Context) ([]local.Processor, error) {
+	procs, err := contextProcessors(context, config.StageRead)
+	if err != nil {
+		return nil, err
+	}
+
+	readProcs := make([]local.Processor, 0, len(procs))
+	for _, proc := range procs {
+		readProcs = append(readProcs, proc)
+	}
+
+	return readProcs, nil
+}
+
+func buildProcessors(declared config.Processor, commands []string) ([]remote.Processor, error) {
+	var procs []remote.Processor
+
+	if len(declared.SetLabels) != 0 || len(declared.RemoveLabels) != 0 {
+		procs = append(procs, process.NewLabelSetter(declared.SetLabels, declared.RemoveLabels))
+	}
+
+	if declared.StripIDs {
+		procs = append(procs, &process.IDStripper{})
+	}
+
+	if len(declared.DatasourceUIDs) != 0 {
+		procs = append(procs, process.NewDatasourceUIDMapper(declared.DatasourceUIDs))
+	}
+
+	if len(declared.Command) != 0 {
+		runner, err := process.NewCommandRunner(declared.Command)
+		if err != nil {
+			return nil, err
+		}
+
+		procs = append(procs, runner)
+	}
+
+	for _, command := range commands {
+		args, err := process.SplitCommand(command)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --exec: %w", err)
+		}
+
+		runner, err := process.NewCommandRunner(args)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --exec: %w", err)
+		}
+
+		procs = append(procs, runner)
+	}
+
+	return procs, nil
+}
+
+// kindProcessor restricts a processor to the resources of the given kinds.
+type kindProcessor struct {
+	kinds     []string
+	processor remote.Processor
+}
+
+func (p *kindProcessor) Process(res *resources.Resource) error {
+	if !slices.ContainsFunc(p.kinds, func(kind string) bool {
+		return strings.EqualFold(kind, res.Kind())
+	}) {
+		return nil
+	}
+
+	return p.processor.Process(res)
+}
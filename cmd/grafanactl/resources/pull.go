@@ -1,16 +1,22 @@
 package resources
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"strings"
 
 	cmdconfig "github.com/grafana/grafanactl/cmd/grafanactl/config"
 	cmdio "github.com/grafana/grafanactl/cmd/grafanactl/io"
+	"github.com/grafana/grafanactl/internal/config"
+	"github.com/grafana/grafanactl/internal/resources"
 	"github.com/grafana/grafanactl/internal/resources/local"
 	"github.com/grafana/grafanactl/internal/resources/process"
 	"github.com/grafana/grafanactl/internal/resources/remote"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
 const (
@@ -22,9 +28,14 @@ type pullOpts struct {
 	OnError        OnErrorMode
 	IncludeManaged bool
 	Path           string
+	Watch          bool
+	Processors     processorOpts
 }
 
 func (opts *pullOpts) setup(flags *pflag.FlagSet) {
+	// Resources are written in files that are read again later
+	opts.IO.DisablePrinters()
+
 	// Bind all the flags
 	opts.IO.BindFlags(flags)
 
@@ -36,6 +47,8 @@ func (opts *pullOpts) setup(flags *pflag.FlagSet) {
 		opts.IncludeManaged,
 		"Include resources managed by tools other than grafanactl",
 	)
+	flags.BoolVarP(&opts.Watch, "watch", "w", opts.Watch, "After pulling the resources, watch for changes and keep the files up to date")
+	opts.Processors.setup(flags)
 }
 
 func (opts *pullOpts) Validate() error {
@@ -54,10 +67,19 @@ func pullCmd(configOpts *cmdconfig.Options) *cobra.Command {
 	opts := &pullOpts{}
 
 	cmd := &cobra.Command{
-		Use:   "pull [RESOURCE_SELECTOR]...",
-		Args:  cobra.ArbitraryArgs,
-		Short: "Pull resources from Grafana",
-		Long:  "Pull resources from Grafana using a specific format. See examples below for more details.",
+		Use:               "pull [RESOURCE_SELECTOR]...",
+		Args:              cobra.ArbitraryArgs,
+		ValidArgsFunction: completeSelectors(configOpts),
+		Short:             "Pull resources from Grafana",
+		Long: `Pull resources from Grafana using a specific format. See examples below for more details.
+
+With --watch, the resources are watched once pulled: files are written as soon as resources
+are created or modified in Grafana, and removed when the resources are deleted.
+
+Resources can be modified before being written, with the processors declared for the
+'post-pull' stage in the current context of the configuration, or with flags: labels can
+be set or removed, database ids stripped, datasource UIDs rewritten, or resources
+processed by an external command reading and writing JSON.`,
 		Example: `
 	# Everything:
 
@@ -95,7 +117,15 @@ func pullCmd(configOpts *cmdconfig.Options) *cobra.Command {
 
 	# Multiple resource kinds, long kind format with version:
 
-	grafanactl resources pull dashboards.v1alpha1.dashboard.grafana.app/foo folders.v1alpha1.folder.grafana.app/qux`,
+	grafanactl resources pull dashboards.v1alpha1.dashboard.grafana.app/foo folders.v1alpha1.folder.grafana.app/qux
+
+	# Keep a local copy of the dashboards up to date, for example to capture changes made in the UI:
+
+	grafanactl resources pull dashboards --watch
+
+	# Pull dashboards without their database ids, with their datasources rewritten:
+
+	grafanactl resources pull dashboards --strip-ids --map-datasource prometheus-prod=prometheus-dev`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 
@@ -108,21 +138,39 @@ func pullCmd(configOpts *cmdconfig.Options) *cobra.Command {
 				return err
 			}
 
-			cfg, err := configOpts.LoadRESTConfig(ctx)
+			fullConfig, err := configOpts.LoadConfig(ctx)
+			if err != nil {
+				return err
+			}
+
+			currentContext := fullConfig.GetCurrentContext()
+
+			pullProcs, err := contextProcessors(currentContext, config.StagePostPull)
+			if err != nil {
+				return err
+			}
+
+			flagProcs, err := opts.Processors.processors()
 			if err != nil {
 				return err
 			}
 
-			res, err := fetchResources(cmd.Context(), fetchRequest{
-				Config: cfg,
+			procs := []remote.Processor{
 				// Strip server fields from the resources.
 				// This includes fields like `resourceVersion`, `uid`, etc.
-				Processors: []remote.Processor{
-					&process.ServerFieldsStripper{},
-				},
+				&process.ServerFieldsStripper{},
+			}
+			procs = append(procs, pullProcs...)
+			procs = append(procs, flagProcs...)
+
+			fetchReq := fetchRequest{
+				Config:         currentContext.ToRESTConfig(ctx),
+				Processors:     procs,
 				ExcludeManaged: !opts.IncludeManaged,
 				StopOnError:    opts.OnError.StopOnError(),
-			}, args)
+			}
+
+			res, err := fetchResources(cmd.Context(), fetchReq, args)
 			if err != nil {
 				return err
 			}
@@ -150,6 +198,14 @@ func pullCmd(configOpts *cmdconfig.Options) *cobra.Command {
 
 			printer(cmd.OutOrStdout(), "%d resources pulled, %d errors", pullSummary.SuccessCount(), pullSummary.FailedCount())
 
+			if opts.Watch {
+				cmdio.Info(cmd.OutOrStdout(), "Watching for changes. Press Ctrl+C to stop.")
+
+				return watchResources(ctx, fetchReq, args, false, func(event remote.WatchEvent) error {
+					return opts.mirror(ctx, cmd.OutOrStdout(), &writer, event)
+				})
+			}
+
 			if opts.OnError.FailOnErrors() && pullSummary.FailedCount() > 0 {
 				return fmt.Errorf("%d resource(s) failed to pull", pullSummary.FailedCount())
 			}
@@ -162,3 +218,33 @@ func pullCmd(configOpts *cmdconfig.Options) *cobra.Command {
 
 	return cmd
 }
+
+// mirror applies a change made to a resource to the files on disk.
+func (opts *pullOpts) mirror(ctx context.Context, output io.Writer, writer *local.FSWriter, event remote.WatchEvent) error {
+	res := event.Resource
+	list := resources.NewResources(res)
+
+	// Errors are reported for every event.
+	eventWriter := *writer
+	eventWriter.StopOnError = true
+
+	var err error
+	if event.Type == watch.Deleted {
+		err = eventWriter.Delete(ctx, list)
+	} else {
+		err = eventWriter.Write(ctx, list)
+	}
+
+	if err != nil {
+		if opts.OnError.StopOnError() {
+			return err
+		}
+
+		printLiveLog(output, cmdio.Error, "%s/%s %s: %s", res.Kind(), res.Name(), strings.ToLower(string(event.Type)), err)
+		return nil
+	}
+
+	printLiveLog(output, cmdio.Success, "%s/%s %s", res.Kind(), res.Name(), strings.ToLower(string(event.Type)))
+
+	return nil
+}
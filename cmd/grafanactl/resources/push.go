@@ -3,15 +3,20 @@ package resources
 import (
 	"errors"
 	"fmt"
+	"io"
+	"time"
 
 	cmdconfig "github.com/grafana/grafanactl/cmd/grafanactl/config"
 	cmdio "github.com/grafana/grafanactl/cmd/grafanactl/io"
-	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/config"
 	"github.com/grafana/grafanactl/internal/resources"
 	"github.com/grafana/grafanactl/internal/resources/discovery"
+	"github.com/grafana/grafanactl/internal/resources/journal"
 	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/grafana/grafanactl/internal/resources/overlay"
 	"github.com/grafana/grafanactl/internal/resources/process"
 	"github.com/grafana/grafanactl/internal/resources/remote"
+	"github.com/grafana/grafanactl/internal/secrets"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -23,6 +28,13 @@ type pushOpts struct {
 	DryRun            bool
 	OmitManagerFields bool
 	IncludeManaged    bool
+	CUETags           []string
+	Template          templateOpts
+	Watch             bool
+	Debounce          time.Duration
+	DeleteRemoved     bool
+	Atomic            bool
+	Processors        processorOpts
 }
 
 func (opts *pushOpts) setup(flags *pflag.FlagSet) {
@@ -32,6 +44,13 @@ func (opts *pushOpts) setup(flags *pflag.FlagSet) {
 	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun, "If set, the push operation will be simulated, without actually creating or updating any resources")
 	flags.BoolVar(&opts.OmitManagerFields, "omit-manager-fields", opts.OmitManagerFields, "If set, the manager fields will not be appended to the resources")
 	flags.BoolVar(&opts.IncludeManaged, "include-managed", opts.IncludeManaged, "If set, resources managed by other tools will be included in the push operation")
+	bindCUETagsFlag(flags, &opts.CUETags)
+	opts.Template.setup(flags)
+	flags.BoolVarP(&opts.Watch, "watch", "w", opts.Watch, "Watch the paths for changes and push the modified resources")
+	flags.DurationVar(&opts.Debounce, "debounce", 500*time.Millisecond, "Delay to wait for changes to settle before pushing them, in watch mode")
+	flags.BoolVar(&opts.DeleteRemoved, "delete-removed", opts.DeleteRemoved, "In watch mode, delete from Grafana the resources removed from the files")
+	flags.BoolVar(&opts.Atomic, "atomic", opts.Atomic, "If set, the push stops on the first error and the resources already pushed are rolled back")
+	opts.Processors.setup(flags)
 }
 
 func (opts *pushOpts) Validate() error {
@@ -43,6 +62,22 @@ func (opts *pushOpts) Validate() error {
 		return errors.New("max-concurrent must be greater than zero")
 	}
 
+	if err := opts.Template.Validate(); err != nil {
+		return err
+	}
+
+	if opts.DeleteRemoved && !opts.Watch {
+		return errors.New("--delete-removed requires --watch")
+	}
+
+	if opts.Atomic && opts.Watch {
+		return errors.New("--atomic cannot be used with --watch")
+	}
+
+	if opts.Debounce <= 0 {
+		return errors.New("debounce must be greater than zero")
+	}
+
 	return opts.OnError.Validate()
 }
 
@@ -50,10 +85,27 @@ func pushCmd(configOpts *cmdconfig.Options) *cobra.Command {
 	opts := &pushOpts{}
 
 	cmd := &cobra.Command{
-		Use:   "push [RESOURCE_SELECTOR]...",
-		Args:  cobra.ArbitraryArgs,
-		Short: "Push resources to Grafana",
-		Long:  "Push resources to Grafana using a specific format. See examples below for more details.",
+		Use:               "push [RESOURCE_SELECTOR]...",
+		Args:              cobra.ArbitraryArgs,
+		ValidArgsFunction: completeSelectors(configOpts),
+		Short:             "Push resources to Grafana",
+		Long: `Push resources to Grafana using a specific format. See examples below for more details.
+
+With --watch, the paths are watched once the resources have been pushed: the resources
+defined in modified files are pushed again as the files change. Resources removed from
+the files are only deleted from Grafana if --delete-removed is set.
+
+With --atomic, resources are pushed one at a time and the state of every resource is
+recorded before it is pushed. If a resource fails to be pushed, the push stops and is
+rolled back: updated resources are restored to their previous state and created resources
+are deleted.
+
+Resources can be modified before being pushed, with the processors declared in the
+current context of the configuration or with flags: labels can be set or removed,
+database ids stripped, datasource UIDs rewritten, or resources processed by an external
+command reading and writing JSON. Processors declared for the 'read' stage run as
+resources are read from files, the ones declared for the 'pre-push' stage and the ones
+given as flags run before they are pushed.`,
 		Example: `
 	# Everything:
 
@@ -91,7 +143,31 @@ func pushCmd(configOpts *cmdconfig.Options) *cobra.Command {
 
 	# Multiple resource kinds, long kind format with version:
 
-	grafanactl resources push dashboards.v1alpha1.dashboard.grafana.app/foo folders.v1alpha1.folder.grafana.app/qux`,
+	grafanactl resources push dashboards.v1alpha1.dashboard.grafana.app/foo folders.v1alpha1.folder.grafana.app/qux
+
+	# Resources defined in CUE packages, with tags injected for a given environment:
+
+	grafanactl resources push -p ./cue-resources -t env=prod
+
+	# Resources rendered as templates, with values from a file and the command line:
+
+	grafanactl resources push --values ./values/prod.yaml --set datasource.uid=prometheus-prod
+
+	# Push resources, then push them again as they are modified:
+
+	grafanactl resources push --watch
+
+	# Also delete from Grafana the resources whose files are removed:
+
+	grafanactl resources push --watch --delete-removed
+
+	# Push all the resources or none of them:
+
+	grafanactl resources push --atomic
+
+	# Push dashboards to production, with their datasources rewritten and a label set:
+
+	grafanactl resources push dashboards --map-datasource prometheus-dev=prometheus-prod --set-label env=prod`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 
@@ -99,7 +175,25 @@ func pushCmd(configOpts *cmdconfig.Options) *cobra.Command {
 				return err
 			}
 
-			cfg, err := configOpts.LoadRESTConfig(ctx)
+			fullConfig, err := configOpts.LoadConfig(ctx)
+			if err != nil {
+				return err
+			}
+
+			currentContext := fullConfig.GetCurrentContext()
+			cfg := currentContext.ToRESTConfig(ctx)
+
+			readProcs, err := readProcessors(currentContext)
+			if err != nil {
+				return err
+			}
+
+			pushProcs, err := contextProcessors(currentContext, config.StagePrePush)
+			if err != nil {
+				return err
+			}
+
+			flagProcs, err := opts.Processors.processors()
 			if err != nil {
 				return err
 			}
@@ -121,15 +215,25 @@ func pushCmd(configOpts *cmdconfig.Options) *cobra.Command {
 				return err
 			}
 
+			tmpl, err := opts.Template.template()
+			if err != nil {
+				return err
+			}
+
 			reader := local.FSReader{
-				Decoders:           format.Codecs(),
+				Decoders:           decoders(opts.CUETags),
+				Template:           tmpl,
 				MaxConcurrentReads: opts.MaxConcurrent,
+				Decrypter:          &secrets.Decrypter{},
+				Processors:         readProcs,
 				StopOnError:        opts.OnError.StopOnError(),
 			}
 
 			resourcesList := resources.NewResources()
 
-			if err := reader.Read(ctx, resourcesList, filters, opts.Paths); err != nil {
+			// Overlays are rendered in memory before being pushed.
+			renderer := overlay.Renderer{Reader: &reader}
+			if err := renderer.Read(ctx, resourcesList, filters, opts.Paths); err != nil {
 				return err
 			}
 
@@ -144,6 +248,8 @@ func pushCmd(configOpts *cmdconfig.Options) *cobra.Command {
 				// regardless of the namespace stored in the resource files.
 				process.NewNamespaceOverrider(cfg.Namespace),
 			}
+			procs = append(procs, pushProcs...)
+			procs = append(procs, flagProcs...)
 			if !opts.OmitManagerFields {
 				procs = append(procs, &process.ManagerFieldsAppender{})
 			}
@@ -155,9 +261,34 @@ func pushCmd(configOpts *cmdconfig.Options) *cobra.Command {
 				DryRun:         opts.DryRun,
 				Processors:     procs,
 				IncludeManaged: opts.IncludeManaged,
+				Atomic:         opts.Atomic,
+			}
+
+			var operation *journal.Operation
+			if !opts.DryRun {
+				operation = newOperation(ctx, configOpts, cfg, "push")
+				req.Recorder = operation
+			}
+
+			var watcher *pushWatcher
+			if opts.Watch {
+				// Resources are modified by processors: keep track of them before pushing.
+				watcher = newPushWatcher(opts.Paths)
+				watcher.track(resourcesList)
 			}
 
 			summary, err := pusher.Push(ctx, req)
+
+			rollback := summary.Rollback()
+			if rollback != nil {
+				reportRollback(cmd.OutOrStdout(), summary)
+			}
+
+			// Nothing was changed by a push entirely rolled back.
+			if operation != nil && (rollback == nil || !rollback.Complete()) {
+				saveOperation(cmd.OutOrStdout(), operation)
+			}
+
 			if err != nil {
 				return err
 			}
@@ -172,6 +303,35 @@ func pushCmd(configOpts *cmdconfig.Options) *cobra.Command {
 
 			printer(cmd.OutOrStdout(), "%d resources pushed, %d errors", summary.SuccessCount(), summary.FailedCount())
 
+			if summary.UnvalidatedCount() != 0 {
+				cmdio.Warning(cmd.OutOrStdout(), "%d resources not validated: the legacy HTTP API does not support dry-runs", summary.UnvalidatedCount())
+			}
+
+			if watcher != nil {
+				watcher.untrack(summary)
+
+				watcher.reader = &reader
+				watcher.renderer = &renderer
+				watcher.pusher = pusher
+				watcher.filters = filters
+				watcher.request = req
+				if !opts.DryRun {
+					watcher.newOperation = func(command string) *journal.Operation {
+						return newOperation(ctx, configOpts, cfg, command)
+					}
+				}
+				watcher.output = cmd.OutOrStdout()
+
+				if opts.DeleteRemoved {
+					watcher.deleter, err = remote.NewDeleter(ctx, cfg)
+					if err != nil {
+						return err
+					}
+				}
+
+				return watcher.Watch(ctx, opts.Debounce)
+			}
+
 			if opts.OnError.FailOnErrors() && summary.FailedCount() > 0 {
 				return fmt.Errorf("%d resource(s) failed to push", summary.FailedCount())
 			}
@@ -184,3 +344,43 @@ func pushCmd(configOpts *cmdconfig.Options) *cobra.Command {
 
 	return cmd
 }
+
+// reportRollback describes the rollback of an atomic push.
+func reportRollback(output io.Writer, summary *remote.OperationSummary) {
+	for _, failure := range summary.Failures() {
+		if failure.Resource == nil {
+			cmdio.Error(output, "%s", failure.Error)
+			continue
+		}
+
+		cmdio.Error(output, "%s/%s failed: %s", failure.Resource.Kind(), failure.Resource.Name(), failure.Error)
+	}
+
+	rollback := summary.Rollback()
+
+	for _, res := range rollback.Restored {
+		cmdio.Info(output, "%s/%s restored", res.Kind(), res.Name())
+	}
+
+	for _, res := range rollback.Deleted {
+		cmdio.Info(output, "%s/%s deleted", res.Kind(), res.Name())
+	}
+
+	for _, failure := range rollback.Failures {
+		if failure.Resource == nil {
+			cmdio.Error(output, "rollback failed: %s", failure.Error)
+			continue
+		}
+
+		cmdio.Error(output, "%s/%s could not be rolled back: %s", failure.Resource.Kind(), failure.Resource.Name(), failure.Error)
+	}
+
+	printer := cmdio.Warning
+	if !rollback.Complete() {
+		printer = cmdio.Error
+	}
+
+	printer(output, "Push rolled back: %d resources restored, %d resources deleted, %d errors",
+		len(rollback.Restored), len(rollback.Deleted), len(rollback.Failures),
+	)
+}
@@ -0,0 +1,450 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	cmdio "github.com/grafana/grafanactl/cmd/grafanactl/io"
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/journal"
+	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/grafana/grafanactl/internal/resources/overlay"
+	"github.com/grafana/grafanactl/internal/resources/remote"
+	"github.com/grafana/grafanactl/internal/server/watch"
+)
+
+// pushWatcher pushes resources again whenever the files defining them change.
+//
+// Resources are tracked by source: a file, a CUE package or an overlay.
+// When a source changes, it is read again and only the resources that differ
+// from the ones last pushed are pushed.
+type pushWatcher struct {
+	reader   *local.FSReader
+	renderer *overlay.Renderer
+	pusher   *remote.Pusher
+	// deleter is only set if resources removed from the files must also be
+	// deleted from Grafana.
+	deleter *remote.Deleter
+	filters resources.Filters
+	// request is used as a template for every push.
+	request remote.PushRequest
+	// newOperation creates the journal operation recording a push or a deletion.
+	// Nothing is recorded if it is not set.
+	newOperation func(command string) *journal.Operation
+
+	// Paths that don't point to an overlay.
+	paths []string
+	// Overlay files, rendered again on every change.
+	overlays []string
+
+	output   io.Writer
+	outputMu sync.Mutex
+
+	// sources maps every source to the resources it defined when last pushed.
+	sources map[string]map[string]*resources.Resource
+}
+
+func newPushWatcher(paths []string) *pushWatcher {
+	overlayPaths, plainPaths := splitOverlayPaths(paths)
+
+	overlays := make([]string, 0, len(overlayPaths))
+	for _, path := range overlayPaths {
+		file, _ := overlay.Find(path)
+		overlays = append(overlays, file)
+	}
+
+	return &pushWatcher{
+		paths:    plainPaths,
+		overlays: overlays,
+		sources:  make(map[string]map[string]*resources.Resource),
+	}
+}
+
+// track records the given resources as pushed.
+// It must be called before the resources are modified by the push processors.
+func (w *pushWatcher) track(list *resources.Resources) {
+	_ = list.ForEach(func(res *resources.Resource) error {
+		source := sourceOf(res)
+		if w.sources[source] == nil {
+			w.sources[source] = make(map[string]*resources.Resource)
+		}
+
+		w.sources[source][resourceKey(res)] = snapshot(res)
+		return nil
+	})
+}
+
+// untrack forgets the resources that failed to be pushed, so that they are
+// pushed again on the next change.
+func (w *pushWatcher) untrack(summary *remote.OperationSummary) {
+	for _, failure := range summary.Failures() {
+		if failure.Resource == nil {
+			continue
+		}
+
+		for _, known := range w.sources {
+			delete(known, resourceKey(failure.Resource))
+		}
+	}
+}
+
+// Watch watches the files for changes until the context is cancelled.
+func (w *pushWatcher) Watch(ctx context.Context, debounce time.Duration) error {
+	debouncer := watch.NewDebouncer(debounce, func(files []string) {
+		w.onChange(ctx, files)
+	})
+	defer debouncer.Stop()
+
+	watcher, err := watch.NewWatcher(ctx, debouncer.Add)
+	if err != nil {
+		return err
+	}
+	watcher.OnRemove(debouncer.Add)
+
+	if err := watcher.Add(w.watchTargets()...); err != nil {
+		return err
+	}
+
+	watcher.Watch()
+
+	cmdio.Info(w.output, "Watching for changes. Press Ctrl+C to stop.")
+
+	<-ctx.Done()
+
+	return nil
+}
+
+// watchTargets returns the directories to watch.
+func (w *pushWatcher) watchTargets() []string {
+	var targets []string
+
+	for _, path := range w.paths {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			path = filepath.Dir(path)
+		}
+		targets = append(targets, path)
+	}
+
+	for _, file := range w.overlays {
+		targets = append(targets, filepath.Dir(file))
+	}
+
+	slices.Sort(targets)
+
+	return slices.Compact(targets)
+}
+
+func (w *pushWatcher) onChange(ctx context.Context, files []string) {
+	current := make(map[string]*resources.Resources)
+
+	// Any file referenced by an overlay could have changed: render them again.
+	// Unchanged resources won't be pushed.
+	for _, file := range w.overlays {
+		rendered := resources.NewResources()
+		if err := w.renderer.Render(ctx, rendered, file); err != nil {
+			w.print(cmdio.Error, "%s: %s", file, err)
+			continue
+		}
+
+		current[file] = rendered
+	}
+
+	for _, file := range files {
+		if !isWithinAny(file, w.paths) {
+			continue
+		}
+
+		for _, source := range w.sourcesFor(file) {
+			if _, ok := current[source]; ok {
+				continue
+			}
+
+			read, err := w.read(ctx, source)
+			if err != nil {
+				w.print(cmdio.Error, "%s: %s", source, err)
+				continue
+			}
+
+			current[source] = read
+		}
+	}
+
+	toPush := resources.NewResources()
+	var candidates []*resources.Resource
+
+	for source, list := range current {
+		previous := w.sources[source]
+		next := make(map[string]*resources.Resource)
+
+		_ = list.ForEach(func(res *resources.Resource) error {
+			if !w.filters.Matches(*res) {
+				return nil
+			}
+
+			key := resourceKey(res)
+			next[key] = snapshot(res)
+
+			if old, ok := previous[key]; !ok || !reflect.DeepEqual(old.Object.Object, res.Object.Object) {
+				toPush.Add(res)
+			}
+
+			return nil
+		})
+
+		for key, old := range previous {
+			if _, ok := next[key]; !ok {
+				candidates = append(candidates, old)
+			}
+		}
+
+		if len(next) == 0 {
+			delete(w.sources, source)
+		} else {
+			w.sources[source] = next
+		}
+	}
+
+	// Resources moved from one source to another are not removed.
+	removed := resources.NewResources()
+	for _, res := range candidates {
+		if !w.isTracked(res) {
+			removed.Add(res)
+		}
+	}
+
+	if toPush.Len() != 0 {
+		w.push(ctx, toPush)
+	}
+
+	if removed.Len() != 0 {
+		w.delete(ctx, removed)
+	}
+}
+
+func (w *pushWatcher) push(ctx context.Context, list *resources.Resources) {
+	req := w.request
+	req.Resources = list
+	req.NoPushFailureLog = true
+	req.OnPush = func(res *resources.Resource, action remote.PushAction) {
+		w.printResource(cmdio.Success, res, string(action))
+	}
+
+	var operation *journal.Operation
+	if w.newOperation != nil {
+		operation = w.newOperation("push")
+		req.Recorder = operation
+	}
+
+	summary, err := w.pusher.Push(ctx, req)
+
+	if operation != nil {
+		w.saveOperation(operation)
+	}
+
+	for _, failure := range summary.Failures() {
+		if failure.Resource == nil {
+			w.print(cmdio.Error, "%s", failure.Error)
+			continue
+		}
+
+		w.printResource(cmdio.Error, failure.Resource, "failed: "+failure.Error.Error())
+	}
+
+	if err != nil && summary.FailedCount() == 0 {
+		w.print(cmdio.Error, "%s", err)
+	}
+
+	w.untrack(summary)
+}
+
+func (w *pushWatcher) delete(ctx context.Context, list *resources.Resources) {
+	if w.deleter == nil {
+		_ = list.ForEach(func(res *resources.Resource) error {
+			w.printResource(cmdio.Warning, res, "removed locally, kept in Grafana")
+			return nil
+		})
+		return
+	}
+
+	req := remote.DeleteRequest{
+		Resources:      list,
+		MaxConcurrency: w.request.MaxConcurrency,
+		StopOnError:    w.request.StopOnError,
+		DryRun:         w.request.DryRun,
+	}
+
+	var operation *journal.Operation
+	if w.newOperation != nil {
+		operation = w.newOperation("delete")
+		req.Recorder = operation
+	}
+
+	summary, err := w.deleter.Delete(ctx, req)
+
+	if operation != nil {
+		w.saveOperation(operation)
+	}
+	if err != nil && summary.FailedCount() == 0 {
+		w.print(cmdio.Error, "%s", err)
+		return
+	}
+
+	failed := make(map[string]error)
+	for _, failure := range summary.Failures() {
+		if failure.Resource != nil {
+			failed[resourceKey(failure.Resource)] = failure.Error
+		}
+	}
+
+	_ = list.ForEach(func(res *resources.Resource) error {
+		if err, ok := failed[resourceKey(res)]; ok {
+			w.printResource(cmdio.Error, res, "failed to delete: "+err.Error())
+			return nil
+		}
+
+		w.printResource(cmdio.Success, res, "deleted")
+		return nil
+	})
+}
+
+func (w *pushWatcher) isTracked(res *resources.Resource) bool {
+	for _, known := range w.sources {
+		if _, ok := known[resourceKey(res)]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sourcesFor returns the sources affected by a change to the given file.
+func (w *pushWatcher) sourcesFor(file string) []string {
+	file = filepath.Clean(file)
+
+	var sources []string
+
+	// The file might be a removed directory: every source it contained is affected.
+	for source := range w.sources {
+		if slices.Contains(w.overlays, source) {
+			continue
+		}
+
+		if source == file || strings.HasPrefix(source, file+string(filepath.Separator)) {
+			sources = append(sources, source)
+		}
+	}
+
+	if info, err := os.Stat(file); err == nil && info.IsDir() {
+		return sources
+	}
+
+	switch strings.TrimPrefix(filepath.Ext(file), ".") {
+	case "json", "yaml", "yml":
+		sources = append(sources, file)
+	case string(format.CUE):
+		// CUE packages are evaluated as a whole.
+		sources = append(sources, filepath.Dir(file))
+	}
+
+	slices.Sort(sources)
+
+	return slices.Compact(sources)
+}
+
+// read reads the resources currently defined by a source.
+// Sources that don't exist anymore don't define any resource.
+func (w *pushWatcher) read(ctx context.Context, source string) (*resources.Resources, error) {
+	dst := resources.NewResources()
+
+	info, err := os.Stat(source)
+	if errors.Is(err, fs.ErrNotExist) {
+		return dst, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		cueFiles, err := filepath.Glob(filepath.Join(source, "*."+string(format.CUE)))
+		if err != nil || len(cueFiles) == 0 {
+			return dst, err
+		}
+
+		if err := w.reader.ReadCUE(ctx, dst, source); err != nil {
+			return nil, err
+		}
+
+		return dst, nil
+	}
+
+	object := &resources.Resource{}
+	if err := w.reader.ReadFile(ctx, object, source); err != nil {
+		return nil, err
+	}
+
+	dst.Add(object)
+
+	return dst, nil
+}
+
+func (w *pushWatcher) printResource(
+	printer func(io.Writer, string, ...any), res *resources.Resource, message string,
+) {
+	if w.request.DryRun {
+		message += " (dry-run)"
+	}
+
+	w.print(printer, "%s/%s %s", res.Kind(), res.Name(), message)
+}
+
+func (w *pushWatcher) print(printer func(io.Writer, string, ...any), message string, args ...any) {
+	w.outputMu.Lock()
+	defer w.outputMu.Unlock()
+
+	printLiveLog(w.output, printer, message, args...)
+}
+
+func (w *pushWatcher) saveOperation(op *journal.Operation) {
+	w.outputMu.Lock()
+	defer w.outputMu.Unlock()
+
+	saveOperation(w.output, op)
+}
+
+// printLiveLog prints a timestamped line, for commands reporting changes as they happen.
+func printLiveLog(output io.Writer, printer func(io.Writer, string, ...any), message string, args ...any) {
+	printer(output, "%s %s", time.Now().Format(time.TimeOnly), fmt.Sprintf(message, args...))
+}
+
+// sourceOf returns the source a resource was read from.
+func sourceOf(res *resources.Resource) string {
+	switch {
+	case res.Source.Overlay != "":
+		return res.Source.Overlay
+	case res.SourceFormat() == format.CUE:
+		return filepath.Dir(res.SourcePath())
+	default:
+		return filepath.Clean(res.SourcePath())
+	}
+}
+
+// resourceKey identifies a resource regardless of its namespace,
+// which is overridden when pushing.
+func resourceKey(res *resources.Resource) string {
+	return res.GroupVersionKind().String() + "/" + res.Name()
+}
+
+func snapshot(res *resources.Resource) *resources.Resource {
+	return resources.MustFromObject(res.Object.DeepCopy().Object, res.Source)
+}
@@ -0,0 +1,158 @@
+package resources
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources/lint"
+)
+
+const (
+	sarifFormat  = "sarif"
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// sarifLog is a minimal Static Analysis Results Interchange Format (SARIF)
+// document, as consumed by code scanning tools.
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+func newSARIFLog(linter *lint.Linter, failures []validationFailure) sarifLog {
+	rules := make([]sarifRule, 0, len(linter.Rules()))
+	for _, rule := range linter.Rules() {
+		rules = append(rules, sarifRule{
+			ID:                   rule.ID(),
+			ShortDescription:     sarifMessage{Text: rule.Description()},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(string(linter.Severity(rule)))},
+		})
+	}
+
+	results := make([]sarifResult, 0, len(failures))
+	for _, failure := range failures {
+		result := sarifResult{
+			RuleID:  failure.Rule,
+			Level:   sarifLevel(failure.Severity),
+			Message: sarifMessage{Text: failure.Error},
+		}
+
+		if failure.File != "" {
+			location := sarifLocation{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(failure.File)},
+				},
+			}
+			if failure.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: failure.Line}
+			}
+
+			result.Locations = []sarifLocation{location}
+		}
+
+		results = append(results, result)
+	}
+
+	return sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name:           "grafanactl",
+						InformationURI: "https://github.com/grafana/grafanactl",
+						Rules:          rules,
+					},
+				},
+				Results: results,
+			},
+		},
+	}
+}
+
+func sarifLevel(severity string) string {
+	switch lint.Severity(severity) {
+	case lint.SeverityError:
+		return "error"
+	case lint.SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+type sarifCodec struct{}
+
+func (c *sarifCodec) Format() format.Format {
+	return sarifFormat
+}
+
+func (c *sarifCodec) Encode(output io.Writer, input any) error {
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(input)
+}
+
+func (c *sarifCodec) Decode(io.Reader, any) error {
+	return errors.New("codec does not support decoding")
+}
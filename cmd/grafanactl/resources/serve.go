@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"log/slog"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"slices"
+	"strings"
 
 	"github.com/grafana/grafana-app-sdk/logging"
 	cmdconfig "github.com/grafana/grafanactl/cmd/grafanactl/config"
@@ -18,6 +20,8 @@ import (
 	"github.com/grafana/grafanactl/internal/logs"
 	"github.com/grafana/grafanactl/internal/resources"
 	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/grafana/grafanactl/internal/resources/overlay"
+	"github.com/grafana/grafanactl/internal/secrets"
 	"github.com/grafana/grafanactl/internal/server"
 	"github.com/grafana/grafanactl/internal/server/livereload"
 	"github.com/grafana/grafanactl/internal/server/watch"
@@ -33,6 +37,8 @@ type serveOpts struct {
 	Script        string
 	ScriptFormat  string
 	MaxConcurrent int
+	CUETags       []string
+	Template      templateOpts
 }
 
 func (opts *serveOpts) setup(flags *pflag.FlagSet) {
@@ -43,6 +49,8 @@ func (opts *serveOpts) setup(flags *pflag.FlagSet) {
 	flags.StringVarP(&opts.Script, "script", "S", "", "Script to execute to generate a resource")
 	flags.StringVarP(&opts.ScriptFormat, "script-format", "f", "json", "Format of the data returned by the script")
 	flags.IntVar(&opts.MaxConcurrent, "max-concurrent", 10, "Maximum number of concurrent operations")
+	bindCUETagsFlag(flags, &opts.CUETags)
+	opts.Template.setup(flags)
 }
 
 func (opts *serveOpts) watchTargets(args []string) []string {
@@ -58,7 +66,7 @@ func (opts *serveOpts) Validate() error {
 		return errors.New("max-concurrent must be greater than zero")
 	}
 
-	return nil
+	return opts.Template.Validate()
 }
 
 func serveCmd(configOpts *cmdconfig.Options) *cobra.Command {
@@ -77,6 +85,10 @@ While resources are loaded from disk, the server will use the Grafana instance
 described in the current context to access some data (example: to run queries
 when previewing dashboards).
 
+Directories containing an overlay (described by a '` + overlay.FileName + `' file) are rendered
+in memory. Since overlays can reference resources from other directories, use --watch to also
+watch these directories for changes.
+
 Note on NFS/SMB and watch mode: fsnotify requires support from underlying
 OS to work. The current NFS and SMB protocols does not provide network level
 support for file notifications.
@@ -85,6 +97,9 @@ support for file notifications.
 	# Serve resources from a directory:
 	grafanactl resources serve ./resources
 
+	# Serve resources rendered as templates, as they would be pushed:
+	grafanactl resources serve ./resources --values ./values/prod.yaml
+
 	# Serve resources from a directory but don't watch for changes:
 	grafanactl resources serve ./resources --no-watch
 
@@ -102,16 +117,32 @@ support for file notifications.
 				return err
 			}
 
+			readProcs, err := readProcessors(cfg.GetCurrentContext())
+			if err != nil {
+				return err
+			}
+
 			logger := logging.FromContext(cmd.Context())
 			parsedResources := resources.NewResources()
+			tmpl, err := opts.Template.template()
+			if err != nil {
+				return err
+			}
+
 			reader := local.FSReader{
-				Decoders:           format.Codecs(),
+				Decoders:           decoders(opts.CUETags),
+				Template:           tmpl,
 				StopOnError:        false,
 				MaxConcurrentReads: opts.MaxConcurrent,
+				Decrypter:          &secrets.Decrypter{},
+				Processors:         readProcs,
 			}
 
+			renderer := overlay.Renderer{Reader: &reader}
+			overlays, plainPaths := splitOverlayPaths(args)
+
 			if len(args) != 0 {
-				if err := reader.Read(cmd.Context(), parsedResources, resources.Filters{}, args); err != nil {
+				if err := renderer.Read(cmd.Context(), parsedResources, resources.Filters{}, args); err != nil {
 					return err
 				}
 			}
@@ -147,6 +178,25 @@ support for file notifications.
 
 				// By default, react to changes by parsing changed files
 				onInputChange := func(file string) {
+					// Any file referenced by an overlay could have changed: render them again.
+					if len(overlays) != 0 {
+						if err := renderer.Read(cmd.Context(), parsedResources, resources.Filters{}, overlays); err != nil {
+							logger.Warn("Could not render overlays", slog.String("file", file), logs.Err(err))
+						}
+
+						if !isWithinAny(file, plainPaths) {
+							return
+						}
+					}
+
+					// CUE packages are evaluated as a whole.
+					if filepath.Ext(file) == "."+string(format.CUE) {
+						if err := reader.ReadCUE(cmd.Context(), parsedResources, filepath.Dir(file)); err != nil {
+							logger.Warn("Could not evaluate CUE package", slog.String("file", file), logs.Err(err))
+						}
+						return
+					}
+
 					object := &resources.Resource{}
 					if err = reader.ReadFile(cmd.Context(), object, file); err != nil {
 						logger.Warn("Could not parse file", slog.String("file", file), logs.Err(err))
@@ -197,6 +247,33 @@ support for file notifications.
 	return cmd
 }
 
+// splitOverlayPaths separates paths pointing to overlays from the other ones.
+func splitOverlayPaths(paths []string) ([]string, []string) {
+	var overlays, plain []string
+
+	for _, path := range paths {
+		if _, ok := overlay.Find(path); ok {
+			overlays = append(overlays, path)
+		} else {
+			plain = append(plain, path)
+		}
+	}
+
+	return overlays, plain
+}
+
+// isWithinAny returns true if the given file is located within one of the given directories.
+func isWithinAny(file string, dirs []string) bool {
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(dir, file)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func executeWatchScript(ctx context.Context, command string) ([]byte, error) {
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
@@ -0,0 +1,128 @@
+package resources
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	cmdconfig "github.com/grafana/grafanactl/cmd/grafanactl/config"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/discovery"
+	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/grafana/grafanactl/internal/resources/overlay"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// sourcesRequest describes the local resources to modify in place.
+type sourcesRequest struct {
+	Paths         []string
+	MaxConcurrent int
+	StopOnError   bool
+}
+
+// readSources reads the resources of the given paths, as they are on disk:
+// templates and overlays are not rendered, so that resources can be written
+// back to their files.
+// Resources are returned sorted by path.
+func readSources(
+	ctx context.Context, configOpts *cmdconfig.Options, req sourcesRequest, args []string,
+) ([]*resources.Resource, error) {
+	for _, path := range req.Paths {
+		if file, ok := overlay.Find(path); ok {
+			return nil, fmt.Errorf("%s is an overlay: modify the resources of its base instead", file)
+		}
+	}
+
+	var filters resources.Filters
+
+	// Selectors are resolved using the discovery registry:
+	// Grafana is only needed when they are given.
+	if len(args) != 0 {
+		cfg, err := configOpts.LoadRESTConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		sels, err := resources.ParseSelectors(args)
+		if err != nil {
+			return nil, err
+		}
+
+		reg, err := discovery.NewDefaultRegistry(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		filters, err = reg.MakeFilters(discovery.MakeFiltersOptions{
+			Selectors: sels,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	reader := local.FSReader{
+		Decoders:           decoders(nil),
+		MaxConcurrentReads: req.MaxConcurrent,
+		StopOnError:        req.StopOnError,
+	}
+
+	res := resources.NewResources()
+	if err := reader.Read(ctx, res, filters, req.Paths); err != nil {
+		return nil, err
+	}
+
+	list := res.AsList()
+	slices.SortFunc(list, func(a, b *resources.Resource) int {
+		return strings.Compare(a.SourcePath(), b.SourcePath())
+	})
+
+	return list, nil
+}
+
+// rewriteSource writes a modified resource back to the file it was read from,
+// or prints the diff of the file in dry-run mode.
+// It returns true if the file was, or would be, modified.
+func rewriteSource(output io.Writer, res *resources.Resource, dryRun bool) (bool, error) {
+	original, updated, err := local.UpdateSource(res, &res.Object)
+	if err != nil {
+		return false, err
+	}
+
+	if bytes.Equal(original, updated) {
+		return false, nil
+	}
+
+	if !dryRun {
+		return true, os.WriteFile(res.SourcePath(), updated, 0644)
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        diffLines(original),
+		B:        diffLines(updated),
+		FromFile: res.SourcePath(),
+		ToFile:   res.SourcePath(),
+		Context:  3,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	fmt.Fprint(output, diff)
+
+	return true, nil
+}
+
+// diffLines splits a file into lines, each one keeping its line break.
+func diffLines(content []byte) []string {
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
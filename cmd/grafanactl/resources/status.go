@@ -0,0 +1,271 @@
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	cmdconfig "github.com/grafana/grafanactl/cmd/grafanactl/config"
+	cmdio "github.com/grafana/grafanactl/cmd/grafanactl/io"
+	"github.com/grafana/grafanactl/internal/config"
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/discovery"
+	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/grafana/grafanactl/internal/resources/overlay"
+	"github.com/grafana/grafanactl/internal/resources/remote"
+	"github.com/grafana/grafanactl/internal/secrets"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type statusOpts struct {
+	IO            cmdio.Options
+	Paths         []string
+	MaxConcurrent int
+	CUETags       []string
+	Template      templateOpts
+	Processors    processorOpts
+}
+
+func (opts *statusOpts) setup(flags *pflag.FlagSet) {
+	opts.IO.RegisterCustomCodec("text", &statusTabCodec{wide: false})
+	opts.IO.RegisterCustomCodec("wide", &statusTabCodec{wide: true})
+	opts.IO.DefaultFormat("text")
+	opts.IO.BindFlags(flags)
+
+	flags.StringSliceVarP(&opts.Paths, "path", "p", []string{defaultResourcesPath}, "Paths on disk from which to read the resources to compare")
+	flags.IntVar(&opts.MaxConcurrent, "max-concurrent", 10, "Maximum number of concurrent operations")
+	bindCUETagsFlag(flags, &opts.CUETags)
+	opts.Template.setup(flags)
+	opts.Processors.setup(flags)
+}
+
+func (opts *statusOpts) Validate() error {
+	if err := opts.IO.Validate(); err != nil {
+		return err
+	}
+
+	if len(opts.Paths) == 0 {
+		return errors.New("at least one path is required")
+	}
+
+	if opts.MaxConcurrent < 1 {
+		return errors.New("max-concurrent must be greater than zero")
+	}
+
+	return opts.Template.Validate()
+}
+
+func statusCmd(configOpts *cmdconfig.Options) *cobra.Command {
+	opts := &statusOpts{}
+
+	cmd := &cobra.Command{
+		Use:               "status [RESOURCE_SELECTOR]...",
+		Args:              cobra.ArbitraryArgs,
+		ValidArgsFunction: completeSelectors(configOpts),
+		Short:             "Show how local resources compare to the resources in Grafana",
+		Long: `Show how local resources compare to the resources in Grafana.
+
+Resources are read from the local files as they would be pushed, and compared to the
+resources in Grafana. The state of every resource is one of:
+
+  in sync            the local and remote resources have the same content
+  modified locally   the local resource changed since it was last pushed
+  modified remotely  the resource changed in Grafana since it was last pushed
+  conflict           both resources changed since the last push, or they differ
+                     and the resource was never pushed by grafanactl
+  differs            the resources differ, and the side that changed is unknown:
+                     resources of the legacy HTTP API hold no checksum
+  encrypted          the local resource holds values that could not be decrypted,
+                     for lack of age identities: it can't be compared
+  missing remotely   the local resource doesn't exist in Grafana
+  only remote        the resource exists in Grafana, but not locally
+
+Changes are detected with the checksum recorded in Grafana when resources are pushed.
+The checksum is computed once processors ran: resources pushed with processor flags
+(--set-label, --map-datasource, ...) must be compared with the same flags.
+Resources only present in Grafana are listed for the kinds of the local resources,
+or for the kinds designated by the selectors.`,
+		Example: `
+	# Status of every local resource:
+
+	grafanactl resources status
+
+	# Status of dashboards only, including dashboards that only exist in Grafana:
+
+	grafanactl resources status dashboards
+
+	# Status as JSON:
+
+	grafanactl resources status -o json
+
+	# Status of resources pushed with a label:
+
+	grafanactl resources status --set-label env=prod`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+
+			codec, err := opts.IO.Codec()
+			if err != nil {
+				return err
+			}
+
+			fullConfig, err := configOpts.LoadConfig(ctx)
+			if err != nil {
+				return err
+			}
+
+			currentContext := fullConfig.GetCurrentContext()
+			cfg := currentContext.ToRESTConfig(ctx)
+
+			readProcs, err := readProcessors(currentContext)
+			if err != nil {
+				return err
+			}
+
+			pushProcs, err := contextProcessors(currentContext, config.StagePrePush)
+			if err != nil {
+				return err
+			}
+
+			// Processors run in the same order as when pushing.
+			flagProcs, err := opts.Processors.processors()
+			if err != nil {
+				return err
+			}
+			pushProcs = append(pushProcs, flagProcs...)
+
+			sels, err := resources.ParseSelectors(args)
+			if err != nil {
+				return err
+			}
+
+			reg, err := discovery.NewDefaultRegistry(ctx, cfg)
+			if err != nil {
+				return err
+			}
+
+			filters, err := reg.MakeFilters(discovery.MakeFiltersOptions{
+				Selectors: sels,
+			})
+			if err != nil {
+				return err
+			}
+
+			tmpl, err := opts.Template.template()
+			if err != nil {
+				return err
+			}
+
+			// Nothing is sent to Grafana: status can be checked without age
+			// identities, resources left encrypted are then reported as such.
+			reader := local.FSReader{
+				Decoders:           decoders(opts.CUETags),
+				Template:           tmpl,
+				MaxConcurrentReads: opts.MaxConcurrent,
+				Decrypter:          &secrets.Decrypter{Optional: true},
+				Processors:         readProcs,
+				StopOnError:        true,
+			}
+
+			localResources := resources.NewResources()
+
+			// Resources are compared as they would be pushed.
+			renderer := overlay.Renderer{Reader: &reader}
+			if err := renderer.Read(ctx, localResources, filters, opts.Paths); err != nil {
+				return err
+			}
+
+			for _, proc := range pushProcs {
+				if err := localResources.ForEach(proc.Process); err != nil {
+					return err
+				}
+			}
+
+			checker, err := remote.NewStatusChecker(ctx, cfg)
+			if err != nil {
+				return err
+			}
+
+			statuses, err := checker.Status(ctx, remote.StatusRequest{
+				Resources: localResources,
+				Filters:   filters,
+			})
+			if err != nil {
+				return err
+			}
+
+			items := make([]resourceStatus, 0, len(statuses))
+			for _, status := range statuses {
+				res := status.Resource()
+				items = append(items, resourceStatus{
+					APIVersion: res.APIVersion(),
+					Kind:       res.Kind(),
+					Name:       res.Name(),
+					Path:       res.SourcePath(),
+					Status:     string(status.State),
+				})
+			}
+
+			return codec.Encode(cmd.OutOrStdout(), items)
+		},
+	}
+
+	opts.setup(cmd.Flags())
+
+	return cmd
+}
+
+type resourceStatus struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Path       string `json:"path,omitempty"`
+	Status     string `json:"status"`
+}
+
+type statusTabCodec struct {
+	wide bool
+}
+
+func (c *statusTabCodec) Format() format.Format {
+	if c.wide {
+		return "wide"
+	}
+
+	return "text"
+}
+
+func (c *statusTabCodec) Encode(output io.Writer, input any) error {
+	items, ok := input.([]resourceStatus)
+	if !ok {
+		return fmt.Errorf("expected []resourceStatus, got %T", input)
+	}
+
+	out := tabwriter.NewWriter(output, 0, 4, 2, ' ', tabwriter.TabIndent|tabwriter.DiscardEmptyColumns)
+	if c.wide {
+		fmt.Fprintf(out, "KIND\tNAME\tSTATUS\tAPI VERSION\tPATH\n")
+	} else {
+		fmt.Fprintf(out, "KIND\tNAME\tSTATUS\n")
+	}
+
+	for _, item := range items {
+		if c.wide {
+			fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\n", item.Kind, item.Name, item.Status, item.APIVersion, item.Path)
+		} else {
+			fmt.Fprintf(out, "%s\t%s\t%s\n", item.Kind, item.Name, item.Status)
+		}
+	}
+
+	return out.Flush()
+}
+
+func (c *statusTabCodec) Decode(io.Reader, any) error {
+	return errors.New("tab codec does not support decoding")
+}
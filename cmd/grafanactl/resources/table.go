@@ -0,0 +1,279 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafanactl/internal/config"
+	"github.com/grafana/grafanactl/internal/logs"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/dynamic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// kindColumn is a column specific to a kind of resource, computed on the client side.
+type kindColumn struct {
+	definition metav1.TableColumnDefinition
+	value      func(obj *unstructured.Unstructured) any
+}
+
+// kindColumns lists the columns printed for known kinds of resources,
+// when the server doesn't declare any.
+// Columns registered with an empty version apply to every version of the kind.
+//
+//nolint:gochecknoglobals
+var kindColumns = map[schema.GroupVersionKind][]kindColumn{
+	{Group: "dashboard.grafana.app", Kind: "Dashboard"}: {
+		specColumn("TITLE", "The title of the dashboard.", "title"),
+		annotationColumn("FOLDER", "The folder containing the dashboard.", utils.AnnoKeyFolder),
+	},
+	{Group: "folder.grafana.app", Kind: "Folder"}: {
+		specColumn("TITLE", "The title of the folder.", "title"),
+		annotationColumn("PARENT", "The parent folder.", utils.AnnoKeyFolder),
+	},
+	{Group: "rules.alerting.grafana.app", Kind: "AlertRule"}: {
+		specColumn("TITLE", "The title of the alert rule.", "title"),
+		annotationColumn("FOLDER", "The folder containing the alert rule.", utils.AnnoKeyFolder),
+	},
+	{Group: "playlist.grafana.app", Kind: "Playlist"}: {
+		specColumn("TITLE", "The title of the playlist.", "title"),
+	},
+}
+
+func specColumn(name string, description string, field ...string) kindColumn {
+	return kindColumn{
+		definition: metav1.TableColumnDefinition{Name: name, Type: "string", Description: description},
+		value: func(obj *unstructured.Unstructured) any {
+			value, found, err := unstructured.NestedFieldNoCopy(obj.Object, append([]string{"spec"}, field...)...)
+			if err != nil || !found {
+				return "<none>"
+			}
+
+			return value
+		},
+	}
+}
+
+func annotationColumn(name string, description string, key string) kindColumn {
+	return kindColumn{
+		definition: metav1.TableColumnDefinition{Name: name, Type: "string", Description: description},
+		value: func(obj *unstructured.Unstructured) any {
+			if value := obj.GetAnnotations()[key]; value != "" {
+				return value
+			}
+
+			return "<none>"
+		},
+	}
+}
+
+func columnsFor(gvk schema.GroupVersionKind) []kindColumn {
+	if columns, ok := kindColumns[gvk]; ok {
+		return columns
+	}
+
+	gvk.Version = ""
+
+	return kindColumns[gvk]
+}
+
+// tableLister lists resources in their Table representation.
+type tableLister interface {
+	List(ctx context.Context, desc resources.Descriptor) (*metav1.Table, error)
+}
+
+// fetchTables builds one table per kind of resource.
+//
+// The columns declared by the server are used when the server supports the
+// Table representation. Otherwise, the columns registered for the kind are used.
+func fetchTables(
+	ctx context.Context, cfg config.NamespacedRESTConfig, filters resources.Filters, items []unstructured.Unstructured,
+) []*metav1.Table {
+	client, err := dynamic.NewDefaultTableClient(cfg)
+	if err != nil {
+		logging.FromContext(ctx).Debug("Could not create table client", logs.Err(err))
+		return clientTables(items)
+	}
+
+	descriptors := make(map[schema.GroupKind]resources.Descriptor, len(filters))
+	for _, filter := range filters {
+		descriptors[filter.Descriptor.GroupVersionKind().GroupKind()] = filter.Descriptor
+	}
+
+	tables := make([]*metav1.Table, 0)
+	for _, group := range groupByKind(items) {
+		gvk := group[0].GroupVersionKind()
+
+		// Resources served by the legacy HTTP API have no server-side columns.
+		desc, ok := descriptors[gvk.GroupKind()]
+		if !ok || desc.IsLegacy() {
+			tables = append(tables, clientTable(group))
+			continue
+		}
+
+		table, err := serverTable(ctx, client, desc, group)
+		if err != nil {
+			logger := logging.FromContext(ctx).With(slog.String("kind", gvk.String()))
+			if errors.Is(err, dynamic.ErrTableNotSupported) {
+				logger.Debug("Server-side table printing not supported, using client-side columns")
+			} else {
+				logger.Debug("Could not get table from the server, using client-side columns", logs.Err(err))
+			}
+
+			table = clientTable(group)
+		}
+
+		tables = append(tables, table)
+	}
+
+	return tables
+}
+
+// serverTable gets the table declared by the server for the given resources,
+// which must all be of the same kind.
+func serverTable(
+	ctx context.Context, client tableLister, desc resources.Descriptor, items []unstructured.Unstructured,
+) (*metav1.Table, error) {
+	listed, err := client.List(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make(map[string]metav1.TableRow, len(listed.Rows))
+	for _, row := range listed.Rows {
+		var metadata metav1.PartialObjectMetadata
+		if err := json.Unmarshal(row.Object.Raw, &metadata); err != nil || metadata.Name == "" {
+			return nil, errors.New("table rows don't include the metadata of the resources")
+		}
+
+		rows[metadata.Name] = row
+	}
+
+	table := &metav1.Table{
+		ColumnDefinitions: append([]metav1.TableColumnDefinition{kindColumnDefinition()}, listed.ColumnDefinitions...),
+	}
+
+	for i := range items {
+		row, ok := rows[items[i].GetName()]
+		if !ok {
+			return nil, errors.New("resource missing from the table: " + items[i].GetName())
+		}
+
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells:  append([]any{items[i].GetKind()}, row.Cells...),
+			Object: runtime.RawExtension{Object: &items[i]},
+		})
+	}
+
+	return table, nil
+}
+
+// clientTables builds one table per kind of resource, using the columns registered for the kind.
+func clientTables(items []unstructured.Unstructured) []*metav1.Table {
+	tables := make([]*metav1.Table, 0)
+	for _, group := range groupByKind(items) {
+		tables = append(tables, clientTable(group))
+	}
+
+	return tables
+}
+
+// clientTable builds the table for the given resources, which must all be of the same kind.
+//
+// Read more about type & format here:
+// https://github.com/OAI/OpenAPI-Specification/blob/main/versions/2.0.md#data-types
+//
+// Priority is 0-based (from most important to least important)
+// and controls whether columns are omitted in (wide: false) tables.
+func clientTable(items []unstructured.Unstructured) *metav1.Table {
+	var columns []kindColumn
+	if len(items) != 0 {
+		columns = columnsFor(items[0].GroupVersionKind())
+	}
+
+	table := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			kindColumnDefinition(),
+			{
+				Name:        "NAME",
+				Type:        "string",
+				Format:      "name",
+				Priority:    0,
+				Description: "The name of the resource.",
+			},
+			{
+				Name:        "NAMESPACE",
+				Priority:    0,
+				Description: "The namespace of the resource.",
+			},
+		},
+	}
+
+	for _, column := range columns {
+		table.ColumnDefinitions = append(table.ColumnDefinitions, column.definition)
+	}
+
+	table.ColumnDefinitions = append(table.ColumnDefinitions, metav1.TableColumnDefinition{
+		Name:        "AGE",
+		Type:        "string",
+		Format:      "date-time",
+		Priority:    1,
+		Description: "The age of the resource.",
+	})
+
+	for i := range items {
+		r := &items[i]
+
+		cells := []any{r.GetKind(), r.GetName(), r.GetNamespace()}
+		for _, column := range columns {
+			cells = append(cells, column.value(r))
+		}
+		cells = append(cells, duration.HumanDuration(time.Since(r.GetCreationTimestamp().Time)))
+
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells:  cells,
+			Object: runtime.RawExtension{Object: r},
+		})
+	}
+
+	return table
+}
+
+func kindColumnDefinition() metav1.TableColumnDefinition {
+	return metav1.TableColumnDefinition{
+		Name:        "KIND",
+		Type:        "string",
+		Priority:    0,
+		Description: "The kind of the resource.",
+	}
+}
+
+// groupByKind groups resources by kind, in the order in which kinds first appear.
+func groupByKind(items []unstructured.Unstructured) [][]unstructured.Unstructured {
+	var groups [][]unstructured.Unstructured
+	indexes := make(map[schema.GroupKind]int)
+
+	for _, item := range items {
+		gk := item.GroupVersionKind().GroupKind()
+
+		index, ok := indexes[gk]
+		if !ok {
+			index = len(groups)
+			indexes[gk] = index
+			groups = append(groups, nil)
+		}
+
+		groups[index] = append(groups[index], item)
+	}
+
+	return groups
+}
@@ -0,0 +1,106 @@
+package resources
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/spf13/pflag"
+)
+
+// templateOpts configures the rendering of resource files as templates.
+type templateOpts struct {
+	Enabled     bool
+	Set         []string
+	ValuesFiles []string
+}
+
+func (opts *templateOpts) setup(flags *pflag.FlagSet) {
+	flags.BoolVar(&opts.Enabled, "template", opts.Enabled, "Render resource files as templates before decoding them: ${VAR} is replaced by environment variables and {{ .Values.x }} by template values")
+	flags.StringArrayVar(&opts.Set, "set", nil, "Template value, as key=value. Nested keys are separated by dots. Implies --template. Example: --set datasource.uid=prometheus-prod")
+	flags.StringArrayVar(&opts.ValuesFiles, "values", nil, "YAML file containing template values. Values from --set take precedence. Implies --template")
+}
+
+func (opts *templateOpts) Validate() error {
+	for _, value := range opts.Set {
+		key, _, found := strings.Cut(value, "=")
+		if !found || key == "" {
+			return fmt.Errorf("invalid template value '%s': expected key=value", value)
+		}
+	}
+
+	return nil
+}
+
+// template returns the template used to render resource files, or nil if
+// templating is disabled.
+func (opts *templateOpts) template() (*local.Template, error) {
+	if !opts.Enabled && len(opts.Set) == 0 && len(opts.ValuesFiles) == 0 {
+		return nil, nil
+	}
+
+	values := map[string]any{}
+
+	for _, file := range opts.ValuesFiles {
+		fileValues, err := readTemplateValues(file)
+		if err != nil {
+			return nil, err
+		}
+
+		mergeTemplateValues(values, fileValues)
+	}
+
+	for _, value := range opts.Set {
+		key, val, _ := strings.Cut(value, "=")
+		setTemplateValue(values, strings.Split(key, "."), val)
+	}
+
+	return &local.Template{Values: values}, nil
+}
+
+func readTemplateValues(file string) (map[string]any, error) {
+	handle, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer handle.Close()
+
+	values := map[string]any{}
+	if err := format.NewYAMLCodec().Decode(handle, &values); err != nil {
+		return nil, fmt.Errorf("could not parse template values from '%s': %w", file, err)
+	}
+
+	return values, nil
+}
+
+// mergeTemplateValues deeply merges src into dst.
+func mergeTemplateValues(dst map[string]any, src map[string]any) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+
+		if srcIsMap && dstIsMap {
+			mergeTemplateValues(dstMap, srcMap)
+			continue
+		}
+
+		dst[key] = value
+	}
+}
+
+func setTemplateValue(dst map[string]any, path []string, value string) {
+	if len(path) == 1 {
+		dst[path[0]] = value
+		return
+	}
+
+	child, ok := dst[path[0]].(map[string]any)
+	if !ok {
+		child = map[string]any{}
+		dst[path[0]] = child
+	}
+
+	setTemplateValue(child, path[1:], value)
+}
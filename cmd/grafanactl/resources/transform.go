@@ -0,0 +1,177 @@
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	cmdconfig "github.com/grafana/grafanactl/cmd/grafanactl/config"
+	cmdio "github.com/grafana/grafanactl/cmd/grafanactl/io"
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/process"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type transformOpts struct {
+	OnError       OnErrorMode
+	Paths         []string
+	Expr          string
+	DryRun        bool
+	MaxConcurrent int
+}
+
+func (opts *transformOpts) setup(flags *pflag.FlagSet) {
+	bindOnErrorFlag(flags, &opts.OnError)
+	flags.StringSliceVarP(&opts.Paths, "path", "p", []string{defaultResourcesPath}, "Paths on disk from which to read the resources to transform")
+	flags.StringVar(&opts.Expr, "expr", opts.Expr, "jq expression applied to every resource. It must output the transformed resource")
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun, "If set, the changes are printed as a diff instead of being written")
+	flags.IntVar(&opts.MaxConcurrent, "max-concurrent", 10, "Maximum number of concurrent operations")
+}
+
+func (opts *transformOpts) Validate() error {
+	if len(opts.Paths) == 0 {
+		return errors.New("at least one path is required")
+	}
+
+	if opts.Expr == "" {
+		return errors.New("--expr is required")
+	}
+
+	if opts.MaxConcurrent < 1 {
+		return errors.New("max-concurrent must be greater than zero")
+	}
+
+	return opts.OnError.Validate()
+}
+
+func transformCmd(configOpts *cmdconfig.Options) *cobra.Command {
+	opts := &transformOpts{}
+
+	cmd := &cobra.Command{
+		Use:               "transform [RESOURCE_SELECTOR]... --expr EXPRESSION",
+		Args:              cobra.ArbitraryArgs,
+		ValidArgsFunction: completeSelectors(configOpts),
+		Short:             "Transform local resources with a jq expression",
+		Long: `Transform local resources with a jq expression.
+
+The expression is evaluated for every resource read from the given paths, with
+the resource as input. It must output exactly one object: the transformed resource,
+which is written back to the file it was read from, in the same format.
+YAML files are patched in place, preserving their comments.
+
+Files are read as they are on disk: templates are not rendered, and resources
+evaluated from CUE packages can not be transformed.
+`,
+		Example: `
+	# Replace a datasource UID in every dashboard
+	grafanactl resources transform dashboards --expr '(.. | objects | select(.uid? == "old-uid") | .uid) = "new-uid"'
+
+	# Move dashboards to another folder
+	grafanactl resources transform -p ./dashboards --expr '.metadata.annotations["grafana.app/folder"] = "new-folder"'
+
+	# Preview the changes without writing them
+	grafanactl resources transform --dry-run --expr '.spec.title |= ascii_upcase' dashboards/foo
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+
+			transformer, err := process.NewJQTransformer(opts.Expr)
+			if err != nil {
+				return err
+			}
+
+			list, err := readSources(ctx, configOpts, sourcesRequest{
+				Paths:         opts.Paths,
+				MaxConcurrent: opts.MaxConcurrent,
+				StopOnError:   opts.OnError.StopOnError(),
+			}, args)
+			if err != nil {
+				return err
+			}
+
+			output := cmd.OutOrStdout()
+			changed := 0
+			failed := 0
+
+			for _, r := range list {
+				if r.SourceFormat() == format.CUE {
+					cmdio.Warning(output, "%s: resources evaluated from CUE packages can not be transformed", r.SourcePath())
+					continue
+				}
+
+				written, err := transformResource(output, transformer, r, opts.DryRun)
+				if err != nil {
+					if opts.OnError.StopOnError() {
+						return fmt.Errorf("%s: %w", r.SourcePath(), err)
+					}
+
+					cmdio.Error(output, "%s: %s", r.SourcePath(), err)
+					failed++
+					continue
+				}
+
+				if written {
+					changed++
+				}
+			}
+
+			verb := "transformed"
+			if opts.DryRun {
+				verb = "would be transformed"
+			}
+
+			printer := cmdio.Success
+			if failed != 0 {
+				printer = cmdio.Warning
+				if changed == 0 {
+					printer = cmdio.Error
+				}
+			}
+
+			printer(output, "%d file(s) %s, %d errors", changed, verb, failed)
+
+			if opts.OnError.FailOnErrors() && failed > 0 {
+				return fmt.Errorf("%d file(s) failed to transform", failed)
+			}
+
+			return nil
+		},
+	}
+
+	opts.setup(cmd.Flags())
+
+	return cmd
+}
+
+// transformResource transforms a resource and writes it back to its file,
+// or prints the diff in dry-run mode.
+// It returns true if the file was, or would be, modified.
+func transformResource(output io.Writer, transformer *process.JQTransformer, res *resources.Resource, dryRun bool) (bool, error) {
+	before := res.Object.DeepCopy()
+
+	if err := transformer.Process(res); err != nil {
+		return false, err
+	}
+
+	if reflect.DeepEqual(before.Object, res.Object.Object) {
+		return false, nil
+	}
+
+	written, err := rewriteSource(output, res, dryRun)
+	if err != nil || !written {
+		return false, err
+	}
+
+	if !dryRun {
+		cmdio.Success(output, "%s transformed", res.SourcePath())
+	}
+
+	return true, nil
+}
@@ -1,18 +1,28 @@
 package resources
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"slices"
+	"strings"
 	"text/tabwriter"
 
+	"github.com/grafana/grafana-app-sdk/logging"
 	cmdconfig "github.com/grafana/grafanactl/cmd/grafanactl/config"
 	cmdio "github.com/grafana/grafanactl/cmd/grafanactl/io"
+	"github.com/grafana/grafanactl/internal/config"
 	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/logs"
 	"github.com/grafana/grafanactl/internal/resources"
 	"github.com/grafana/grafanactl/internal/resources/discovery"
 	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/grafana/grafanactl/internal/resources/openapi"
+	"github.com/grafana/grafanactl/internal/resources/overlay"
 	"github.com/grafana/grafanactl/internal/resources/remote"
+	"github.com/grafana/grafanactl/internal/secrets"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -23,6 +33,13 @@ type validateOpts struct {
 	Paths         []string
 	MaxConcurrent int
 	OnError       OnErrorMode
+	CUETags       []string
+	Template      templateOpts
+	Processors    processorOpts
+
+	Offline        bool
+	SchemasDir     string
+	RefreshSchemas bool
 }
 
 func (opts *validateOpts) setup(flags *pflag.FlagSet) {
@@ -34,6 +51,12 @@ func (opts *validateOpts) setup(flags *pflag.FlagSet) {
 	flags.StringSliceVarP(&opts.Paths, "path", "p", []string{defaultResourcesPath}, "Paths on disk from which to read the resources.")
 	flags.IntVar(&opts.MaxConcurrent, "max-concurrent", 10, "Maximum number of concurrent operations")
 	bindOnErrorFlag(flags, &opts.OnError)
+	bindCUETagsFlag(flags, &opts.CUETags)
+	opts.Template.setup(flags)
+	opts.Processors.setup(flags)
+	flags.BoolVar(&opts.Offline, "offline", opts.Offline, "Validate resources against cached OpenAPI schemas instead of a dry-run push. Schemas are fetched from the server if they are not cached yet")
+	flags.StringVar(&opts.SchemasDir, "schemas-dir", opts.SchemasDir, "Directory holding the OpenAPI schemas used by --offline. Defaults to a cache directory specific to the server of the current context")
+	flags.BoolVar(&opts.RefreshSchemas, "refresh-schemas", opts.RefreshSchemas, "Fetch the OpenAPI schemas used by --offline from the server, even if they are already cached")
 }
 
 func (opts *validateOpts) Validate() error {
@@ -45,19 +68,102 @@ func (opts *validateOpts) Validate() error {
 		return errors.New("max-concurrent must be greater than zero")
 	}
 
+	if err := opts.Template.Validate(); err != nil {
+		return err
+	}
+
+	if !opts.Offline && (opts.SchemasDir != "" || opts.RefreshSchemas) {
+		return errors.New("--schemas-dir and --refresh-schemas require --offline")
+	}
+
 	return opts.OnError.Validate()
 }
 
+// schemaStore returns the store holding the OpenAPI schemas used for offline
+// validation, populating it from the server if needed.
+func (opts *validateOpts) schemaStore(ctx context.Context, configOpts *cmdconfig.Options) (openapi.Store, error) {
+	store := openapi.Store{Dir: opts.SchemasDir}
+
+	// Vendored schemas don't require any configuration.
+	if store.Dir != "" && store.Exists() && !opts.RefreshSchemas {
+		return store, nil
+	}
+
+	cfg, err := configOpts.LoadConfig(ctx)
+	if err != nil {
+		return store, err
+	}
+
+	if store.Dir == "" {
+		store.Dir = openapi.DefaultDir(cfg.GetCurrentContext().Grafana.Server)
+	}
+
+	if store.Exists() && !opts.RefreshSchemas {
+		return store, nil
+	}
+
+	logging.FromContext(ctx).Info("Fetching OpenAPI schemas", slog.String("dir", store.Dir))
+
+	if err := store.Fetch(ctx, cfg.GetCurrentContext().ToRESTConfig(ctx)); err != nil {
+		return store, fmt.Errorf("could not fetch OpenAPI schemas: %w", err)
+	}
+
+	return store, nil
+}
+
+// contextProcessors returns the read and pre-push processors declared in the current
+// context. Offline, with vendored schemas, no configuration is required: without one,
+// no processor is returned.
+func (opts *validateOpts) contextProcessors(
+	ctx context.Context, configOpts *cmdconfig.Options, output io.Writer,
+) ([]local.Processor, []remote.Processor, error) {
+	cfg, err := configOpts.LoadConfig(ctx)
+	if err != nil {
+		if opts.Offline && opts.SchemasDir != "" {
+			cmdio.Warning(output, "The processors of the current context are not applied: %s", err)
+			return nil, nil, nil
+		}
+
+		return nil, nil, err
+	}
+
+	readProcs, err := readProcessors(cfg.GetCurrentContext())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pushProcs, err := contextProcessors(cfg.GetCurrentContext(), config.StagePrePush)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return readProcs, pushProcs, nil
+}
+
 func validateCmd(configOpts *cmdconfig.Options) *cobra.Command {
 	opts := &validateOpts{}
 
 	cmd := &cobra.Command{
-		Use:   "validate [RESOURCE_SELECTOR]...",
-		Args:  cobra.ArbitraryArgs,
-		Short: "Validate resources",
+		Use:               "validate [RESOURCE_SELECTOR]...",
+		Args:              cobra.ArbitraryArgs,
+		ValidArgsFunction: completeSelectors(configOpts),
+		Short:             "Validate resources",
 		Long: `Validate resources.
 
-This command validates its inputs against a remote Grafana instance.
+By default, this command validates its inputs against a remote Grafana instance,
+by simulating a push of the resources. Only the first error of each resource is reported.
+Resources served by the legacy HTTP API (datasources, teams, ...) can't be validated
+this way: they are reported as not validated.
+
+With --offline, resources are validated against OpenAPI v3 schemas. Schemas are
+fetched from the server once and cached locally, or read from the directory given
+by --schemas-dir (allowing them to be vendored). Every schema violation is reported,
+with the JSON path of the invalid field and its location in the source file.
+Files encrypted with SOPS are decrypted in memory to be validated.
+
+Resources are validated as they would be pushed: the processors declared in the
+current context of the configuration and the processors given as flags (--set-label,
+--map-datasource, ...) are applied first.
 `,
 		Example: `
 	# Validate all resources in the default directory
@@ -74,6 +180,13 @@ This command validates its inputs against a remote Grafana instance.
 
 	# Displaying validation results as JSON
 	grafanactl resources validate -o json
+
+	# Validate resources against cached OpenAPI schemas
+	grafanactl resources validate --offline
+
+	# Vendor OpenAPI schemas in a directory, then validate resources against them
+	grafanactl resources validate --offline --refresh-schemas --schemas-dir ./schemas
+	grafanactl resources validate --offline --schemas-dir ./schemas
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
@@ -87,21 +200,57 @@ This command validates its inputs against a remote Grafana instance.
 				return err
 			}
 
-			cfg, err := configOpts.LoadRESTConfig(ctx)
+			readProcs, pushProcs, err := opts.contextProcessors(ctx, configOpts, cmd.ErrOrStderr())
 			if err != nil {
 				return err
 			}
 
-			sels, err := resources.ParseSelectors(args)
+			// Processors run in the same order as when pushing.
+			flagProcs, err := opts.Processors.processors()
 			if err != nil {
 				return err
 			}
+			pushProcs = append(pushProcs, flagProcs...)
 
-			reg, err := discovery.NewDefaultRegistry(ctx, cfg)
+			sels, err := resources.ParseSelectors(args)
 			if err != nil {
 				return err
 			}
 
+			var (
+				cfg       config.NamespacedRESTConfig
+				reg       *discovery.Registry
+				validator *openapi.Validator
+			)
+
+			if opts.Offline {
+				store, err := opts.schemaStore(ctx, configOpts)
+				if err != nil {
+					return err
+				}
+
+				validator, err = store.Validator()
+				if err != nil {
+					return err
+				}
+
+				// Resource selectors are resolved using the cached discovery information.
+				reg, err = discovery.NewRegistry(ctx, store)
+				if err != nil {
+					return err
+				}
+			} else {
+				cfg, err = configOpts.LoadRESTConfig(ctx)
+				if err != nil {
+					return err
+				}
+
+				reg, err = discovery.NewDefaultRegistry(ctx, cfg)
+				if err != nil {
+					return err
+				}
+			}
+
 			filters, err := reg.MakeFilters(discovery.MakeFiltersOptions{
 				Selectors: sels,
 			})
@@ -109,70 +258,77 @@ This command validates its inputs against a remote Grafana instance.
 				return err
 			}
 
+			tmpl, err := opts.Template.template()
+			if err != nil {
+				return err
+			}
+
 			reader := local.FSReader{
-				Decoders:           format.Codecs(),
+				Decoders:           decoders(opts.CUETags),
+				Template:           tmpl,
 				MaxConcurrentReads: opts.MaxConcurrent,
+				Decrypter:          &secrets.Decrypter{},
+				Processors:         readProcs,
 				StopOnError:        opts.OnError.StopOnError(),
 			}
 
+			// Offline, files encrypted with SOPS must still be decrypted: their values
+			// and metadata don't match the schemas. Values encrypted with age are
+			// strings, valid either way: they are left as-is without identities.
+			if opts.Offline {
+				reader.Decrypter.Optional = true
+			}
+
 			resourcesList := resources.NewResources()
 
-			if err := reader.Read(ctx, resourcesList, filters, opts.Paths); err != nil {
+			// Resources are validated as they would be pushed.
+			renderer := overlay.Renderer{Reader: &reader}
+			if err := renderer.Read(ctx, resourcesList, filters, opts.Paths); err != nil {
 				return err
 			}
 
-			pusher, err := remote.NewDefaultPusher(ctx, cfg)
-			if err != nil {
-				return err
+			for _, proc := range pushProcs {
+				if err := resourcesList.ForEach(proc.Process); err != nil {
+					return err
+				}
 			}
 
-			req := remote.PushRequest{
-				Resources:        resourcesList,
-				MaxConcurrency:   opts.MaxConcurrent,
-				StopOnError:      opts.OnError.StopOnError(),
-				DryRun:           true,
-				NoPushFailureLog: true,
-			}
+			var failures []validationFailure
+			if opts.Offline {
+				failures, err = validateOffline(ctx, validator, resourcesList)
+			} else {
+				// Dry-runs aren't supported by the legacy HTTP API: pushing
+				// these resources would validate nothing.
+				var notValidated []*resources.Resource
+				resourcesList, notValidated = withoutLegacyResources(resourcesList)
+				for _, res := range notValidated {
+					cmdio.Warning(cmd.ErrOrStderr(), "%s: %s/%s not validated: the legacy HTTP API doesn't support dry-runs",
+						res.SourcePath(), res.Kind(), res.Name())
+				}
 
-			summary, err := pusher.Push(ctx, req)
+				failures, err = validateOnline(ctx, cfg, resourcesList, opts)
+			}
 			if err != nil {
 				return err
 			}
 
-			if summary.FailedCount() == 0 && opts.IO.OutputFormat == "text" {
+			if len(failures) == 0 && opts.IO.OutputFormat == "text" {
 				cmdio.Success(cmd.OutOrStdout(), "No errors found.")
 				return nil
 			}
 
 			if opts.IO.OutputFormat == "text" {
-				if err := codec.Encode(cmd.OutOrStdout(), summary); err != nil {
+				if err := codec.Encode(cmd.OutOrStdout(), failures); err != nil {
 					return err
 				}
 			} else {
-				printableSummary := struct {
-					Failures []map[string]string `json:"failures" yaml:"failures"`
-				}{
-					Failures: make([]map[string]string, 0),
-				}
-
-				for _, failure := range summary.Failures() {
-					file := ""
-					if failure.Resource != nil {
-						file = failure.Resource.SourcePath()
-					}
-					printableSummary.Failures = append(printableSummary.Failures, map[string]string{
-						"file":  file,
-						"error": failure.Error.Error(),
-					})
-				}
-
-				if err := codec.Encode(cmd.OutOrStdout(), printableSummary); err != nil {
+				if err := codec.Encode(cmd.OutOrStdout(), printableFailures(failures)); err != nil {
 					return err
 				}
 			}
 
-			if opts.OnError.FailOnErrors() && summary.FailedCount() > 0 {
-				return fmt.Errorf("%d resource(s) failed to validate", summary.FailedCount())
+			if failedCount := countFailedResources(failures); opts.OnError.FailOnErrors() && failedCount > 0 {
+				return fmt.Errorf("%d resource(s) failed to validate", failedCount)
 			}
 
 			return nil
@@ -184,6 +340,174 @@ This command validates its inputs against a remote Grafana instance.
 	return cmd
 }
 
+// validationFailure describes a single validation error.
+type validationFailure struct {
+	// Resource is the invalid resource. May be nil for failures that are
+	// not associated with a specific resource.
+	Resource *resources.Resource
+	File     string
+	// Line is the 1-based line of the error in File. It is set to 0 when unknown.
+	Line int
+	// Path is the JSON path of the invalid field, if known.
+	Path string
+	// Rule and Severity are set for failures reported by linting rules.
+	Rule     string
+	Severity string
+	Error    string
+}
+
+func (failure validationFailure) location() string {
+	if failure.Line > 0 {
+		return fmt.Sprintf("%s:%d", failure.File, failure.Line)
+	}
+
+	return failure.File
+}
+
+func (failure validationFailure) printable() map[string]any {
+	printable := map[string]any{
+		"file":  failure.File,
+		"error": failure.Error,
+	}
+
+	if failure.Line > 0 {
+		printable["line"] = failure.Line
+	}
+
+	if failure.Path != "" {
+		printable["path"] = failure.Path
+	}
+
+	if failure.Rule != "" {
+		printable["rule"] = failure.Rule
+		printable["severity"] = failure.Severity
+	}
+
+	return printable
+}
+
+// printableFailures formats failures for the JSON and YAML codecs.
+func printableFailures(failures []validationFailure) any {
+	printable := struct {
+		Failures []map[string]any `json:"failures" yaml:"failures"`
+	}{
+		Failures: make([]map[string]any, 0, len(failures)),
+	}
+
+	for _, failure := range failures {
+		printable.Failures = append(printable.Failures, failure.printable())
+	}
+
+	return printable
+}
+
+func countFailedResources(failures []validationFailure) int {
+	count := 0
+	seen := make(map[*resources.Resource]struct{})
+
+	for _, failure := range failures {
+		if failure.Resource != nil {
+			if _, ok := seen[failure.Resource]; ok {
+				continue
+			}
+			seen[failure.Resource] = struct{}{}
+		}
+
+		count++
+	}
+
+	return count
+}
+
+// withoutLegacyResources separates the resources served by the legacy HTTP API from the others.
+func withoutLegacyResources(list *resources.Resources) (*resources.Resources, []*resources.Resource) {
+	kept := resources.NewResources()
+	var legacy []*resources.Resource
+
+	for _, res := range list.AsList() {
+		if res.Group() == resources.LegacyGroup {
+			legacy = append(legacy, res)
+			continue
+		}
+
+		kept.Add(res)
+	}
+
+	return kept, legacy
+}
+
+// validateOnline validates resources by simulating a push.
+func validateOnline(
+	ctx context.Context, cfg config.NamespacedRESTConfig, resourcesList *resources.Resources, opts *validateOpts,
+) ([]validationFailure, error) {
+	pusher, err := remote.NewDefaultPusher(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	req := remote.PushRequest{
+		Resources:        resourcesList,
+		MaxConcurrency:   opts.MaxConcurrent,
+		StopOnError:      opts.OnError.StopOnError(),
+		DryRun:           true,
+		NoPushFailureLog: true,
+	}
+
+	summary, err := pusher.Push(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	failures := make([]validationFailure, 0, summary.FailedCount())
+	for _, failure := range summary.Failures() {
+		file := ""
+		if failure.Resource != nil {
+			file = failure.Resource.SourcePath()
+		}
+
+		failures = append(failures, validationFailure{
+			Resource: failure.Resource,
+			File:     file,
+			Error:    failure.Error.Error(),
+		})
+	}
+
+	return failures, nil
+}
+
+// validateOffline validates resources against OpenAPI schemas.
+func validateOffline(
+	ctx context.Context, validator *openapi.Validator, resourcesList *resources.Resources,
+) ([]validationFailure, error) {
+	logger := logging.FromContext(ctx)
+
+	var failures []validationFailure
+
+	for _, res := range resourcesList.AsList() {
+		violations, err := validator.Validate(res)
+		if err != nil {
+			if errors.As(err, &openapi.SchemaNotFoundError{}) {
+				logger.Warn("Skipping resource without schema", slog.String("resource", string(res.Ref())), logs.Err(err))
+				continue
+			}
+
+			return nil, err
+		}
+
+		for _, violation := range violations {
+			failures = append(failures, validationFailure{
+				Resource: res,
+				File:     res.SourcePath(),
+				Line:     violation.Line,
+				Path:     violation.Path,
+				Error:    violation.Message,
+			})
+		}
+	}
+
+	return failures, nil
+}
+
 type validationTableCodec struct{}
 
 func (c *validationTableCodec) Format() format.Format {
@@ -192,17 +516,39 @@ func (c *validationTableCodec) Format() format.Format {
 
 func (c *validationTableCodec) Encode(output io.Writer, input any) error {
 	//nolint:forcetypeassert
-	summary := input.(*remote.OperationSummary)
+	failures := input.([]validationFailure)
+
+	withRules := slices.ContainsFunc(failures, func(failure validationFailure) bool {
+		return failure.Rule != ""
+	})
+	withPaths := slices.ContainsFunc(failures, func(failure validationFailure) bool {
+		return failure.Path != ""
+	})
 
 	tab := tabwriter.NewWriter(output, 0, 4, 2, ' ', tabwriter.TabIndent|tabwriter.DiscardEmptyColumns)
 
-	fmt.Fprintf(tab, "FILE\tERROR\n")
-	for _, failure := range summary.Failures() {
-		file := ""
-		if failure.Resource != nil {
-			file = failure.Resource.SourcePath()
+	header := []string{"FILE"}
+	if withRules {
+		header = append(header, "SEVERITY", "RULE")
+	}
+	if withPaths {
+		header = append(header, "PATH")
+	}
+	header = append(header, "ERROR")
+
+	fmt.Fprintln(tab, strings.Join(header, "\t"))
+
+	for _, failure := range failures {
+		row := []string{failure.location()}
+		if withRules {
+			row = append(row, failure.Severity, failure.Rule)
 		}
-		fmt.Fprintf(tab, "%s\t%s\n", file, failure.Error)
+		if withPaths {
+			row = append(row, failure.Path)
+		}
+		row = append(row, failure.Error)
+
+		fmt.Fprintln(tab, strings.Join(row, "\t"))
 	}
 
 	return tab.Flush()
@@ -9,6 +9,8 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/grafana/grafana-app-sdk/logging"
 	"github.com/grafana/grafanactl/cmd/grafanactl/config"
+	"github.com/grafana/grafanactl/cmd/grafanactl/history"
+	"github.com/grafana/grafanactl/cmd/grafanactl/plugin"
 	"github.com/grafana/grafanactl/cmd/grafanactl/resources"
 	"github.com/grafana/grafanactl/internal/logs"
 	"github.com/spf13/cobra"
@@ -58,7 +60,10 @@ func Command(version string) *cobra.Command {
 	rootCmd.SetIn(os.Stdin)
 
 	rootCmd.AddCommand(config.Command())
+	rootCmd.AddCommand(history.Command())
+	rootCmd.AddCommand(plugin.Command())
 	rootCmd.AddCommand(resources.Command())
+	rootCmd.AddCommand(history.UndoCommand())
 
 	rootCmd.PersistentFlags().BoolVar(&noColors, "no-color", noColors, "Disable color output")
 	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "Verbose mode. Multiple -v options increase the verbosity (maximum: 3).")
@@ -0,0 +1,101 @@
+package completion
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/grafana/grafanactl/internal/config"
+)
+
+// Cache is an on-disk cache of completion values, expiring after a given time.
+// Failing to read or write the cache is never an error: values are fetched again.
+type Cache struct {
+	// Dir is the directory in which values are cached.
+	Dir string
+	// Now returns the current time. If nil, time.Now is used.
+	Now func() time.Time
+}
+
+// DefaultDir returns the directory of the default completion cache, in the XDG cache directory.
+func DefaultDir() string {
+	return filepath.Join(xdg.CacheHome, config.StandardConfigFolder, "completion")
+}
+
+type entry struct {
+	Expires time.Time       `json:"expires"`
+	Value   json.RawMessage `json:"value"`
+}
+
+// Load returns the value cached under the given key if it didn't expire.
+// Otherwise, the value is fetched and cached for the given duration.
+func Load[T any](cache Cache, key string, ttl time.Duration, fetch func() (T, error)) (T, error) {
+	path := cache.path(key)
+
+	if raw, err := os.ReadFile(path); err == nil {
+		cached := entry{}
+		var value T
+
+		if json.Unmarshal(raw, &cached) == nil && cache.now().Before(cached.Expires) &&
+			json.Unmarshal(cached.Value, &value) == nil {
+			return value, nil
+		}
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return value, err
+	}
+
+	cache.store(path, ttl, value)
+
+	return value, nil
+}
+
+func (cache Cache) store(path string, ttl time.Duration, value any) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+
+	raw, err := json.Marshal(entry{Expires: cache.now().Add(ttl), Value: encoded})
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+
+	// Completions can run concurrently: entries are replaced atomically.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".entry-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(raw)
+	if closeErr := tmp.Close(); err != nil || closeErr != nil {
+		return
+	}
+
+	_ = os.Rename(tmp.Name(), path)
+}
+
+func (cache Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return filepath.Join(cache.Dir, hex.EncodeToString(sum[:])[:32]+".json")
+}
+
+func (cache Cache) now() time.Time {
+	if cache.Now != nil {
+		return cache.Now()
+	}
+
+	return time.Now()
+}
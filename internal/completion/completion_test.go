@@ -0,0 +1,121 @@
+package completion_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafanactl/internal/completion"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func testKinds() []completion.Kind {
+	return completion.Kinds(resources.Descriptors{
+		{
+			GroupVersion: schema.GroupVersion{Group: "folder.grafana.app", Version: "v1"},
+			Kind:         "Folder",
+			Singular:     "folder",
+			Plural:       "folders",
+		},
+		{
+			GroupVersion: schema.GroupVersion{Group: "dashboard.grafana.app", Version: "v1"},
+			Kind:         "Dashboard",
+			Singular:     "dashboard",
+			Plural:       "dashboards",
+		},
+	})
+}
+
+func TestKinds(t *testing.T) {
+	req := require.New(t)
+
+	kinds := testKinds()
+
+	req.Equal([]completion.Kind{
+		{
+			Kind:  "Dashboard",
+			Short: []string{"dashboard", "dashboards"},
+			Long:  []string{"dashboards.dashboard", "dashboards.v1.dashboard.grafana.app"},
+		},
+		{
+			Kind:  "Folder",
+			Short: []string{"folder", "folders"},
+			Long:  []string{"folders.folder", "folders.v1.folder.grafana.app"},
+		},
+	}, kinds)
+
+	req.True(kinds[0].Matches("Dashboard"))
+	req.True(kinds[0].Matches("dashboards"))
+	req.True(kinds[0].Matches("dashboards.v1.dashboard.grafana.app"))
+	req.False(kinds[0].Matches("folders"))
+}
+
+func TestComplete(t *testing.T) {
+	req := require.New(t)
+
+	kinds := testKinds()
+
+	req.Equal([]string{"dashboard", "dashboards", "folder", "folders"}, completion.Complete(kinds, ""))
+	req.Equal([]string{"folder", "folders"}, completion.Complete(kinds, "fo"))
+	req.Equal([]string{"dashboards.dashboard", "dashboards.v1.dashboard.grafana.app"}, completion.Complete(kinds, "dashboards."))
+	req.Equal([]string{"dashboards.v1.dashboard.grafana.app"}, completion.Complete(kinds, "dashboards.v"))
+	req.Empty(completion.Complete(kinds, "alert"))
+}
+
+func TestNames(t *testing.T) {
+	req := require.New(t)
+
+	names := []string{"foo", "bar", "baz", "bar"}
+
+	req.Equal([]string{"dashboards/bar", "dashboards/baz", "dashboards/foo"}, completion.Names("dashboards/", names))
+	req.Equal([]string{"dashboards/bar", "dashboards/baz"}, completion.Names("dashboards/b", names))
+	req.Equal([]string{"dashboards/foo,bar,baz"}, completion.Names("dashboards/foo,bar,b", names))
+	req.Equal([]string{"dashboards/foo,bar", "dashboards/foo,baz"}, completion.Names("dashboards/foo,b", names))
+	req.Nil(completion.Names("dashboards", names))
+}
+
+func TestLoad(t *testing.T) {
+	req := require.New(t)
+
+	now := time.Now()
+	cache := completion.Cache{Dir: t.TempDir(), Now: func() time.Time { return now }}
+
+	fetches := 0
+	fetch := func() ([]string, error) {
+		fetches++
+
+		return []string{"foo", "bar"}, nil
+	}
+
+	for range 2 {
+		values, err := completion.Load(cache, "key", time.Minute, fetch)
+		req.NoError(err)
+		req.Equal([]string{"foo", "bar"}, values)
+	}
+	req.Equal(1, fetches)
+
+	// Keys are cached separately.
+	_, err := completion.Load(cache, "other", time.Minute, fetch)
+	req.NoError(err)
+	req.Equal(2, fetches)
+
+	// Expired values are fetched again.
+	now = now.Add(2 * time.Minute)
+	_, err = completion.Load(cache, "key", time.Minute, fetch)
+	req.NoError(err)
+	req.Equal(3, fetches)
+
+	// Errors are not cached.
+	now = now.Add(2 * time.Minute)
+	_, err = completion.Load(cache, "key", time.Minute, func() ([]string, error) {
+		return nil, errors.New("unreachable")
+	})
+	req.ErrorContains(err, "unreachable")
+
+	values, err := completion.Load(cache, "key", time.Minute, fetch)
+	req.NoError(err)
+	req.Equal([]string{"foo", "bar"}, values)
+	req.Equal(4, fetches)
+}
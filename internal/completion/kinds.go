@@ -0,0 +1,73 @@
+package completion
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/grafana/grafanactl/internal/resources"
+)
+
+// Kind lists the forms in which a resource kind can be written in selectors.
+type Kind struct {
+	Kind string `json:"kind"`
+	// Short forms: singular and plural names.
+	Short []string `json:"short"`
+	// Long forms: plural name with the short group, and with the version and group.
+	Long []string `json:"long"`
+}
+
+// Kinds returns the forms of the given resource kinds, sorted by kind.
+func Kinds(descs resources.Descriptors) []Kind {
+	kinds := make([]Kind, 0, len(descs))
+
+	for _, desc := range descs {
+		shortGroup := strings.Split(desc.GroupVersion.Group, ".")[0]
+
+		kinds = append(kinds, Kind{
+			Kind:  desc.Kind,
+			Short: compact(desc.Singular, desc.Plural),
+			Long: []string{
+				desc.Plural + "." + shortGroup,
+				desc.Plural + "." + desc.GroupVersion.Version + "." + desc.GroupVersion.Group,
+			},
+		})
+	}
+
+	slices.SortFunc(kinds, func(a, b Kind) int {
+		return strings.Compare(a.Long[1], b.Long[1])
+	})
+
+	return kinds
+}
+
+// Matches returns true if the given selector designates the kind.
+func (k Kind) Matches(selector string) bool {
+	return strings.EqualFold(selector, k.Kind) || slices.Contains(k.Short, selector) || slices.Contains(k.Long, selector)
+}
+
+// Complete returns the forms of the kinds starting with the given prefix.
+// Long forms are only returned once a dot is typed, to keep suggestions short.
+func Complete(kinds []Kind, prefix string) []string {
+	var forms []string
+
+	for _, kind := range kinds {
+		candidates := kind.Short
+		if strings.Contains(prefix, ".") {
+			candidates = kind.Long
+		}
+
+		for _, form := range candidates {
+			if strings.HasPrefix(form, prefix) && !slices.Contains(forms, form) {
+				forms = append(forms, form)
+			}
+		}
+	}
+
+	return forms
+}
+
+func compact(values ...string) []string {
+	return slices.Compact(slices.DeleteFunc(values, func(value string) bool {
+		return value == ""
+	}))
+}
@@ -0,0 +1,33 @@
+package completion
+
+import (
+	"slices"
+	"strings"
+)
+
+// Names completes a selector with the names of the resources of its kind,
+// e.g. "dashboards/foo,b" with "bar" and "baz". Names already in the
+// selector are not suggested again.
+func Names(selector string, names []string) []string {
+	kind, typed, found := strings.Cut(selector, "/")
+	if !found {
+		return nil
+	}
+
+	previous := strings.Split(typed, ",")
+	prefix := previous[len(previous)-1]
+	previous = previous[:len(previous)-1]
+
+	var completions []string
+	for _, name := range names {
+		if !strings.HasPrefix(name, prefix) || slices.Contains(previous, name) {
+			continue
+		}
+
+		completions = append(completions, kind+"/"+strings.Join(append(slices.Clone(previous), name), ","))
+	}
+
+	slices.Sort(completions)
+
+	return slices.Compact(completions)
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ProcessorStage is the stage of a command at which a processor runs.
+type ProcessorStage string
+
+const (
+	// StageRead runs processors on resources as they are read from local files.
+	StageRead ProcessorStage = "read"
+	// StagePrePush runs processors on resources before they are pushed to Grafana.
+	StagePrePush ProcessorStage = "pre-push"
+	// StagePostPull runs processors on resources after they are pulled from Grafana.
+	StagePostPull ProcessorStage = "post-pull"
+)
+
+// ProcessorStages lists the stages at which processors can run.
+//
+//nolint:gochecknoglobals
+var ProcessorStages = []ProcessorStage{StageRead, StagePrePush, StagePostPull}
+
+// Processor declares a processor modifying resources at a given stage.
+// Exactly one action must be set.
+type Processor struct {
+	// Stage at which the processor runs: read, pre-push or post-pull.
+	// Required.
+	Stage ProcessorStage `json:"stage" yaml:"stage"`
+
+	// Kinds restricts the processor to resources of the given kinds.
+	// Optional: by default, all resources are processed.
+	Kinds []string `json:"kinds,omitempty" yaml:"kinds,omitempty"`
+
+	// SetLabels sets labels on resources.
+	SetLabels map[string]string `json:"set-labels,omitempty" yaml:"set-labels,omitempty"`
+
+	// RemoveLabels removes labels from resources.
+	RemoveLabels []string `json:"remove-labels,omitempty" yaml:"remove-labels,omitempty"`
+
+	// StripIDs removes the numeric database id from the spec of resources,
+	// as found in dashboards.
+	StripIDs bool `json:"strip-ids,omitempty" yaml:"strip-ids,omitempty"`
+
+	// DatasourceUIDs rewrites the UIDs of the datasources referenced by
+	// resources, from the keys of the mapping to its values.
+	DatasourceUIDs map[string]string `json:"datasource-uids,omitempty" yaml:"datasource-uids,omitempty"`
+
+	// Command runs an external command, given the resource as JSON on its
+	// standard input, and writing the processed resource as JSON on its
+	// standard output.
+	Command []string `json:"command,omitempty" yaml:"command,omitempty"`
+}
+
+// Validate checks that the processor is valid.
+// path is the location of the processor in the configuration file.
+func (processor Processor) Validate(path string) error {
+	if !slices.Contains(ProcessorStages, processor.Stage) {
+		stages := make([]string, 0, len(ProcessorStages))
+		for _, stage := range ProcessorStages {
+			stages = append(stages, string(stage))
+		}
+
+		return ValidationError{
+			Path:    path,
+			Message: fmt.Sprintf("invalid processor stage '%s'", processor.Stage),
+			Suggestions: []string{
+				"Use one of: " + strings.Join(stages, ", "),
+			},
+		}
+	}
+
+	actions := 0
+	for _, set := range []bool{
+		len(processor.SetLabels) != 0,
+		len(processor.RemoveLabels) != 0,
+		processor.StripIDs,
+		len(processor.DatasourceUIDs) != 0,
+		len(processor.Command) != 0,
+	} {
+		if set {
+			actions++
+		}
+	}
+
+	if actions != 1 {
+		return ValidationError{
+			Path:    path,
+			Message: fmt.Sprintf("a processor must declare exactly one action, found %d", actions),
+			Suggestions: []string{
+				"Use one of: set-labels, remove-labels, strip-ids, datasource-uids, command",
+			},
+		}
+	}
+
+	return nil
+}
+
+// ProcessorsFor returns the processors of the context running at the given stage.
+func (context *Context) ProcessorsFor(stage ProcessorStage) []Processor {
+	var processors []Processor
+
+	for _, processor := range context.Processors {
+		if processor.Stage == stage {
+			processors = append(processors, processor)
+		}
+	}
+
+	return processors
+}
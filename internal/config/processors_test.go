@@ -0,0 +1,47 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/grafana/grafanactl/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessor_Validate(t *testing.T) {
+	req := require.New(t)
+
+	req.NoError(config.Processor{Stage: config.StagePrePush, StripIDs: true}.Validate("$"))
+
+	err := config.Processor{Stage: "later", StripIDs: true}.Validate("$")
+	req.ErrorContains(err, "invalid processor stage 'later'")
+
+	err = config.Processor{Stage: config.StageRead}.Validate("$")
+	req.ErrorContains(err, "exactly one action, found 0")
+
+	err = config.Processor{
+		Stage:     config.StagePostPull,
+		StripIDs:  true,
+		SetLabels: map[string]string{"env": "prod"},
+	}.Validate("$.contexts.'prod'.processors[0]")
+	validationErr := config.ValidationError{}
+	req.ErrorAs(err, &validationErr)
+	req.Equal("$.contexts.'prod'.processors[0]", validationErr.Path)
+}
+
+func TestContext_ProcessorsFor(t *testing.T) {
+	req := require.New(t)
+
+	context := config.Context{
+		Processors: []config.Processor{
+			{Stage: config.StagePrePush, StripIDs: true},
+			{Stage: config.StagePostPull, RemoveLabels: []string{"env"}},
+			{Stage: config.StagePrePush, Command: []string{"jq", "."}},
+		},
+	}
+
+	req.Equal([]config.Processor{
+		{Stage: config.StagePrePush, StripIDs: true},
+		{Stage: config.StagePrePush, Command: []string{"jq", "."}},
+	}, context.ProcessorsFor(config.StagePrePush))
+	req.Empty(context.ProcessorsFor(config.StageRead))
+}
@@ -53,6 +53,10 @@ type Context struct {
 	Name string `json:"-" yaml:"-"`
 
 	Grafana *GrafanaConfig `json:"grafana,omitempty" yaml:"grafana,omitempty"`
+
+	// Processors modify resources at given stages of the commands,
+	// e.g. to set labels on resources before pushing them.
+	Processors []Processor `json:"processors,omitempty" yaml:"processors,omitempty"`
 }
 
 func (context *Context) Validate() error {
@@ -63,6 +67,12 @@ func (context *Context) Validate() error {
 		}
 	}
 
+	for i, processor := range context.Processors {
+		if err := processor.Validate(fmt.Sprintf("$.contexts.'%s'.processors[%d]", context.Name, i)); err != nil {
+			return err
+		}
+	}
+
 	return context.Grafana.Validate(context.Name)
 }
 
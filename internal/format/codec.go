@@ -13,6 +13,7 @@ type Format string
 const (
 	JSON Format = "json"
 	YAML Format = "yaml"
+	CUE  Format = "cue"
 )
 
 // Codecs return a list of default codecs.
@@ -20,6 +21,7 @@ func Codecs() map[Format]Codec {
 	return map[Format]Codec{
 		JSON: NewJSONCodec(),
 		YAML: NewYAMLCodec(),
+		CUE:  NewCUECodec(),
 	}
 }
 
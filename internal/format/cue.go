@@ -0,0 +1,115 @@
+package format
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	cueformat "cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/load"
+)
+
+var _ Codec = (*CUECodec)(nil)
+
+// CUECodec is a Codec that evaluates CUE sources into concrete values.
+//
+// Unlike JSON and YAML, CUE sources are usually spread across several files
+// forming a package: Evaluate should be preferred over Decode to evaluate a
+// whole package directory at once.
+type CUECodec struct {
+	// Tags are injected into fields annotated with a `@tag()` attribute
+	// (same as `cue export -t key=value`).
+	Tags []string
+}
+
+// NewCUECodec returns a new CUECodec.
+func NewCUECodec(tags ...string) *CUECodec {
+	return &CUECodec{
+		Tags: tags,
+	}
+}
+
+func (c *CUECodec) Format() Format {
+	return CUE
+}
+
+func (c *CUECodec) Encode(dst io.Writer, value any) error {
+	encoded := cuecontext.New().Encode(value)
+	if err := encoded.Err(); err != nil {
+		return err
+	}
+
+	src, err := cueformat.Node(encoded.Syntax(cue.Final(), cue.Concrete(true)))
+	if err != nil {
+		return err
+	}
+
+	_, err = dst.Write(src)
+
+	return err
+}
+
+func (c *CUECodec) Decode(src io.Reader, value any) error {
+	instance, err := c.evaluate([]string{"-"}, &load.Config{Stdin: src})
+	if err != nil {
+		return err
+	}
+
+	return DecodeCUEValue(instance, value)
+}
+
+// Evaluate loads and evaluates the CUE sources found at the given path,
+// which can either be a package directory or a single .cue file.
+// The returned value is guaranteed to be concrete.
+func (c *CUECodec) Evaluate(path string) (cue.Value, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return cue.Value{}, err
+	}
+
+	if info.IsDir() {
+		return c.evaluate([]string{"."}, &load.Config{Dir: path})
+	}
+
+	return c.evaluate([]string{filepath.Base(path)}, &load.Config{Dir: filepath.Dir(path)})
+}
+
+func (c *CUECodec) evaluate(args []string, cfg *load.Config) (cue.Value, error) {
+	cfg.Tags = c.Tags
+
+	instances := load.Instances(args, cfg)
+	if len(instances) != 1 {
+		return cue.Value{}, errors.New("expected exactly one CUE instance")
+	}
+
+	if err := instances[0].Err; err != nil {
+		return cue.Value{}, err
+	}
+
+	value := cuecontext.New().BuildInstance(instances[0])
+	if err := value.Err(); err != nil {
+		return cue.Value{}, err
+	}
+
+	if err := value.Validate(cue.Concrete(true), cue.Final()); err != nil {
+		return cue.Value{}, err
+	}
+
+	return value, nil
+}
+
+// DecodeCUEValue decodes a concrete CUE value into the given Go value.
+func DecodeCUEValue(src cue.Value, value any) error {
+	// Going through JSON ensures that types implementing json.Unmarshaler
+	// (like unstructured.Unstructured) are decoded properly.
+	raw, err := src.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, value)
+}
@@ -0,0 +1,337 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+)
+
+// yamlIndent is the indentation used for the nodes added to a document,
+// consistent with YAMLCodec.
+const yamlIndent = 2
+
+// PatchYAML updates a YAML document so that it represents the given value.
+//
+// Unlike encoding the value again, only the fields that changed are updated:
+// comments, key order and formatting are preserved everywhere else, which
+// keeps the diffs of hand-written files minimal.
+// New keys are added at the end of their mapping, in alphabetical order.
+//
+// Only documents containing a single mapping can be patched.
+func PatchYAML(src []byte, value any) ([]byte, error) {
+	file, err := parser.ParseBytes(src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(file.Docs) != 1 || file.Docs[0].Body == nil {
+		return nil, errors.New("only YAML files containing a single document can be patched")
+	}
+
+	body, ok := file.Docs[0].Body.(*ast.MappingNode)
+	if !ok {
+		return nil, errors.New("only YAML documents containing a mapping can be patched")
+	}
+
+	target, err := toGeneric(value)
+	if err != nil {
+		return nil, err
+	}
+
+	object, ok := target.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected an object, got %T", target)
+	}
+
+	if err := patchMapping(body, object); err != nil {
+		return nil, err
+	}
+
+	out := file.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+
+	return []byte(out), nil
+}
+
+// patchMapping updates the values of a mapping, removes the keys that are
+// absent from the target and adds the new ones.
+func patchMapping(node *ast.MappingNode, target map[string]any) error {
+	seen := make(map[string]struct{}, len(node.Values))
+	values := make([]*ast.MappingValueNode, 0, len(node.Values))
+
+	for _, entry := range node.Values {
+		key := mappingKey(entry)
+
+		newValue, ok := target[key]
+		if !ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+
+		if err := patchMappingValue(entry, newValue); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+
+		values = append(values, entry)
+	}
+
+	node.Values = values
+
+	added := make([]string, 0)
+	for key := range target {
+		if _, ok := seen[key]; !ok {
+			added = append(added, key)
+		}
+	}
+
+	if len(added) == 0 {
+		return nil
+	}
+
+	slices.Sort(added)
+
+	additions := make(map[string]any, len(added))
+	for _, key := range added {
+		additions[key] = target[key]
+	}
+
+	newNode, err := valueToNode(additions)
+	if err != nil {
+		return err
+	}
+
+	newMapping, ok := newNode.(*ast.MappingNode)
+	if !ok {
+		return fmt.Errorf("expected a mapping node, got %s", newNode.Type())
+	}
+
+	// Mappings without values can't be used as a reference for the indentation.
+	if len(node.Values) == 0 {
+		newMapping.AddColumn(node.GetToken().Position.Column - newMapping.GetToken().Position.Column)
+		node.Values = newMapping.Values
+		node.IsFlowStyle = false
+
+		return nil
+	}
+
+	column := node.Values[0].Key.GetToken().Position.Column
+	for _, entry := range newMapping.Values {
+		entry.AddColumn(column - entry.Key.GetToken().Position.Column)
+		node.Values = append(node.Values, entry)
+	}
+
+	return nil
+}
+
+func patchMappingValue(entry *ast.MappingValueNode, target any) error {
+	changed, err := patchNode(entry.Value, target)
+	if err != nil || !changed {
+		return err
+	}
+
+	newNode, err := valueToNode(target)
+	if err != nil {
+		return err
+	}
+
+	// Block collections are indented under their key,
+	// unless the original value was already indented differently.
+	column := entry.Key.GetToken().Position.Column + yamlIndent
+	if isBlockCollection(newNode) && sameNodeType(entry.Value, newNode) {
+		column = entry.Value.GetToken().Position.Column
+	}
+
+	newNode.AddColumn(column - newNode.GetToken().Position.Column)
+	keepComment(entry.Value, newNode)
+	entry.Value = newNode
+
+	return nil
+}
+
+// patchNode updates a node in place when possible.
+// It returns true if the node must be replaced by a new one.
+func patchNode(node ast.Node, target any) (bool, error) {
+	if equal, err := nodeEquals(node, target); err == nil && equal {
+		return false, nil
+	}
+
+	switch typed := node.(type) {
+	case *ast.MappingNode:
+		if object, ok := target.(map[string]any); ok && !typed.IsFlowStyle {
+			return false, patchMapping(typed, object)
+		}
+	case *ast.SequenceNode:
+		if list, ok := target.([]any); ok && !typed.IsFlowStyle {
+			return false, patchSequence(typed, list)
+		}
+	}
+
+	return true, nil
+}
+
+// patchSequence updates the elements of a sequence by position,
+// removing the extra ones and adding the new ones at the end.
+func patchSequence(node *ast.SequenceNode, target []any) error {
+	if len(target) == 0 {
+		node.Values = nil
+		node.ValueHeadComments = nil
+		node.IsFlowStyle = true
+
+		return nil
+	}
+
+	common := min(len(node.Values), len(target))
+
+	for i := range common {
+		changed, err := patchNode(node.Values[i], target[i])
+		if err != nil {
+			return fmt.Errorf("[%d]: %w", i, err)
+		}
+
+		if !changed {
+			continue
+		}
+
+		newNode, err := valueToNode(target[i])
+		if err != nil {
+			return err
+		}
+
+		keepComment(node.Values[i], newNode)
+		if err := node.Replace(i, newNode); err != nil {
+			return err
+		}
+	}
+
+	node.Values = node.Values[:common]
+	if len(node.ValueHeadComments) > common {
+		node.ValueHeadComments = node.ValueHeadComments[:common]
+	}
+
+	if len(target) == common {
+		return nil
+	}
+
+	newNode, err := valueToNode(target[common:])
+	if err != nil {
+		return err
+	}
+
+	newSequence, ok := newNode.(*ast.SequenceNode)
+	if !ok {
+		return fmt.Errorf("expected a sequence node, got %s", newNode.Type())
+	}
+
+	if len(node.ValueHeadComments) < common {
+		node.ValueHeadComments = append(node.ValueHeadComments, make([]*ast.CommentGroupNode, common-len(node.ValueHeadComments))...)
+	}
+
+	node.Merge(newSequence)
+
+	return nil
+}
+
+// nodeEquals returns true if the node represents the given value.
+func nodeEquals(node ast.Node, target any) (bool, error) {
+	var current any
+	if err := yaml.NodeToValue(node, &current); err != nil {
+		return false, err
+	}
+
+	current, err := toGeneric(current)
+	if err != nil {
+		return false, err
+	}
+
+	return reflect.DeepEqual(current, target), nil
+}
+
+// toGeneric converts a value to its generic JSON representation,
+// so that values decoded from YAML can be compared to objects.
+// Integers are kept as such, to be encoded without a decimal point.
+func toGeneric(value any) (any, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var out any
+	if err := decoder.Decode(&out); err != nil {
+		return nil, err
+	}
+
+	return convertNumbers(out), nil
+}
+
+func convertNumbers(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, item := range typed {
+			typed[key] = convertNumbers(item)
+		}
+	case []any:
+		for i, item := range typed {
+			typed[i] = convertNumbers(item)
+		}
+	case json.Number:
+		if integer, err := typed.Int64(); err == nil {
+			return integer
+		}
+
+		float, _ := typed.Float64()
+		return float
+	}
+
+	return value
+}
+
+//nolint:ireturn
+func valueToNode(value any) (ast.Node, error) {
+	return yaml.ValueToNode(value, yaml.Indent(yamlIndent), yaml.IndentSequence(true), yaml.UseJSONMarshaler())
+}
+
+func mappingKey(entry *ast.MappingValueNode) string {
+	if key, ok := entry.Key.(ast.ScalarNode); ok {
+		if str, ok := key.GetValue().(string); ok {
+			return str
+		}
+	}
+
+	return entry.Key.String()
+}
+
+func isBlockCollection(node ast.Node) bool {
+	switch typed := node.(type) {
+	case *ast.MappingNode:
+		return !typed.IsFlowStyle && len(typed.Values) != 0
+	case *ast.SequenceNode:
+		return !typed.IsFlowStyle && len(typed.Values) != 0
+	default:
+		return false
+	}
+}
+
+func sameNodeType(a ast.Node, b ast.Node) bool {
+	return a.Type() == b.Type() && isBlockCollection(a)
+}
+
+// keepComment moves the inline comment of a replaced node to its replacement.
+func keepComment(old ast.Node, replacement ast.Node) {
+	if comment := old.GetComment(); comment != nil && replacement.GetComment() == nil {
+		_ = replacement.SetComment(comment)
+	}
+}
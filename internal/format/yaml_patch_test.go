@@ -0,0 +1,192 @@
+package format_test
+
+import (
+	"testing"
+
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/stretchr/testify/require"
+)
+
+const dashboardYAML = `# Managed by hand: keep comments!
+apiVersion: dashboard.grafana.app/v1
+kind: Dashboard
+metadata:
+  name: foo # the UID
+
+  # Annotations
+  annotations:
+    grafana.app/folder: ops
+spec:
+  title: Old title
+  tags: [a, b]
+  panels:
+    # The first panel
+    - id: 1 # first
+      title: A
+    - id: 2
+      title: B
+`
+
+func dashboard() map[string]any {
+	return map[string]any{
+		"apiVersion": "dashboard.grafana.app/v1",
+		"kind":       "Dashboard",
+		"metadata": map[string]any{
+			"name": "foo",
+			"annotations": map[string]any{
+				"grafana.app/folder": "ops",
+			},
+		},
+		"spec": map[string]any{
+			"title": "Old title",
+			"tags":  []any{"a", "b"},
+			"panels": []any{
+				map[string]any{"id": 1, "title": "A"},
+				map[string]any{"id": 2, "title": "B"},
+			},
+		},
+	}
+}
+
+func TestPatchYAML(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func(obj map[string]any)
+		want   string
+	}{
+		{
+			name:   "no changes",
+			update: func(map[string]any) {},
+			want:   dashboardYAML,
+		},
+		{
+			name: "scalar changed",
+			update: func(obj map[string]any) {
+				obj["spec"].(map[string]any)["title"] = "New title"
+				obj["spec"].(map[string]any)["panels"].([]any)[0].(map[string]any)["id"] = 10
+			},
+			want: `# Managed by hand: keep comments!
+apiVersion: dashboard.grafana.app/v1
+kind: Dashboard
+metadata:
+  name: foo # the UID
+
+  # Annotations
+  annotations:
+    grafana.app/folder: ops
+spec:
+  title: New title
+  tags: [a, b]
+  panels:
+    # The first panel
+    - id: 10 # first
+      title: A
+    - id: 2
+      title: B
+`,
+		},
+		{
+			name: "keys added and removed",
+			update: func(obj map[string]any) {
+				delete(obj["metadata"].(map[string]any), "annotations")
+				obj["metadata"].(map[string]any)["labels"] = map[string]any{"team": "ops"}
+				obj["spec"].(map[string]any)["editable"] = true
+			},
+			want: `# Managed by hand: keep comments!
+apiVersion: dashboard.grafana.app/v1
+kind: Dashboard
+metadata:
+  name: foo # the UID
+  labels:
+    team: ops
+spec:
+  title: Old title
+  tags: [a, b]
+  panels:
+    # The first panel
+    - id: 1 # first
+      title: A
+    - id: 2
+      title: B
+  editable: true
+`,
+		},
+		{
+			name: "sequence elements added and removed",
+			update: func(obj map[string]any) {
+				spec := obj["spec"].(map[string]any)
+				spec["tags"] = []any{"a", "b", "c"}
+				spec["panels"] = []any{
+					map[string]any{"id": 1, "title": "A", "type": "graph"},
+				}
+			},
+			want: `# Managed by hand: keep comments!
+apiVersion: dashboard.grafana.app/v1
+kind: Dashboard
+metadata:
+  name: foo # the UID
+
+  # Annotations
+  annotations:
+    grafana.app/folder: ops
+spec:
+  title: Old title
+  tags:
+    - a
+    - b
+    - c
+  panels:
+    # The first panel
+    - id: 1 # first
+      title: A
+      type: graph
+`,
+		},
+		{
+			name: "scalar replaced by a mapping",
+			update: func(obj map[string]any) {
+				obj["spec"].(map[string]any)["title"] = map[string]any{"text": "Title", "size": 2}
+				obj["spec"].(map[string]any)["panels"].([]any)[1] = "removed"
+			},
+			want: `# Managed by hand: keep comments!
+apiVersion: dashboard.grafana.app/v1
+kind: Dashboard
+metadata:
+  name: foo # the UID
+
+  # Annotations
+  annotations:
+    grafana.app/folder: ops
+spec:
+  title:
+    size: 2
+    text: Title
+  tags: [a, b]
+  panels:
+    # The first panel
+    - id: 1 # first
+      title: A
+    - removed
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			obj := dashboard()
+			tc.update(obj)
+
+			patched, err := format.PatchYAML([]byte(dashboardYAML), obj)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, string(patched))
+		})
+	}
+}
+
+func TestPatchYAML_unsupportedDocuments(t *testing.T) {
+	_, err := format.PatchYAML([]byte("a: 1\n---\nb: 2\n"), map[string]any{"a": 1})
+	require.ErrorContains(t, err, "single document")
+
+	_, err = format.PatchYAML([]byte("- a\n- b\n"), map[string]any{"a": 1})
+	require.ErrorContains(t, err, "mapping")
+}
@@ -1,14 +1,17 @@
 package grafana
 
 import (
+	"crypto/tls"
 	"errors"
 	"net/url"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/go-openapi/strfmt"
+	authlib "github.com/grafana/authlib/types"
 	goapi "github.com/grafana/grafana-openapi-client-go/client"
 	"github.com/grafana/grafanactl/internal/config"
+	"k8s.io/client-go/rest"
 )
 
 func ClientFromContext(ctx *config.Context) (*goapi.GrafanaHTTPAPI, error) {
@@ -19,30 +22,77 @@ func ClientFromContext(ctx *config.Context) (*goapi.GrafanaHTTPAPI, error) {
 		return nil, errors.New("grafana not configured")
 	}
 
-	grafanaURL, err := url.Parse(ctx.Grafana.Server)
+	opts := clientOptions{
+		Server:   ctx.Grafana.Server,
+		User:     ctx.Grafana.User,
+		Password: ctx.Grafana.Password,
+		Token:    ctx.Grafana.APIToken,
+		OrgID:    ctx.Grafana.OrgID,
+	}
+
+	if ctx.Grafana.TLS != nil {
+		opts.TLS = ctx.Grafana.TLS.ToStdTLSConfig()
+	}
+
+	return newClient(opts)
+}
+
+// ClientFromRESTConfig creates a client for the legacy HTTP API, targeting
+// the same server, organization and credentials as the given REST config.
+func ClientFromRESTConfig(cfg config.NamespacedRESTConfig) (*goapi.GrafanaHTTPAPI, error) {
+	tlsConfig, err := rest.TLSConfigFor(&cfg.Config)
 	if err != nil {
 		return nil, err
 	}
 
-	cfg := &goapi.TransportConfig{
-		Host:     grafanaURL.Host,
-		BasePath: strings.TrimLeft(grafanaURL.Path+"/api", "/"),
-		Schemes:  []string{grafanaURL.Scheme},
+	opts := clientOptions{
+		Server:   cfg.Host,
+		TLS:      tlsConfig,
+		User:     cfg.Username,
+		Password: cfg.Password,
+		Token:    cfg.BearerToken,
 	}
 
-	if ctx.Grafana.TLS != nil {
-		cfg.TLSConfig = ctx.Grafana.TLS.ToStdTLSConfig()
+	// Stacks only have one organization: the default one.
+	if info, err := authlib.ParseNamespace(cfg.Namespace); err == nil && info.StackID == 0 && info.OrgID > 1 {
+		opts.OrgID = info.OrgID
+	}
+
+	return newClient(opts)
+}
+
+// clientOptions describes the server targeted by a client, and how to authenticate to it.
+type clientOptions struct {
+	Server   string
+	TLS      *tls.Config
+	User     string
+	Password string
+	Token    string
+	OrgID    int64
+}
+
+func newClient(opts clientOptions) (*goapi.GrafanaHTTPAPI, error) {
+	grafanaURL, err := url.Parse(opts.Server)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &goapi.TransportConfig{
+		Host:      grafanaURL.Host,
+		BasePath:  strings.TrimLeft(grafanaURL.Path+"/api", "/"),
+		Schemes:   []string{grafanaURL.Scheme},
+		TLSConfig: opts.TLS,
 	}
 
 	// Authentication
-	if ctx.Grafana.User != "" && ctx.Grafana.Password != "" {
-		cfg.BasicAuth = url.UserPassword(ctx.Grafana.User, ctx.Grafana.Password)
+	if opts.User != "" && opts.Password != "" {
+		cfg.BasicAuth = url.UserPassword(opts.User, opts.Password)
 	}
-	if ctx.Grafana.APIToken != "" {
-		cfg.APIKey = ctx.Grafana.APIToken
+	if opts.Token != "" {
+		cfg.APIKey = opts.Token
 	}
-	if ctx.Grafana.OrgID != 0 {
-		cfg.OrgID = ctx.Grafana.OrgID
+	if opts.OrgID != 0 {
+		cfg.OrgID = opts.OrgID
 	}
 
 	return goapi.NewHTTPClientWithConfig(strfmt.Default, cfg), nil
@@ -0,0 +1,103 @@
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	goapi "github.com/grafana/grafana-openapi-client-go/client"
+	"github.com/grafana/grafana-openapi-client-go/client/dashboards"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grafanactl/internal/config"
+)
+
+// DashboardVersion is a version of a dashboard, as described by the legacy HTTP API.
+type DashboardVersion struct {
+	Version   int64
+	CreatedBy string
+	Created   time.Time
+	Message   string
+
+	// Data is the JSON model of the dashboard at this version.
+	// It is only set when a single version is fetched.
+	Data map[string]any
+}
+
+// DashboardVersionsClient gives access to the versions of dashboards with the
+// legacy HTTP API, for Grafana instances that don't expose the history of
+// resources through the Kubernetes-style API.
+type DashboardVersionsClient struct {
+	client *goapi.GrafanaHTTPAPI
+}
+
+// NewDashboardVersionsClient creates a DashboardVersionsClient for the given context.
+func NewDashboardVersionsClient(ctx *config.Context) (*DashboardVersionsClient, error) {
+	client, err := ClientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DashboardVersionsClient{client: client}, nil
+}
+
+// List returns the versions of a dashboard, most recent first.
+func (c *DashboardVersionsClient) List(ctx context.Context, uid string) ([]DashboardVersion, error) {
+	resp, err := c.client.Dashboards.GetDashboardVersionsByUID(
+		dashboards.NewGetDashboardVersionsByUIDParams().WithContext(ctx).WithUID(uid),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not list the versions of dashboard %s: %w", uid, err)
+	}
+
+	versions := make([]DashboardVersion, 0, len(resp.Payload.Versions))
+	for _, version := range resp.Payload.Versions {
+		versions = append(versions, dashboardVersion(version))
+	}
+
+	return versions, nil
+}
+
+// Get returns a version of a dashboard.
+func (c *DashboardVersionsClient) Get(ctx context.Context, uid string, version int64) (*DashboardVersion, error) {
+	resp, err := c.client.Dashboards.GetDashboardVersionByUIDWithParams(
+		dashboards.NewGetDashboardVersionByUIDParams().WithContext(ctx).WithUID(uid).WithDashboardVersionID(version),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not get version %d of dashboard %s: %w", version, uid, err)
+	}
+
+	result := dashboardVersion(resp.Payload)
+
+	data, ok := resp.Payload.Data.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected model for version %d of dashboard %s: %T", version, uid, resp.Payload.Data)
+	}
+
+	result.Data = data
+
+	return &result, nil
+}
+
+// Restore restores a dashboard to the given version.
+// Restoring a version creates a new version of the dashboard.
+func (c *DashboardVersionsClient) Restore(ctx context.Context, uid string, version int64) error {
+	_, err := c.client.Dashboards.RestoreDashboardVersionByUIDWithParams(
+		dashboards.NewRestoreDashboardVersionByUIDParams().WithContext(ctx).WithUID(uid).WithBody(
+			&models.RestoreDashboardVersionCommand{Version: version},
+		),
+	)
+	if err != nil {
+		return fmt.Errorf("could not restore version %d of dashboard %s: %w", version, uid, err)
+	}
+
+	return nil
+}
+
+func dashboardVersion(meta *models.DashboardVersionMeta) DashboardVersion {
+	return DashboardVersion{
+		Version:   meta.Version,
+		CreatedBy: meta.CreatedBy,
+		Created:   time.Time(meta.Created),
+		Message:   meta.Message,
+	}
+}
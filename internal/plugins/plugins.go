@@ -0,0 +1,178 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/grafana/grafanactl/internal/config"
+)
+
+// Prefix is the prefix of the name of plugin executables.
+const Prefix = "grafanactl-"
+
+// Environment variables given to plugins.
+const (
+	// ConfigEnvVar holds the path of the configuration file in use.
+	ConfigEnvVar = config.ConfigFileEnvVar
+	// ContextEnvVar holds the name of the current context.
+	ContextEnvVar = "GRAFANACTL_CONTEXT"
+	// ServerEnvVar holds the address of the Grafana server of the current context.
+	ServerEnvVar = "GRAFANACTL_SERVER"
+	// NamespaceEnvVar holds the namespace targeted by the current context.
+	NamespaceEnvVar = "GRAFANACTL_NAMESPACE"
+	// TokenFDEnvVar holds the file descriptor (the handle, on Windows) from which the
+	// API token of the current context can be read. It is only set when a token is configured.
+	TokenFDEnvVar = "GRAFANACTL_TOKEN_FD"
+	// BinaryEnvVar holds the path of the grafanactl executable running the plugin.
+	BinaryEnvVar = "GRAFANACTL_BIN"
+)
+
+// Plugin is an executable extending grafanactl with a command.
+type Plugin struct {
+	// Name of the command provided by the plugin.
+	Name string
+	// Path of the plugin executable.
+	Path string
+	// Shadowed lists the paths of the executables with the same name found
+	// later in $PATH, and thus ignored.
+	Shadowed []string
+}
+
+// Discover lists the plugins found in the directories of the given PATH list.
+// Plugins are returned in the order in which they are found: when several
+// executables provide the same name, the first one is used.
+func Discover(pathList string) []Plugin {
+	var plugins []Plugin
+	index := make(map[string]int)
+
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" {
+			continue
+		}
+
+		// Directories that can't be read are ignored, as by shells.
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok || entry.IsDir() {
+				continue
+			}
+
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+
+			if i, found := index[name]; found {
+				plugins[i].Shadowed = append(plugins[i].Shadowed, path)
+				continue
+			}
+
+			index[name] = len(plugins)
+			plugins = append(plugins, Plugin{Name: name, Path: path})
+		}
+	}
+
+	return plugins
+}
+
+func pluginName(filename string) (string, bool) {
+	if !strings.HasPrefix(filename, Prefix) {
+		return "", false
+	}
+
+	name := strings.TrimSuffix(strings.TrimPrefix(filename, Prefix), executableExtension(filename))
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return "", false
+	}
+
+	return name, true
+}
+
+// Context is the grafanactl context given to plugins.
+type Context struct {
+	// ConfigFile is the path of the configuration file in use.
+	ConfigFile string
+	// Name of the current context.
+	Name string
+	// Server is the address of the Grafana server.
+	Server string
+	// Namespace targeted by the context.
+	Namespace string
+	// Token is the API token of the context, given through a file descriptor.
+	Token string
+}
+
+// ExitError is returned when a plugin exits with a non-zero code.
+// The error of the plugin is expected to be reported by the plugin itself.
+type ExitError struct {
+	Plugin string
+	Code   int
+}
+
+func (e ExitError) Error() string {
+	return fmt.Sprintf("plugin %s exited with code %d", e.Plugin, e.Code)
+}
+
+// Run runs a plugin with the given arguments, with the standard streams of
+// the current process and the context exposed through environment variables.
+func Run(ctx context.Context, plugin Plugin, args []string, pluginCtx Context) error {
+	cmd := exec.CommandContext(ctx, plugin.Path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), environment(pluginCtx)...)
+
+	if binary, err := os.Executable(); err == nil {
+		cmd.Env = append(cmd.Env, BinaryEnvVar+"="+binary)
+	}
+
+	if pluginCtx.Token != "" {
+		closeToken, err := passToken(cmd, pluginCtx.Token)
+		if err != nil {
+			return err
+		}
+		defer closeToken()
+	}
+
+	if err := cmd.Run(); err != nil {
+		exitErr := &exec.ExitError{}
+		if errors.As(err, &exitErr) {
+			return ExitError{Plugin: plugin.Name, Code: exitErr.ExitCode()}
+		}
+
+		return fmt.Errorf("could not run plugin %s: %w", plugin.Name, err)
+	}
+
+	return nil
+}
+
+func environment(pluginCtx Context) []string {
+	vars := []struct {
+		name  string
+		value string
+	}{
+		{ConfigEnvVar, pluginCtx.ConfigFile},
+		{ContextEnvVar, pluginCtx.Name},
+		{ServerEnvVar, pluginCtx.Server},
+		{NamespaceEnvVar, pluginCtx.Namespace},
+	}
+
+	env := make([]string, 0, len(vars))
+	for _, v := range vars {
+		if v.value != "" {
+			env = append(env, v.name+"="+v.value)
+		}
+	}
+
+	return env
+}
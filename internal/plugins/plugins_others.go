@@ -0,0 +1,47 @@
+//go:build !windows
+
+package plugins
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+func executableExtension(string) string {
+	return ""
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	return info.Mode().IsRegular() && info.Mode().Perm()&0111 != 0
+}
+
+// passToken gives the token to the plugin through a pipe, inherited as the
+// first extra file descriptor: it never appears in the environment or the
+// arguments of the plugin.
+func passToken(cmd *exec.Cmd, token string) (func(), error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	// The token is much smaller than the buffer of the pipe: writing it
+	// doesn't block, even if the plugin never reads it.
+	if _, err := writer.WriteString(token); err != nil {
+		reader.Close()
+		writer.Close()
+		return nil, err
+	}
+	writer.Close()
+
+	// Extra files are numbered from 3, after the standard streams.
+	cmd.ExtraFiles = append(cmd.ExtraFiles, reader)
+	cmd.Env = append(cmd.Env, TokenFDEnvVar+"="+strconv.Itoa(2+len(cmd.ExtraFiles)))
+
+	return func() { reader.Close() }, nil
+}
@@ -0,0 +1,91 @@
+package plugins_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafanactl/internal/plugins"
+	"github.com/stretchr/testify/require"
+)
+
+func writeExecutable(t *testing.T, dir string, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0700))
+
+	return path
+}
+
+func TestDiscover(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires POSIX executables")
+	}
+
+	req := require.New(t)
+
+	first := t.TempDir()
+	second := t.TempDir()
+
+	promote := writeExecutable(t, first, "grafanactl-promote", "#!/bin/sh\n")
+	shadowed := writeExecutable(t, second, "grafanactl-promote", "#!/bin/sh\n")
+	audit := writeExecutable(t, second, "grafanactl-audit", "#!/bin/sh\n")
+
+	// Files that are not executable, or not named after a plugin, are ignored.
+	req.NoError(os.WriteFile(filepath.Join(first, "grafanactl-notes"), nil, 0600))
+	writeExecutable(t, first, "kubectl-promote", "#!/bin/sh\n")
+	writeExecutable(t, first, "grafanactl-", "#!/bin/sh\n")
+	req.NoError(os.Mkdir(filepath.Join(first, "grafanactl-dir"), 0700))
+
+	found := plugins.Discover(strings.Join([]string{first, "", filepath.Join(first, "missing"), second}, string(os.PathListSeparator)))
+
+	req.Equal([]plugins.Plugin{
+		{Name: "promote", Path: promote, Shadowed: []string{shadowed}},
+		{Name: "audit", Path: audit},
+	}, found)
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires POSIX executables")
+	}
+
+	req := require.New(t)
+
+	dir := t.TempDir()
+	output := filepath.Join(dir, "output")
+
+	path := writeExecutable(t, dir, "grafanactl-env", `#!/bin/sh
+{
+	echo "args=$*"
+	echo "server=$GRAFANACTL_SERVER"
+	echo "namespace=$GRAFANACTL_NAMESPACE"
+	echo "context=$GRAFANACTL_CONTEXT"
+	echo "config=$GRAFANACTL_CONFIG"
+	echo "token=$(cat <&"$GRAFANACTL_TOKEN_FD")"
+} > "`+output+`"
+exit 4
+`)
+
+	err := plugins.Run(t.Context(), plugins.Plugin{Name: "env", Path: path}, []string{"--flag", "value"}, plugins.Context{
+		ConfigFile: "/etc/grafanactl.yaml",
+		Name:       "prod",
+		Server:     "https://grafana.example",
+		Namespace:  "stacks-12",
+		Token:      "glsa_secret",
+	})
+	req.Equal(plugins.ExitError{Plugin: "env", Code: 4}, err)
+
+	written, err := os.ReadFile(output)
+	req.NoError(err)
+	req.Equal(`args=--flag value
+server=https://grafana.example
+namespace=stacks-12
+context=prod
+config=/etc/grafanactl.yaml
+token=glsa_secret
+`, string(written))
+}
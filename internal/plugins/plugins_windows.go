@@ -0,0 +1,63 @@
+//go:build windows
+
+package plugins
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+//nolint:gochecknoglobals
+var executableExtensions = []string{".exe", ".bat", ".cmd", ".com"}
+
+func executableExtension(filename string) string {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if slices.Contains(executableExtensions, ext) {
+		return filepath.Ext(filename)
+	}
+
+	return ""
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	return info.Mode().IsRegular() && executableExtension(path) != ""
+}
+
+// passToken gives the token to the plugin through a pipe, whose handle is
+// inherited by the plugin: it never appears in the environment or the arguments
+// of the plugin. File descriptors don't exist on Windows: the value of the
+// handle is given instead.
+func passToken(cmd *exec.Cmd, token string) (func(), error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	// The token is much smaller than the buffer of the pipe: writing it
+	// doesn't block, even if the plugin never reads it.
+	if _, err := writer.WriteString(token); err != nil {
+		reader.Close()
+		writer.Close()
+		return nil, err
+	}
+	writer.Close()
+
+	// Pipes are created inheritable: the handle only has to be listed.
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.AdditionalInheritedHandles = append(cmd.SysProcAttr.AdditionalInheritedHandles, syscall.Handle(reader.Fd()))
+	cmd.Env = append(cmd.Env, TokenFDEnvVar+"="+strconv.FormatUint(uint64(reader.Fd()), 10))
+
+	return func() { reader.Close() }, nil
+}
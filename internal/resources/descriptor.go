@@ -8,6 +8,10 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+// LegacyGroup is the API group of the resources served by the legacy HTTP API
+// of Grafana, for kinds that are not exposed by its Kubernetes-style API.
+const LegacyGroup = "legacy.grafana.app"
+
 // Descriptors is a list of descriptors.
 type Descriptors []Descriptor
 
@@ -42,6 +46,12 @@ func (d Descriptor) String() string {
 	return fmt.Sprintf("%s.%s.%s", d.Plural, d.GroupVersion.Version, d.GroupVersion.Group)
 }
 
+// IsLegacy returns true if the resource is served by the legacy HTTP API
+// rather than by the Kubernetes-style API.
+func (d Descriptor) IsLegacy() bool {
+	return d.GroupVersion.Group == LegacyGroup
+}
+
 // GroupVersionKind returns the GroupVersionKind for the descriptor.
 func (d Descriptor) GroupVersionKind() schema.GroupVersionKind {
 	return schema.GroupVersionKind{
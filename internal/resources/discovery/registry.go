@@ -7,7 +7,9 @@ import (
 
 	"github.com/grafana/grafanactl/internal/config"
 	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/legacy"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
 )
 
@@ -38,13 +40,53 @@ type Registry struct {
 }
 
 // NewDefaultRegistry creates a new discovery registry using the default discovery client.
+// The registry includes the resources served by the legacy HTTP API.
 func NewDefaultRegistry(ctx context.Context, cfg config.NamespacedRESTConfig) (*Registry, error) {
 	client, err := discovery.NewDiscoveryClientForConfig(&cfg.Config)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewRegistry(ctx, client)
+	return NewRegistry(ctx, WithLegacyResources(client))
+}
+
+// WithLegacyResources wraps a discovery client, adding the resources
+// served by the legacy HTTP API to the resources it discovers.
+func WithLegacyResources(client Client) Client {
+	return legacyClient{Client: client}
+}
+
+type legacyClient struct {
+	Client
+}
+
+func (c legacyClient) ServerGroupsAndResources() ([]*metav1.APIGroup, []*metav1.APIResourceList, error) {
+	groups, lists, err := c.Client.ServerGroupsAndResources()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gv := schema.GroupVersion{Group: resources.LegacyGroup, Version: legacy.Version}
+	version := metav1.GroupVersionForDiscovery{GroupVersion: gv.String(), Version: gv.Version}
+
+	list := &metav1.APIResourceList{GroupVersion: gv.String()}
+	for _, desc := range legacy.Descriptors() {
+		list.APIResources = append(list.APIResources, metav1.APIResource{
+			Name:         desc.Plural,
+			SingularName: desc.Singular,
+			Kind:         desc.Kind,
+			Namespaced:   true,
+			Verbs:        metav1.Verbs{"get", "list", "create", "update", "delete"},
+		})
+	}
+
+	groups = append(groups, &metav1.APIGroup{
+		Name:             gv.Group,
+		Versions:         []metav1.GroupVersionForDiscovery{version},
+		PreferredVersion: version,
+	})
+
+	return groups, append(lists, list), nil
 }
 
 // NewRegistry creates a new discovery registry.
@@ -602,3 +602,47 @@ type mockDiscoveryClient struct {
 func (m *mockDiscoveryClient) ServerGroupsAndResources() ([]*metav1.APIGroup, []*metav1.APIResourceList, error) {
 	return m.groups, m.resources, m.err
 }
+
+func TestRegistry_WithLegacyResources(t *testing.T) {
+	req := require.New(t)
+
+	groups, apiResources := getMixedVersionsDiscovery()
+	client := discovery.WithLegacyResources(&mockDiscoveryClient{
+		groups:    groups,
+		resources: apiResources,
+	})
+
+	reg, err := discovery.NewRegistry(t.Context(), client)
+	req.NoError(err)
+
+	// Kinds served by the Kubernetes-style API are still preferred.
+	filters, err := reg.MakeFilters(discovery.MakeFiltersOptions{
+		Selectors: resources.Selectors{
+			{Type: resources.FilterTypeAll, GroupVersionKind: resources.PartialGVK{Resource: "dashboards"}},
+			{Type: resources.FilterTypeAll, GroupVersionKind: resources.PartialGVK{Resource: "datasources"}},
+		},
+		PreferredVersionOnly: true,
+	})
+	req.NoError(err)
+	req.Len(filters, 2)
+	req.False(filters[0].Descriptor.IsLegacy())
+	req.True(filters[1].Descriptor.IsLegacy())
+	req.Equal(resources.Descriptor{
+		GroupVersion: schema.GroupVersion{Group: resources.LegacyGroup, Version: "v0"},
+		Kind:         "DataSource",
+		Singular:     "datasource",
+		Plural:       "datasources",
+	}, filters[1].Descriptor)
+
+	// The short name of the group selects legacy resources explicitly.
+	filters, err = reg.MakeFilters(discovery.MakeFiltersOptions{
+		Selectors: resources.Selectors{
+			{Type: resources.FilterTypeAll, GroupVersionKind: resources.PartialGVK{Resource: "teams", Group: "legacy"}},
+		},
+		PreferredVersionOnly: true,
+	})
+	req.NoError(err)
+	req.Len(filters, 1)
+	req.Equal("Team", filters[0].Descriptor.Kind)
+	req.True(filters[0].Descriptor.IsLegacy())
+}
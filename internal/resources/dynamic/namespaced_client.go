@@ -10,6 +10,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/tools/pager"
 )
@@ -114,6 +116,16 @@ func (c *NamespacedClient) Get(
 	return res, ParseStatusError(err)
 }
 
+// Watch watches resources on the server.
+func (c *NamespacedClient) Watch(
+	ctx context.Context, desc resources.Descriptor, opts metav1.ListOptions,
+) (watch.Interface, error) {
+	opts.Watch = true
+
+	res, err := c.client.Resource(desc.GroupVersionResource()).Namespace(c.namespace).Watch(ctx, opts)
+	return res, ParseStatusError(err)
+}
+
 // Create creates a resource on the server.
 func (c *NamespacedClient) Create(
 	ctx context.Context, desc resources.Descriptor, obj *unstructured.Unstructured, opts metav1.CreateOptions,
@@ -145,3 +157,11 @@ func (c *NamespacedClient) Apply(
 	res, err := c.client.Resource(desc.GroupVersionResource()).Namespace(c.namespace).Apply(ctx, name, obj, opts)
 	return res, ParseStatusError(err)
 }
+
+// Patch patches a resource on the server.
+func (c *NamespacedClient) Patch(
+	ctx context.Context, desc resources.Descriptor, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions,
+) (*unstructured.Unstructured, error) {
+	res, err := c.client.Resource(desc.GroupVersionResource()).Namespace(c.namespace).Patch(ctx, name, pt, data, opts)
+	return res, ParseStatusError(err)
+}
@@ -0,0 +1,115 @@
+package dynamic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/grafana/grafanactl/internal/config"
+	"github.com/grafana/grafanactl/internal/resources"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+// tableAcceptHeader requests the Table representation of resources,
+// falling back to the regular representation if the server doesn't support it.
+const tableAcceptHeader = "application/json;as=Table;v=v1;g=meta.k8s.io,application/json"
+
+// ErrTableNotSupported is returned when the server can't represent resources as a table.
+var ErrTableNotSupported = errors.New("the server does not support the table representation")
+
+// TableClient gets resources in their Table representation,
+// as declared by the server for each kind of resource.
+type TableClient struct {
+	namespace string
+	client    rest.Interface
+}
+
+// NewDefaultTableClient creates a new table client.
+func NewDefaultTableClient(cfg config.NamespacedRESTConfig) (*TableClient, error) {
+	client, err := rest.UnversionedRESTClientFor(dynamic.ConfigFor(&cfg.Config))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTableClient(cfg.Namespace, client), nil
+}
+
+// NewTableClient creates a new table client.
+func NewTableClient(namespace string, client rest.Interface) *TableClient {
+	return &TableClient{
+		namespace: namespace,
+		client:    client,
+	}
+}
+
+// List lists resources from the server as a table.
+// It automatically handles pagination, and includes the metadata of the resources in the rows.
+// It returns ErrTableNotSupported if the server doesn't support the table representation.
+func (c *TableClient) List(ctx context.Context, desc resources.Descriptor) (*metav1.Table, error) {
+	var res *metav1.Table
+	continueToken := ""
+
+	for {
+		req := c.client.Get().
+			AbsPath(c.resourcePath(desc)).
+			SetHeader("Accept", tableAcceptHeader).
+			Param("includeObject", string(metav1.IncludeMetadata))
+		if continueToken != "" {
+			req = req.Param("continue", continueToken)
+		}
+
+		body, err := req.Do(ctx).Raw()
+		if err != nil {
+			if apierrors.IsNotAcceptable(err) || apierrors.IsUnsupportedMediaType(err) {
+				return nil, ErrTableNotSupported
+			}
+
+			return nil, ParseStatusError(err)
+		}
+
+		page := &metav1.Table{}
+		if err := json.Unmarshal(body, page); err != nil {
+			return nil, fmt.Errorf("could not decode table: %w", err)
+		}
+
+		// Servers ignoring the Accept header return a regular list.
+		if page.Kind != "Table" || page.APIVersion != metav1.SchemeGroupVersion.String() {
+			return nil, ErrTableNotSupported
+		}
+
+		if res == nil {
+			res = page
+		} else {
+			res.Rows = append(res.Rows, page.Rows...)
+		}
+
+		continueToken = page.Continue
+		if continueToken == "" {
+			break
+		}
+	}
+
+	res.Continue = ""
+
+	return res, nil
+}
+
+func (c *TableClient) resourcePath(desc resources.Descriptor) string {
+	gv := desc.GroupVersion
+
+	prefix := path.Join("/apis", gv.Group, gv.Version)
+	if gv.Group == "" {
+		prefix = path.Join("/api", gv.Version)
+	}
+
+	if c.namespace == "" {
+		return path.Join(prefix, desc.Plural)
+	}
+
+	return path.Join(prefix, "namespaces", c.namespace, desc.Plural)
+}
@@ -0,0 +1,107 @@
+package dynamic_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafanactl/internal/config"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/dynamic"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+func dashboardsDescriptor() resources.Descriptor {
+	return resources.Descriptor{
+		GroupVersion: schema.GroupVersion{Group: "dashboard.grafana.app", Version: "v1"},
+		Kind:         "Dashboard",
+		Singular:     "dashboard",
+		Plural:       "dashboards",
+	}
+}
+
+func newTableClient(t *testing.T, handler http.HandlerFunc) *dynamic.TableClient {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := dynamic.NewDefaultTableClient(config.NamespacedRESTConfig{
+		Config:    rest.Config{Host: server.URL},
+		Namespace: "default",
+	})
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestTableClient_List(t *testing.T) {
+	req := require.New(t)
+
+	pages := map[string]string{
+		"": `{
+			"kind": "Table", "apiVersion": "meta.k8s.io/v1",
+			"metadata": {"continue": "page-2"},
+			"columnDefinitions": [{"name": "Name", "type": "string"}, {"name": "Title", "type": "string"}],
+			"rows": [{"cells": ["foo", "Foo"]}]
+		}`,
+		"page-2": `{
+			"kind": "Table", "apiVersion": "meta.k8s.io/v1",
+			"metadata": {},
+			"columnDefinitions": [{"name": "Name", "type": "string"}, {"name": "Title", "type": "string"}],
+			"rows": [{"cells": ["bar", "Bar"]}]
+		}`,
+	}
+
+	client := newTableClient(t, func(w http.ResponseWriter, r *http.Request) {
+		req.Equal("/apis/dashboard.grafana.app/v1/namespaces/default/dashboards", r.URL.Path)
+		req.Contains(r.Header.Get("Accept"), "as=Table;v=v1;g=meta.k8s.io")
+		req.Equal("Metadata", r.URL.Query().Get("includeObject"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(pages[r.URL.Query().Get("continue")]))
+	})
+
+	table, err := client.List(t.Context(), dashboardsDescriptor())
+	req.NoError(err)
+
+	req.Len(table.ColumnDefinitions, 2)
+	req.Len(table.Rows, 2)
+	req.Equal([]any{"foo", "Foo"}, table.Rows[0].Cells)
+	req.Equal([]any{"bar", "Bar"}, table.Rows[1].Cells)
+	req.Empty(table.Continue)
+}
+
+func TestTableClient_List_notSupported(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{
+			name: "regular list",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"kind": "DashboardList", "apiVersion": "dashboard.grafana.app/v1", "items": []}`))
+			},
+		},
+		{
+			name: "not acceptable",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotAcceptable)
+				_, _ = w.Write([]byte(`{"kind": "Status", "apiVersion": "v1", "status": "Failure", "reason": "NotAcceptable", "code": 406}`))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newTableClient(t, tc.handler)
+
+			_, err := client.List(t.Context(), dashboardsDescriptor())
+			require.ErrorIs(t, err, dynamic.ErrTableNotSupported)
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/grafana/grafanactl/internal/resources"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
 // VersionedClient is a dynamic client that supports automatic version switching.
@@ -88,6 +89,44 @@ func (c *VersionedClient) Get(
 	return c.NamespacedClient.Get(ctx, newdesc, name, opts)
 }
 
+// Watch watches resources on the server.
+// It will automatically re-fetch resources which need to be fetched using the stored version.
+func (c *VersionedClient) Watch(
+	ctx context.Context, desc resources.Descriptor, opts metav1.ListOptions,
+) (watch.Interface, error) {
+	watcher, err := c.NamespacedClient.Watch(ctx, desc, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return watch.Filter(watcher, func(event watch.Event) (watch.Event, bool) {
+		if event.Type != watch.Added && event.Type != watch.Modified {
+			return event, true
+		}
+
+		obj, ok := event.Object.(*unstructured.Unstructured)
+		if !ok {
+			return event, true
+		}
+
+		storedVersion := getStoredVersion(obj)
+		if storedVersion == "" {
+			return event, true
+		}
+
+		newdesc := desc
+		newdesc.GroupVersion.Version = storedVersion
+
+		// If the object can't be re-fetched (e.g. it was deleted in the meantime),
+		// the event will be followed by another one: keep the original object.
+		if versioned, err := c.NamespacedClient.Get(ctx, newdesc, obj.GetName(), metav1.GetOptions{}); err == nil {
+			event.Object = versioned
+		}
+
+		return event, true
+	}), nil
+}
+
 func (c *VersionedClient) getMultipleCorrectVersions(
 	ctx context.Context, desc resources.Descriptor, src []unstructured.Unstructured, opts metav1.ListOptions,
 ) ([]unstructured.Unstructured, error) {
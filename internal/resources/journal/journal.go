@@ -0,0 +1,277 @@
+package journal
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/grafana/grafanactl/internal/config"
+	"github.com/grafana/grafanactl/internal/resources"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// MaxOperations is the number of operations kept in a journal.
+// Older operations are removed when new ones are saved.
+const MaxOperations = 100
+
+// ErrNotFound is returned when an operation does not exist in the journal.
+var ErrNotFound = errors.New("operation not found")
+
+// Action describes how a resource was changed by an operation.
+type Action string
+
+const (
+	ActionCreated Action = "created"
+	ActionUpdated Action = "updated"
+	ActionDeleted Action = "deleted"
+)
+
+// Change is a change made to a resource in Grafana.
+type Change struct {
+	Action     Action `json:"action"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+
+	// Previous is the resource as it was in Grafana before the change.
+	// It is not set for created resources.
+	Previous *unstructured.Unstructured `json:"previous,omitempty"`
+}
+
+// Operation is a command that changed resources in Grafana.
+type Operation struct {
+	ID      string    `json:"id"`
+	Command string    `json:"command"`
+	Time    time.Time `json:"time"`
+
+	// Context, server and namespace targeted by the operation.
+	Context   string `json:"context"`
+	Server    string `json:"server"`
+	Namespace string `json:"namespace"`
+
+	// Undoes is the ID of the operation reverted by this operation, if any.
+	Undoes string `json:"undoes,omitempty"`
+	// UndoneBy is the ID of the operation that reverted this operation, if any.
+	UndoneBy string `json:"undoneBy,omitempty"`
+
+	Changes []Change `json:"changes"`
+
+	mu sync.Mutex
+}
+
+// NewOperation creates an operation for the given command, targeting the given context.
+func NewOperation(command string, contextName string, cfg config.NamespacedRESTConfig) *Operation {
+	now := time.Now()
+
+	suffix := make([]byte, 2)
+	_, _ = rand.Read(suffix)
+
+	return &Operation{
+		// IDs are sorted chronologically.
+		ID:        now.UTC().Format("20060102-150405") + "-" + hex.EncodeToString(suffix),
+		Command:   command,
+		Time:      now,
+		Context:   contextName,
+		Server:    cfg.Host,
+		Namespace: cfg.Namespace,
+	}
+}
+
+// Record adds a change to the operation.
+// It can be called concurrently.
+func (op *Operation) Record(change Change) {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+
+	op.Changes = append(op.Changes, change)
+}
+
+// IsEmpty returns true if the operation did not change anything.
+func (op *Operation) IsEmpty() bool {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+
+	return len(op.Changes) == 0
+}
+
+// Reversal returns what must be done in Grafana to revert the operation:
+// the resources to restore to their previous state, and the resources to delete.
+// Resources to delete are returned in reverse order of creation, so that children
+// are deleted before their parents (e.g. dashboards before their folder).
+func (op *Operation) Reversal() (*resources.Resources, []*resources.Resource, error) {
+	restore := resources.NewResources()
+	remove := []*resources.Resource{}
+
+	for _, change := range slices.Backward(op.Changes) {
+		res, err := change.Reverted()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if change.Action == ActionCreated {
+			remove = append(remove, res)
+		} else {
+			restore.Add(res)
+		}
+	}
+
+	return restore, remove, nil
+}
+
+// Reverted returns the resource reverting the change: the resource to delete
+// if it was created, or the previous state of the resource to restore otherwise.
+func (change Change) Reverted() (*resources.Resource, error) {
+	if change.Action == ActionCreated {
+		return resources.FromUnstructured(&unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": change.APIVersion,
+			"kind":       change.Kind,
+			"metadata": map[string]any{
+				"name": change.Name,
+			},
+		}})
+	}
+
+	if change.Previous == nil {
+		return nil, fmt.Errorf("no previous state recorded for %s/%s", change.Kind, change.Name)
+	}
+
+	previous := change.Previous.DeepCopy()
+
+	// Fields set by the server prevent the resource from being created again.
+	unstructured.RemoveNestedField(previous.Object, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(previous.Object, "metadata", "uid")
+	unstructured.RemoveNestedField(previous.Object, "metadata", "generation")
+	unstructured.RemoveNestedField(previous.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(previous.Object, "metadata", "deletionTimestamp")
+	unstructured.RemoveNestedField(previous.Object, "metadata", "managedFields")
+	unstructured.RemoveNestedField(previous.Object, "status")
+
+	return resources.FromUnstructured(previous)
+}
+
+// Journal stores the operations made to resources in Grafana, in a directory
+// holding one JSON file per operation.
+type Journal struct {
+	Dir string
+}
+
+// DefaultDir returns the directory of the default journal, in the XDG state directory.
+func DefaultDir() string {
+	return filepath.Join(xdg.StateHome, config.StandardConfigFolder, "journal")
+}
+
+// Save writes an operation to the journal, and removes the oldest operations
+// if the journal holds more than MaxOperations.
+func (journal Journal) Save(op *Operation) error {
+	op.mu.Lock()
+	raw, err := json.MarshalIndent(op, "", "  ")
+	op.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(journal.Dir, 0o700); err != nil {
+		return err
+	}
+
+	// Operations contain resources: they are only readable by the user.
+	if err := os.WriteFile(journal.path(op.ID), raw, 0o600); err != nil {
+		return err
+	}
+
+	ids, err := journal.ids()
+	if err != nil {
+		return err
+	}
+
+	for len(ids) > MaxOperations {
+		if err := os.Remove(journal.path(ids[0])); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		ids = ids[1:]
+	}
+
+	return nil
+}
+
+// Get reads an operation from the journal.
+func (journal Journal) Get(id string) (*Operation, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+
+	raw, err := os.ReadFile(journal.path(id))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+
+		return nil, err
+	}
+
+	op := &Operation{}
+	if err := json.Unmarshal(raw, op); err != nil {
+		return nil, fmt.Errorf("could not read operation %s: %w", id, err)
+	}
+
+	return op, nil
+}
+
+// List returns the operations of the journal, most recent first.
+func (journal Journal) List() ([]*Operation, error) {
+	ids, err := journal.ids()
+	if err != nil {
+		return nil, err
+	}
+
+	operations := make([]*Operation, 0, len(ids))
+	for _, id := range slices.Backward(ids) {
+		op, err := journal.Get(id)
+		if err != nil {
+			return nil, err
+		}
+
+		operations = append(operations, op)
+	}
+
+	return operations, nil
+}
+
+// ids returns the IDs of the operations of the journal, oldest first.
+func (journal Journal) ids() ([]string, error) {
+	entries, err := os.ReadDir(journal.Dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		ids = append(ids, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+
+	slices.Sort(ids)
+
+	return ids, nil
+}
+
+func (journal Journal) path(id string) string {
+	return filepath.Join(journal.Dir, id+".json")
+}
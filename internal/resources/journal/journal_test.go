@@ -0,0 +1,132 @@
+package journal_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/grafana/grafanactl/internal/config"
+	"github.com/grafana/grafanactl/internal/resources/journal"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+)
+
+func testConfig() config.NamespacedRESTConfig {
+	return config.NamespacedRESTConfig{
+		Config:    rest.Config{Host: "https://grafana.example"},
+		Namespace: "default",
+	}
+}
+
+func dashboard(name string, title string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "dashboard.grafana.app/v1",
+		"kind":       "Dashboard",
+		"metadata": map[string]any{
+			"name":              name,
+			"namespace":         "default",
+			"resourceVersion":   "42",
+			"uid":               "some-uid",
+			"creationTimestamp": "2026-01-01T00:00:00Z",
+		},
+		"spec": map[string]any{
+			"title": title,
+		},
+	}}
+}
+
+func TestJournal_SaveAndList(t *testing.T) {
+	req := require.New(t)
+
+	store := journal.Journal{Dir: t.TempDir()}
+
+	first := journal.NewOperation("push", "prod", testConfig())
+	first.ID = "20260101-000000-aaaa"
+	first.Record(journal.Change{Action: journal.ActionCreated, APIVersion: "dashboard.grafana.app/v1", Kind: "Dashboard", Name: "a"})
+
+	second := journal.NewOperation("delete", "prod", testConfig())
+	second.ID = "20260102-000000-bbbb"
+	second.Record(journal.Change{Action: journal.ActionDeleted, APIVersion: "dashboard.grafana.app/v1", Kind: "Dashboard", Name: "b", Previous: dashboard("b", "B")})
+
+	req.NoError(store.Save(first))
+	req.NoError(store.Save(second))
+
+	operations, err := store.List()
+	req.NoError(err)
+	req.Len(operations, 2)
+	req.Equal(second.ID, operations[0].ID)
+	req.Equal(first.ID, operations[1].ID)
+
+	got, err := store.Get(second.ID)
+	req.NoError(err)
+	req.Equal("delete", got.Command)
+	req.Equal("prod", got.Context)
+	req.Equal("https://grafana.example", got.Server)
+	req.Equal("B", got.Changes[0].Previous.Object["spec"].(map[string]any)["title"])
+
+	_, err = store.Get("unknown")
+	req.ErrorIs(err, journal.ErrNotFound)
+}
+
+func TestJournal_Save_RemovesOldestOperations(t *testing.T) {
+	req := require.New(t)
+
+	store := journal.Journal{Dir: t.TempDir()}
+
+	for i := range journal.MaxOperations + 2 {
+		op := journal.NewOperation("push", "prod", testConfig())
+		op.ID = fmt.Sprintf("20260101-%06d-aaaa", i)
+		req.NoError(store.Save(op))
+	}
+
+	operations, err := store.List()
+	req.NoError(err)
+	req.Len(operations, journal.MaxOperations)
+	req.Equal("20260101-000002-aaaa", operations[len(operations)-1].ID)
+}
+
+func TestOperation_Reversal_deletionOrder(t *testing.T) {
+	req := require.New(t)
+
+	op := journal.NewOperation("push", "prod", testConfig())
+	op.Record(journal.Change{Action: journal.ActionCreated, APIVersion: "folder.grafana.app/v1", Kind: "Folder", Name: "parent"})
+	op.Record(journal.Change{Action: journal.ActionCreated, APIVersion: "folder.grafana.app/v1", Kind: "Folder", Name: "child"})
+	op.Record(journal.Change{Action: journal.ActionCreated, APIVersion: "dashboard.grafana.app/v1", Kind: "Dashboard", Name: "dashboard"})
+
+	_, remove, err := op.Reversal()
+	req.NoError(err)
+
+	names := make([]string, 0, len(remove))
+	for _, res := range remove {
+		names = append(names, res.Name())
+	}
+
+	// Children are deleted before their parents.
+	req.Equal([]string{"dashboard", "child", "parent"}, names)
+}
+
+func TestOperation_Reversal(t *testing.T) {
+	req := require.New(t)
+
+	op := journal.NewOperation("push", "prod", testConfig())
+	op.Record(journal.Change{Action: journal.ActionCreated, APIVersion: "dashboard.grafana.app/v1", Kind: "Dashboard", Name: "created"})
+	op.Record(journal.Change{Action: journal.ActionUpdated, APIVersion: "dashboard.grafana.app/v1", Kind: "Dashboard", Name: "updated", Previous: dashboard("updated", "Before")})
+	op.Record(journal.Change{Action: journal.ActionDeleted, APIVersion: "dashboard.grafana.app/v1", Kind: "Dashboard", Name: "deleted", Previous: dashboard("deleted", "Deleted")})
+
+	restore, remove, err := op.Reversal()
+	req.NoError(err)
+
+	req.Len(remove, 1)
+	req.Equal("created", remove[0].Name())
+
+	req.Equal(2, restore.Len())
+	for _, res := range restore.AsList() {
+		obj := res.ToUnstructured()
+
+		// Server-side fields are removed, so that resources can be created again.
+		req.Empty(obj.GetResourceVersion())
+		req.Empty(obj.GetUID())
+		req.NotContains(obj.Object["metadata"], "creationTimestamp")
+		req.Equal("default", obj.GetNamespace())
+	}
+}
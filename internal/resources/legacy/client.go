@@ -0,0 +1,311 @@
+package legacy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+
+	goapi "github.com/grafana/grafana-openapi-client-go/client"
+	"github.com/grafana/grafanactl/internal/config"
+	"github.com/grafana/grafanactl/internal/grafana"
+	"github.com/grafana/grafanactl/internal/resources"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// Version is the API version of the resources served by the legacy HTTP API.
+const Version = "v0"
+
+// ErrWatchNotSupported is returned when watching resources served by the legacy HTTP API.
+var ErrWatchNotSupported = errors.New("watching resources is not supported by the legacy HTTP API")
+
+// ErrMetadataNotSupported is returned when writing labels or annotations to resources
+// served by the legacy HTTP API: their objects have no metadata to hold them.
+var ErrMetadataNotSupported = errors.New("labels and annotations are not supported by the legacy HTTP API")
+
+// Client is a client for the resources served by the legacy HTTP API of Grafana.
+//
+// Objects of the legacy HTTP API are presented as resources: their UID is the
+// name of the resource, and their JSON model, without server-managed fields,
+// is its spec. They have no labels
+// nor annotations: the manager and source annotations set by grafanactl are dropped,
+// and other labels or annotations are rejected.
+type Client struct {
+	namespace string
+	endpoints map[string]endpoint
+}
+
+// NewDefaultClient creates a new Client targeting the Grafana instance of the given REST config.
+func NewDefaultClient(cfg config.NamespacedRESTConfig) (*Client, error) {
+	api, err := grafana.ClientFromRESTConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(cfg.Namespace, api), nil
+}
+
+// NewClient creates a new Client using the given legacy HTTP API client.
+func NewClient(namespace string, api *goapi.GrafanaHTTPAPI) *Client {
+	return &Client{
+		namespace: namespace,
+		endpoints: newEndpoints(api),
+	}
+}
+
+// Descriptors returns the descriptors of the resources served by the legacy HTTP API.
+func Descriptors() resources.Descriptors {
+	descs := make(resources.Descriptors, 0, len(kinds))
+	for _, k := range kinds {
+		descs = append(descs, k.descriptor())
+	}
+
+	return descs
+}
+
+// List lists resources from the server.
+// Label and field selectors are not supported.
+func (c *Client) List(
+	ctx context.Context, desc resources.Descriptor, opts metav1.ListOptions,
+) (*unstructured.UnstructuredList, error) {
+	if opts.LabelSelector != "" || opts.FieldSelector != "" {
+		return nil, fmt.Errorf("%s: selectors are not supported by the legacy HTTP API", desc.Plural)
+	}
+
+	ep, err := c.endpoint(desc)
+	if err != nil {
+		return nil, err
+	}
+
+	models, err := ep.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := unstructured.UnstructuredList{
+		Items: make([]unstructured.Unstructured, 0, len(models)),
+	}
+
+	for _, model := range models {
+		res.Items = append(res.Items, *c.toObject(desc, ep, model))
+	}
+
+	return &res, nil
+}
+
+// GetMultiple gets multiple resources from the server.
+func (c *Client) GetMultiple(
+	ctx context.Context, desc resources.Descriptor, names []string, opts metav1.GetOptions,
+) ([]unstructured.Unstructured, error) {
+	res := make([]unstructured.Unstructured, 0, len(names))
+
+	for _, name := range names {
+		obj, err := c.Get(ctx, desc, name, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, *obj)
+	}
+
+	return res, nil
+}
+
+// Get gets a resource from the server.
+func (c *Client) Get(
+	ctx context.Context, desc resources.Descriptor, name string, _ metav1.GetOptions,
+) (*unstructured.Unstructured, error) {
+	ep, err := c.endpoint(desc)
+	if err != nil {
+		return nil, err
+	}
+
+	model, err := ep.get(ctx, name)
+	if err != nil {
+		return nil, parseError(desc, name, err)
+	}
+
+	return c.toObject(desc, ep, model), nil
+}
+
+// Watch is not supported by the legacy HTTP API.
+func (c *Client) Watch(context.Context, resources.Descriptor, metav1.ListOptions) (watch.Interface, error) {
+	return nil, ErrWatchNotSupported
+}
+
+// Create creates a resource on the server.
+// The legacy HTTP API does not support dry-runs: they are not sent to the server.
+func (c *Client) Create(
+	ctx context.Context, desc resources.Descriptor, obj *unstructured.Unstructured, opts metav1.CreateOptions,
+) (*unstructured.Unstructured, error) {
+	ep, err := c.endpoint(desc)
+	if err != nil {
+		return nil, err
+	}
+
+	model, err := toModel(ep, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(opts.DryRun) != 0 {
+		return obj, nil
+	}
+
+	if err := ep.create(ctx, obj.GetName(), model); err != nil {
+		return nil, parseError(desc, obj.GetName(), err)
+	}
+
+	return obj, nil
+}
+
+// Update updates a resource on the server.
+// The legacy HTTP API does not support dry-runs: they are not sent to the server.
+func (c *Client) Update(
+	ctx context.Context, desc resources.Descriptor, obj *unstructured.Unstructured, opts metav1.UpdateOptions,
+) (*unstructured.Unstructured, error) {
+	ep, err := c.endpoint(desc)
+	if err != nil {
+		return nil, err
+	}
+
+	model, err := toModel(ep, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(opts.DryRun) != 0 {
+		return obj, nil
+	}
+
+	if err := ep.update(ctx, obj.GetName(), model); err != nil {
+		return nil, parseError(desc, obj.GetName(), err)
+	}
+
+	return obj, nil
+}
+
+// Delete deletes a resource from the server.
+// The legacy HTTP API does not support dry-runs: they only check that the resource exists.
+func (c *Client) Delete(
+	ctx context.Context, desc resources.Descriptor, name string, opts metav1.DeleteOptions,
+) error {
+	ep, err := c.endpoint(desc)
+	if err != nil {
+		return err
+	}
+
+	if len(opts.DryRun) != 0 {
+		_, err := ep.get(ctx, name)
+		return parseError(desc, name, err)
+	}
+
+	return parseError(desc, name, ep.delete(ctx, name))
+}
+
+func (c *Client) endpoint(desc resources.Descriptor) (endpoint, error) {
+	ep, ok := c.endpoints[desc.Kind]
+	if !ok || !desc.IsLegacy() {
+		return nil, fmt.Errorf("%s is not served by the legacy HTTP API", desc.String())
+	}
+
+	return ep, nil
+}
+
+// toObject converts the JSON model of an object of the legacy HTTP API to a resource.
+func (c *Client) toObject(desc resources.Descriptor, ep endpoint, model map[string]any) *unstructured.Unstructured {
+	spec := maps.Clone(model)
+	name, _ := spec[ep.nameField()].(string)
+
+	delete(spec, ep.nameField())
+	for _, field := range ep.serverFields() {
+		delete(spec, field)
+	}
+
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": desc.GroupVersion.String(),
+		"kind":       desc.Kind,
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": c.namespace,
+		},
+		"spec": spec,
+	}}
+}
+
+// toModel converts a resource to the JSON model of an object of the legacy HTTP API.
+func toModel(ep endpoint, obj *unstructured.Unstructured) (map[string]any, error) {
+	if hasMetadata(obj) {
+		return nil, fmt.Errorf("%s/%s: %w", obj.GetKind(), obj.GetName(), ErrMetadataNotSupported)
+	}
+
+	spec, _, err := unstructured.NestedMap(obj.Object, "spec")
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec for %s/%s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	if spec == nil {
+		spec = make(map[string]any)
+	}
+
+	spec[ep.nameField()] = obj.GetName()
+
+	return spec, nil
+}
+
+// hasMetadata returns true if a resource has labels, or annotations other than
+// the manager and source properties set by grafanactl.
+func hasMetadata(obj *unstructured.Unstructured) bool {
+	if len(obj.GetLabels()) != 0 {
+		return true
+	}
+
+	for key := range obj.GetAnnotations() {
+		if !resources.IsReservedAnnotation(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseError converts "not found" errors of the legacy HTTP API
+// to their Kubernetes-style equivalent.
+func parseError(desc resources.Descriptor, name string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var coded interface{ IsCode(code int) bool }
+	if errors.Is(err, errNotFound) || (errors.As(err, &coded) && coded.IsCode(http.StatusNotFound)) {
+		return apierrors.NewNotFound(desc.GroupVersionResource().GroupResource(), name)
+	}
+
+	return fmt.Errorf("%s/%s: %w", desc.Kind, name, err)
+}
+
+// convert converts a value to another type through their JSON representation,
+// e.g. from a JSON model to a request body of the legacy HTTP API.
+func convert(from any, to any) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, to)
+}
+
+// toMap converts a value of the legacy HTTP API to its JSON model.
+func toMap(value any) (map[string]any, error) {
+	model := make(map[string]any)
+	if err := convert(value, &model); err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
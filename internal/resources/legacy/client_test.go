@@ -0,0 +1,260 @@
+package legacy_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-openapi/strfmt"
+	goapi "github.com/grafana/grafana-openapi-client-go/client"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/legacy"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type request struct {
+	method string
+	path   string
+	body   map[string]any
+}
+
+// newClient creates a legacy client for a server answering with the given routes,
+// keyed by "METHOD /path". Requests received by the server are recorded.
+func newClient(t *testing.T, routes map[string]string) (*legacy.Client, *[]request) {
+	t.Helper()
+
+	var requests []request
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received := request{method: r.Method, path: r.URL.Path}
+		if data, _ := io.ReadAll(r.Body); len(data) != 0 {
+			require.NoError(t, json.Unmarshal(data, &received.body))
+		}
+		requests = append(requests, received)
+
+		response, ok := routes[r.Method+" "+r.URL.Path]
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "not found"}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	api := goapi.NewHTTPClientWithConfig(strfmt.Default, &goapi.TransportConfig{
+		Host:     serverURL.Host,
+		BasePath: "/api",
+		Schemes:  []string{serverURL.Scheme},
+	})
+
+	return legacy.NewClient("default", api), &requests
+}
+
+func descriptor(t *testing.T, kind string) resources.Descriptor {
+	t.Helper()
+
+	for _, desc := range legacy.Descriptors() {
+		if desc.Kind == kind {
+			return desc
+		}
+	}
+
+	t.Fatalf("no legacy descriptor for %s", kind)
+
+	return resources.Descriptor{}
+}
+
+func TestClient_Get(t *testing.T) {
+	req := require.New(t)
+
+	client, _ := newClient(t, map[string]string{
+		"GET /api/datasources/uid/prom": `{
+			"id": 1, "uid": "prom", "orgId": 1, "version": 3, "name": "Prometheus",
+			"type": "prometheus", "url": "http://prometheus:9090", "jsonData": {"httpMethod": "POST"}
+		}`,
+	})
+
+	desc := descriptor(t, "DataSource")
+	req.True(desc.IsLegacy())
+
+	obj, err := client.Get(t.Context(), desc, "prom", metav1.GetOptions{})
+	req.NoError(err)
+
+	req.Equal(map[string]any{
+		"apiVersion": "legacy.grafana.app/v0",
+		"kind":       "DataSource",
+		"metadata": map[string]any{
+			"name":      "prom",
+			"namespace": "default",
+		},
+		"spec": map[string]any{
+			"name":     "Prometheus",
+			"type":     "prometheus",
+			"url":      "http://prometheus:9090",
+			"jsonData": map[string]any{"httpMethod": "POST"},
+		},
+	}, obj.Object)
+
+	_, err = client.Get(t.Context(), desc, "missing", metav1.GetOptions{})
+	req.True(apierrors.IsNotFound(err))
+}
+
+func TestClient_List(t *testing.T) {
+	req := require.New(t)
+
+	client, requests := newClient(t, map[string]string{
+		"GET /api/datasources": `[
+			{"id": 1, "uid": "prom", "orgId": 1, "name": "Prometheus", "type": "prometheus", "typeName": "Prometheus", "access": "proxy"},
+			{"id": 2, "uid": "loki", "orgId": 1, "name": "Loki", "type": "loki", "access": "proxy", "basicAuth": true}
+		]`,
+		"GET /api/datasources/uid/loki": `{
+			"id": 2, "uid": "loki", "orgId": 1, "version": 1, "name": "Loki", "type": "loki", "access": "proxy",
+			"basicAuth": true, "basicAuthUser": "admin"
+		}`,
+	})
+
+	list, err := client.List(t.Context(), descriptor(t, "DataSource"), metav1.ListOptions{})
+	req.NoError(err)
+	req.Len(list.Items, 2)
+
+	req.Equal(map[string]any{"name": "Prometheus", "type": "prometheus", "access": "proxy"}, list.Items[0].Object["spec"])
+	req.Equal(map[string]any{
+		"name": "Loki", "type": "loki", "access": "proxy", "basicAuth": true, "basicAuthUser": "admin",
+	}, list.Items[1].Object["spec"])
+
+	// Only the data sources whose summary lacks fields are fetched.
+	req.Len(*requests, 2)
+	req.Equal("/api/datasources/uid/loki", (*requests)[1].path)
+}
+
+func TestClient_Create(t *testing.T) {
+	req := require.New(t)
+
+	client, requests := newClient(t, map[string]string{
+		"POST /api/datasources": `{"id": 2, "message": "Datasource added"}`,
+	})
+
+	desc := descriptor(t, "DataSource")
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "legacy.grafana.app/v0",
+		"kind":       "DataSource",
+		"metadata":   map[string]any{"name": "loki"},
+		"spec": map[string]any{
+			"name": "Loki",
+			"type": "loki",
+		},
+	}}
+
+	// Dry-runs are not sent to the server.
+	_, err := client.Create(t.Context(), desc, obj, metav1.CreateOptions{DryRun: []string{"All"}})
+	req.NoError(err)
+	req.Empty(*requests)
+
+	_, err = client.Create(t.Context(), desc, obj, metav1.CreateOptions{})
+	req.NoError(err)
+
+	req.Len(*requests, 1)
+	req.Equal("POST", (*requests)[0].method)
+	req.Equal(map[string]any{"uid": "loki", "name": "Loki", "type": "loki"}, (*requests)[0].body)
+}
+
+func TestClient_UpdateByUID(t *testing.T) {
+	req := require.New(t)
+
+	client, requests := newClient(t, map[string]string{
+		"GET /api/teams/search": `{"totalCount": 2, "teams": [
+			{"id": 6, "uid": "xyz", "name": "dev", "email": "dev@example.com"},
+			{"id": 7, "uid": "abc", "name": "ops", "email": "old@example.com"}
+		]}`,
+		"PUT /api/teams/7": `{"message": "Team updated"}`,
+	})
+
+	desc := descriptor(t, "Team")
+
+	// Teams are identified by their UID, and can be renamed.
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "legacy.grafana.app/v0",
+		"kind":       "Team",
+		"metadata":   map[string]any{"name": "abc"},
+		"spec":       map[string]any{"name": "platform", "email": "ops@example.com"},
+	}}
+
+	_, err := client.Update(t.Context(), desc, obj, metav1.UpdateOptions{})
+	req.NoError(err)
+
+	req.Len(*requests, 2)
+	req.Equal("PUT", (*requests)[1].method)
+	req.Equal("/api/teams/7", (*requests)[1].path)
+	req.Equal(map[string]any{"name": "platform", "email": "ops@example.com"}, (*requests)[1].body)
+
+	err = client.Delete(t.Context(), desc, "missing", metav1.DeleteOptions{})
+	req.True(apierrors.IsNotFound(err))
+}
+
+func TestClient_Metadata(t *testing.T) {
+	req := require.New(t)
+
+	client, requests := newClient(t, map[string]string{
+		"POST /api/datasources": `{"id": 2, "message": "Datasource added"}`,
+	})
+
+	desc := descriptor(t, "DataSource")
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "legacy.grafana.app/v0",
+		"kind":       "DataSource",
+		"metadata": map[string]any{
+			"name":   "loki",
+			"labels": map[string]any{"env": "prod"},
+		},
+		"spec": map[string]any{"name": "Loki", "type": "loki"},
+	}}
+
+	// Labels and annotations are rejected, even in dry-runs.
+	_, err := client.Create(t.Context(), desc, obj, metav1.CreateOptions{DryRun: []string{"All"}})
+	req.ErrorIs(err, legacy.ErrMetadataNotSupported)
+
+	_, err = client.Update(t.Context(), desc, obj, metav1.UpdateOptions{})
+	req.ErrorIs(err, legacy.ErrMetadataNotSupported)
+	req.Empty(*requests)
+
+	// Manager and source annotations set by grafanactl are dropped.
+	obj.SetLabels(nil)
+	obj.SetAnnotations(map[string]string{
+		utils.AnnoKeyManagerKind:    "grafanactl",
+		utils.AnnoKeySourceChecksum: "abc",
+	})
+
+	_, err = client.Create(t.Context(), desc, obj, metav1.CreateOptions{})
+	req.NoError(err)
+	req.Len(*requests, 1)
+	req.Equal(map[string]any{"uid": "loki", "name": "Loki", "type": "loki"}, (*requests)[0].body)
+}
+
+func TestClient_Unsupported(t *testing.T) {
+	req := require.New(t)
+
+	client, _ := newClient(t, nil)
+
+	_, err := client.Watch(t.Context(), descriptor(t, "Team"), metav1.ListOptions{})
+	req.ErrorIs(err, legacy.ErrWatchNotSupported)
+
+	_, err = client.List(t.Context(), descriptor(t, "Team"), metav1.ListOptions{LabelSelector: "foo=bar"})
+	req.ErrorContains(err, "selectors are not supported")
+
+	_, err = client.Get(t.Context(), resources.Descriptor{Kind: "Dashboard"}, "foo", metav1.GetOptions{})
+	req.ErrorContains(err, "is not served by the legacy HTTP API")
+}
@@ -0,0 +1,579 @@
+package legacy
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	goapi "github.com/grafana/grafana-openapi-client-go/client"
+	"github.com/grafana/grafana-openapi-client-go/client/datasources"
+	"github.com/grafana/grafana-openapi-client-go/client/provisioning"
+	"github.com/grafana/grafana-openapi-client-go/client/service_accounts"
+	"github.com/grafana/grafana-openapi-client-go/client/teams"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grafanactl/internal/resources"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// errNotFound is returned by endpoints looking objects up by name, when no object matches.
+var errNotFound = errors.New("not found")
+
+// searchPageSize is the number of objects requested per page by paginated endpoints.
+const searchPageSize int64 = 1000
+
+// kind describes a kind of objects served by the legacy HTTP API.
+type kind struct {
+	kind     string
+	singular string
+	plural   string
+}
+
+func (k kind) descriptor() resources.Descriptor {
+	return resources.Descriptor{
+		GroupVersion: schema.GroupVersion{Group: resources.LegacyGroup, Version: Version},
+		Kind:         k.kind,
+		Singular:     k.singular,
+		Plural:       k.plural,
+	}
+}
+
+//nolint:gochecknoglobals
+var kinds = []kind{
+	{kind: "AlertRule", singular: "alertrule", plural: "alertrules"},
+	{kind: "ContactPoint", singular: "contactpoint", plural: "contactpoints"},
+	{kind: "DataSource", singular: "datasource", plural: "datasources"},
+	{kind: "ServiceAccount", singular: "serviceaccount", plural: "serviceaccounts"},
+	{kind: "Team", singular: "team", plural: "teams"},
+}
+
+// endpoint gives access to the objects of a kind through the legacy HTTP API.
+// Objects are manipulated as JSON models.
+type endpoint interface {
+	// nameField is the field of the model identifying objects.
+	nameField() string
+	// serverFields are the fields of the model managed by the server.
+	serverFields() []string
+
+	list(ctx context.Context) ([]map[string]any, error)
+	get(ctx context.Context, name string) (map[string]any, error)
+	create(ctx context.Context, name string, model map[string]any) error
+	update(ctx context.Context, name string, model map[string]any) error
+	delete(ctx context.Context, name string) error
+}
+
+func newEndpoints(api *goapi.GrafanaHTTPAPI) map[string]endpoint {
+	return map[string]endpoint{
+		"AlertRule":      alertRules{api: api},
+		"ContactPoint":   contactPoints{api: api},
+		"DataSource":     dataSources{api: api},
+		"ServiceAccount": serviceAccounts{api: api},
+		"Team":           teamsEndpoint{api: api},
+	}
+}
+
+// disableProvenance keeps alerting resources editable in the UI once provisioned.
+func disableProvenance() *string {
+	value := "true"
+	return &value
+}
+
+// dataSources are identified by their UID.
+type dataSources struct {
+	api *goapi.GrafanaHTTPAPI
+}
+
+func (e dataSources) nameField() string {
+	return "uid"
+}
+
+func (e dataSources) serverFields() []string {
+	return []string{
+		"id", "orgId", "version", "typeName", "typeLogoUrl", "readOnly", "accessControl", "secureJsonFields",
+	}
+}
+
+func (e dataSources) list(ctx context.Context) ([]map[string]any, error) {
+	resp, err := e.api.Datasources.GetDataSourcesWithParams(datasources.NewGetDataSourcesParams().WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]map[string]any, 0, len(resp.Payload))
+	for _, item := range resp.Payload {
+		// The list gives a summary of data sources, lacking the basic auth user and
+		// the credentials of browser access: only those data sources are fetched.
+		if item.BasicAuth || item.Access == "direct" {
+			model, err := e.get(ctx, item.UID)
+			if err != nil {
+				return nil, err
+			}
+
+			res = append(res, model)
+			continue
+		}
+
+		model, err := toMap(item)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, model)
+	}
+
+	return res, nil
+}
+
+func (e dataSources) get(ctx context.Context, name string) (map[string]any, error) {
+	resp, err := e.api.Datasources.GetDataSourceByUIDWithParams(
+		datasources.NewGetDataSourceByUIDParams().WithContext(ctx).WithUID(name),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return toMap(resp.Payload)
+}
+
+func (e dataSources) create(ctx context.Context, _ string, model map[string]any) error {
+	body := &models.AddDataSourceCommand{}
+	if err := convert(model, body); err != nil {
+		return err
+	}
+
+	_, err := e.api.Datasources.AddDataSourceWithParams(
+		datasources.NewAddDataSourceParams().WithContext(ctx).WithBody(body),
+	)
+
+	return err
+}
+
+func (e dataSources) update(ctx context.Context, name string, model map[string]any) error {
+	body := &models.UpdateDataSourceCommand{}
+	if err := convert(model, body); err != nil {
+		return err
+	}
+
+	_, err := e.api.Datasources.UpdateDataSourceByUIDWithParams(
+		datasources.NewUpdateDataSourceByUIDParams().WithContext(ctx).WithUID(name).WithBody(body),
+	)
+
+	return err
+}
+
+func (e dataSources) delete(ctx context.Context, name string) error {
+	_, err := e.api.Datasources.DeleteDataSourceByUIDWithParams(
+		datasources.NewDeleteDataSourceByUIDParams().WithContext(ctx).WithUID(name),
+	)
+
+	return err
+}
+
+// alertRules are identified by their UID.
+type alertRules struct {
+	api *goapi.GrafanaHTTPAPI
+}
+
+func (e alertRules) nameField() string {
+	return "uid"
+}
+
+func (e alertRules) serverFields() []string {
+	return []string{"id", "orgID", "updated", "provenance"}
+}
+
+func (e alertRules) list(ctx context.Context) ([]map[string]any, error) {
+	resp, err := e.api.Provisioning.GetAlertRulesWithParams(provisioning.NewGetAlertRulesParams().WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]map[string]any, 0, len(resp.Payload))
+	for _, rule := range resp.Payload {
+		model, err := toMap(rule)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, model)
+	}
+
+	return res, nil
+}
+
+func (e alertRules) get(ctx context.Context, name string) (map[string]any, error) {
+	resp, err := e.api.Provisioning.GetAlertRuleWithParams(
+		provisioning.NewGetAlertRuleParams().WithContext(ctx).WithUID(name),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return toMap(resp.Payload)
+}
+
+func (e alertRules) create(ctx context.Context, _ string, model map[string]any) error {
+	body := &models.ProvisionedAlertRule{}
+	if err := convert(model, body); err != nil {
+		return err
+	}
+
+	_, err := e.api.Provisioning.PostAlertRule(
+		provisioning.NewPostAlertRuleParams().WithContext(ctx).WithBody(body).WithXDisableProvenance(disableProvenance()),
+	)
+
+	return err
+}
+
+func (e alertRules) update(ctx context.Context, name string, model map[string]any) error {
+	body := &models.ProvisionedAlertRule{}
+	if err := convert(model, body); err != nil {
+		return err
+	}
+
+	_, err := e.api.Provisioning.PutAlertRule(
+		provisioning.NewPutAlertRuleParams().WithContext(ctx).WithUID(name).WithBody(body).
+			WithXDisableProvenance(disableProvenance()),
+	)
+
+	return err
+}
+
+func (e alertRules) delete(ctx context.Context, name string) error {
+	_, err := e.api.Provisioning.DeleteAlertRule(
+		provisioning.NewDeleteAlertRuleParams().WithContext(ctx).WithUID(name).WithXDisableProvenance(disableProvenance()),
+	)
+
+	return err
+}
+
+// contactPoints are identified by their UID.
+type contactPoints struct {
+	api *goapi.GrafanaHTTPAPI
+}
+
+func (e contactPoints) nameField() string {
+	return "uid"
+}
+
+func (e contactPoints) serverFields() []string {
+	return []string{"provenance"}
+}
+
+func (e contactPoints) list(ctx context.Context) ([]map[string]any, error) {
+	resp, err := e.api.Provisioning.GetContactpoints(provisioning.NewGetContactpointsParams().WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]map[string]any, 0, len(resp.Payload))
+	for _, point := range resp.Payload {
+		model, err := toMap(point)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, model)
+	}
+
+	return res, nil
+}
+
+// get looks the contact point up in the list: contact points can't be fetched by UID.
+func (e contactPoints) get(ctx context.Context, name string) (map[string]any, error) {
+	points, err := e.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, point := range points {
+		if point["uid"] == name {
+			return point, nil
+		}
+	}
+
+	return nil, errNotFound
+}
+
+func (e contactPoints) create(ctx context.Context, _ string, model map[string]any) error {
+	body := &models.EmbeddedContactPoint{}
+	if err := convert(model, body); err != nil {
+		return err
+	}
+
+	_, err := e.api.Provisioning.PostContactpoints(
+		provisioning.NewPostContactpointsParams().WithContext(ctx).WithBody(body).WithXDisableProvenance(disableProvenance()),
+	)
+
+	return err
+}
+
+func (e contactPoints) update(ctx context.Context, name string, model map[string]any) error {
+	body := &models.EmbeddedContactPoint{}
+	if err := convert(model, body); err != nil {
+		return err
+	}
+
+	_, err := e.api.Provisioning.PutContactpoint(
+		provisioning.NewPutContactpointParams().WithContext(ctx).WithUID(name).WithBody(body).
+			WithXDisableProvenance(disableProvenance()),
+	)
+
+	return err
+}
+
+func (e contactPoints) delete(ctx context.Context, name string) error {
+	_, err := e.api.Provisioning.DeleteContactpointsWithParams(
+		provisioning.NewDeleteContactpointsParams().WithContext(ctx).WithUID(name),
+	)
+
+	return err
+}
+
+// teamsEndpoint gives access to teams, identified by their UID: unlike their
+// name, it doesn't change when teams are renamed. The UID of teams is generated
+// by the server: new teams get the UID chosen by Grafana, not the resource name.
+type teamsEndpoint struct {
+	api *goapi.GrafanaHTTPAPI
+}
+
+func (e teamsEndpoint) nameField() string {
+	return "uid"
+}
+
+func (e teamsEndpoint) serverFields() []string {
+	return []string{
+		"id", "orgId", "memberCount", "avatarUrl", "accessControl", "permission", "isProvisioned", "externalUID",
+	}
+}
+
+func (e teamsEndpoint) list(ctx context.Context) ([]map[string]any, error) {
+	found, err := e.search(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]map[string]any, 0, len(found))
+	for _, team := range found {
+		model, err := toMap(team)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, model)
+	}
+
+	return res, nil
+}
+
+func (e teamsEndpoint) get(ctx context.Context, name string) (map[string]any, error) {
+	team, err := e.lookup(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return toMap(team)
+}
+
+func (e teamsEndpoint) create(ctx context.Context, _ string, model map[string]any) error {
+	body := &models.CreateTeamCommand{}
+	if err := convert(model, body); err != nil {
+		return err
+	}
+
+	_, err := e.api.Teams.CreateTeamWithParams(teams.NewCreateTeamParams().WithContext(ctx).WithBody(body))
+
+	return err
+}
+
+func (e teamsEndpoint) update(ctx context.Context, name string, model map[string]any) error {
+	team, err := e.lookup(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	body := &models.UpdateTeamCommand{}
+	if err := convert(model, body); err != nil {
+		return err
+	}
+
+	_, err = e.api.Teams.UpdateTeamWithParams(
+		teams.NewUpdateTeamParams().WithContext(ctx).WithTeamID(strconv.FormatInt(*team.ID, 10)).WithBody(body),
+	)
+
+	return err
+}
+
+func (e teamsEndpoint) delete(ctx context.Context, name string) error {
+	team, err := e.lookup(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.api.Teams.DeleteTeamByIDWithParams(
+		teams.NewDeleteTeamByIDParams().WithContext(ctx).WithTeamID(strconv.FormatInt(*team.ID, 10)),
+	)
+
+	return err
+}
+
+// lookup looks the team up in the list: teams can't be searched by UID.
+func (e teamsEndpoint) lookup(ctx context.Context, uid string) (*models.TeamDTO, error) {
+	found, err := e.search(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, team := range found {
+		if team.UID != nil && *team.UID == uid && team.ID != nil {
+			return team, nil
+		}
+	}
+
+	return nil, errNotFound
+}
+
+// search returns all the teams.
+func (e teamsEndpoint) search(ctx context.Context) ([]*models.TeamDTO, error) {
+	var found []*models.TeamDTO
+
+	perPage := searchPageSize
+	for page := int64(1); ; page++ {
+		resp, err := e.api.Teams.SearchTeams(
+			teams.NewSearchTeamsParams().WithContext(ctx).WithPage(&page).WithPerpage(&perPage),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		found = append(found, resp.Payload.Teams...)
+
+		if int64(len(resp.Payload.Teams)) < perPage || int64(len(found)) >= resp.Payload.TotalCount {
+			return found, nil
+		}
+	}
+}
+
+// serviceAccounts are identified by their UID: unlike their name, it doesn't
+// change when service accounts are renamed. The UID of service accounts is generated
+// by the server: new service accounts get the UID chosen by Grafana, not the resource name.
+type serviceAccounts struct {
+	api *goapi.GrafanaHTTPAPI
+}
+
+func (e serviceAccounts) nameField() string {
+	return "uid"
+}
+
+func (e serviceAccounts) serverFields() []string {
+	return []string{"id", "orgId", "login", "avatarUrl", "tokens", "accessControl", "isExternal"}
+}
+
+func (e serviceAccounts) list(ctx context.Context) ([]map[string]any, error) {
+	found, err := e.search(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]map[string]any, 0, len(found))
+	for _, account := range found {
+		model, err := toMap(account)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, model)
+	}
+
+	return res, nil
+}
+
+func (e serviceAccounts) get(ctx context.Context, name string) (map[string]any, error) {
+	account, err := e.lookup(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return toMap(account)
+}
+
+func (e serviceAccounts) create(ctx context.Context, _ string, model map[string]any) error {
+	body := &models.CreateServiceAccountForm{}
+	if err := convert(model, body); err != nil {
+		return err
+	}
+
+	_, err := e.api.ServiceAccounts.CreateServiceAccount(
+		service_accounts.NewCreateServiceAccountParams().WithContext(ctx).WithBody(body),
+	)
+
+	return err
+}
+
+func (e serviceAccounts) update(ctx context.Context, name string, model map[string]any) error {
+	account, err := e.lookup(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	body := &models.UpdateServiceAccountForm{}
+	if err := convert(model, body); err != nil {
+		return err
+	}
+
+	_, err = e.api.ServiceAccounts.UpdateServiceAccount(
+		service_accounts.NewUpdateServiceAccountParams().WithContext(ctx).WithServiceAccountID(account.ID).WithBody(body),
+	)
+
+	return err
+}
+
+func (e serviceAccounts) delete(ctx context.Context, name string) error {
+	account, err := e.lookup(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.api.ServiceAccounts.DeleteServiceAccountWithParams(
+		service_accounts.NewDeleteServiceAccountParams().WithContext(ctx).WithServiceAccountID(account.ID),
+	)
+
+	return err
+}
+
+// lookup looks the service account up in the list: service accounts can't be searched by UID.
+func (e serviceAccounts) lookup(ctx context.Context, uid string) (*models.ServiceAccountDTO, error) {
+	found, err := e.search(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, account := range found {
+		if account.UID == uid {
+			return account, nil
+		}
+	}
+
+	return nil, errNotFound
+}
+
+// search returns all the service accounts.
+func (e serviceAccounts) search(ctx context.Context) ([]*models.ServiceAccountDTO, error) {
+	var found []*models.ServiceAccountDTO
+
+	perPage := searchPageSize
+	for page := int64(1); ; page++ {
+		resp, err := e.api.ServiceAccounts.SearchOrgServiceAccountsWithPaging(
+			service_accounts.NewSearchOrgServiceAccountsWithPagingParams().WithContext(ctx).
+				WithPage(&page).WithPerpage(&perPage),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		found = append(found, resp.Payload.ServiceAccounts...)
+
+		if int64(len(resp.Payload.ServiceAccounts)) < perPage || int64(len(found)) >= resp.Payload.TotalCount {
+			return found, nil
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package legacy
+
+import (
+	"context"
+
+	"github.com/grafana/grafanactl/internal/resources"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// ResourceClient is a client for the resources of Grafana.
+type ResourceClient interface {
+	Get(
+		ctx context.Context, desc resources.Descriptor, name string, opts metav1.GetOptions,
+	) (*unstructured.Unstructured, error)
+
+	GetMultiple(
+		ctx context.Context, desc resources.Descriptor, names []string, opts metav1.GetOptions,
+	) ([]unstructured.Unstructured, error)
+
+	List(
+		ctx context.Context, desc resources.Descriptor, opts metav1.ListOptions,
+	) (*unstructured.UnstructuredList, error)
+
+	Watch(
+		ctx context.Context, desc resources.Descriptor, opts metav1.ListOptions,
+	) (watch.Interface, error)
+
+	Create(
+		ctx context.Context, desc resources.Descriptor, obj *unstructured.Unstructured, opts metav1.CreateOptions,
+	) (*unstructured.Unstructured, error)
+
+	Update(
+		ctx context.Context, desc resources.Descriptor, obj *unstructured.Unstructured, opts metav1.UpdateOptions,
+	) (*unstructured.Unstructured, error)
+
+	Delete(ctx context.Context, desc resources.Descriptor, name string, opts metav1.DeleteOptions) error
+}
+
+// Router is a client sending requests for the resources served by the legacy
+// HTTP API to the legacy client, and all the other requests to the
+// Kubernetes-style API client.
+type Router struct {
+	kubernetes ResourceClient
+	legacy     ResourceClient
+}
+
+// NewRouter creates a new Router.
+func NewRouter(kubernetes ResourceClient, legacy ResourceClient) *Router {
+	return &Router{
+		kubernetes: kubernetes,
+		legacy:     legacy,
+	}
+}
+
+func (r *Router) client(desc resources.Descriptor) ResourceClient {
+	if desc.IsLegacy() {
+		return r.legacy
+	}
+
+	return r.kubernetes
+}
+
+// Get gets a resource from the server.
+func (r *Router) Get(
+	ctx context.Context, desc resources.Descriptor, name string, opts metav1.GetOptions,
+) (*unstructured.Unstructured, error) {
+	return r.client(desc).Get(ctx, desc, name, opts)
+}
+
+// GetMultiple gets multiple resources from the server.
+func (r *Router) GetMultiple(
+	ctx context.Context, desc resources.Descriptor, names []string, opts metav1.GetOptions,
+) ([]unstructured.Unstructured, error) {
+	return r.client(desc).GetMultiple(ctx, desc, names, opts)
+}
+
+// List lists resources from the server.
+func (r *Router) List(
+	ctx context.Context, desc resources.Descriptor, opts metav1.ListOptions,
+) (*unstructured.UnstructuredList, error) {
+	return r.client(desc).List(ctx, desc, opts)
+}
+
+// Watch watches resources on the server.
+func (r *Router) Watch(
+	ctx context.Context, desc resources.Descriptor, opts metav1.ListOptions,
+) (watch.Interface, error) {
+	return r.client(desc).Watch(ctx, desc, opts)
+}
+
+// Create creates a resource on the server.
+func (r *Router) Create(
+	ctx context.Context, desc resources.Descriptor, obj *unstructured.Unstructured, opts metav1.CreateOptions,
+) (*unstructured.Unstructured, error) {
+	return r.client(desc).Create(ctx, desc, obj, opts)
+}
+
+// Update updates a resource on the server.
+func (r *Router) Update(
+	ctx context.Context, desc resources.Descriptor, obj *unstructured.Unstructured, opts metav1.UpdateOptions,
+) (*unstructured.Unstructured, error) {
+	return r.client(desc).Update(ctx, desc, obj, opts)
+}
+
+// Delete deletes a resource from the server.
+func (r *Router) Delete(ctx context.Context, desc resources.Descriptor, name string, opts metav1.DeleteOptions) error {
+	return r.client(desc).Delete(ctx, desc, name, opts)
+}
@@ -0,0 +1,160 @@
+package lint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/grafana/grafanactl/internal/resources"
+)
+
+// CustomRule defines a rule using a CEL expression.
+//
+// The expression is evaluated for every resource matched by Match, and must
+// return true if the resource follows the rule. The following variables are
+// available:
+//   - object: the resource being checked.
+//   - resources: the list of all the resources being linted.
+type CustomRule struct {
+	ID          string `json:"id" yaml:"id"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Severity of the findings. Defaults to "error".
+	Severity Severity `json:"severity,omitempty" yaml:"severity,omitempty"`
+	// Match selects the resources to check. All resources are checked if not set.
+	Match *Match `json:"match,omitempty" yaml:"match,omitempty"`
+	// Expr is the CEL expression evaluated against the resources.
+	Expr string `json:"expr" yaml:"expr"`
+	// Message of the findings. Defaults to the description of the rule.
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// Match selects resources. Empty fields match every resource.
+type Match struct {
+	Group string `json:"group,omitempty" yaml:"group,omitempty"`
+	Kind  string `json:"kind,omitempty" yaml:"kind,omitempty"`
+}
+
+// Matches returns true if the resource is selected.
+func (m *Match) Matches(res *resources.Resource) bool {
+	if m == nil {
+		return true
+	}
+
+	if m.Group != "" && m.Group != res.Group() {
+		return false
+	}
+
+	return m.Kind == "" || m.Kind == res.Kind()
+}
+
+type celRule struct {
+	definition CustomRule
+	program    cel.Program
+}
+
+// NewCELRule compiles a custom rule.
+func NewCELRule(definition CustomRule) (Rule, error) { //nolint:ireturn
+	if definition.ID == "" {
+		return nil, errors.New("custom rules require an id")
+	}
+
+	if definition.Expr == "" {
+		return nil, fmt.Errorf("custom rule '%s': an expression is required", definition.ID)
+	}
+
+	if definition.Severity == "" {
+		definition.Severity = SeverityError
+	}
+
+	if err := definition.Severity.Validate(); err != nil {
+		return nil, fmt.Errorf("custom rule '%s': %w", definition.ID, err)
+	}
+
+	env, err := cel.NewEnv(
+		cel.Variable("object", cel.DynType),
+		cel.Variable("resources", cel.ListType(cel.DynType)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Compile(definition.Expr)
+	if issues.Err() != nil {
+		return nil, fmt.Errorf("custom rule '%s': invalid expression: %w", definition.ID, issues.Err())
+	}
+
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("custom rule '%s': the expression must return a boolean, not %s", definition.ID, ast.OutputType())
+	}
+
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("custom rule '%s': %w", definition.ID, err)
+	}
+
+	return &celRule{definition: definition, program: program}, nil
+}
+
+func (rule *celRule) ID() string {
+	return rule.definition.ID
+}
+
+func (rule *celRule) Description() string {
+	return rule.definition.Description
+}
+
+func (rule *celRule) Severity() Severity {
+	return rule.definition.Severity
+}
+
+func (rule *celRule) Check(ctx context.Context, res *resources.Resources) ([]Finding, error) {
+	list := res.AsList()
+
+	objects := make([]any, 0, len(list))
+	for _, item := range list {
+		objects = append(objects, item.Object.Object)
+	}
+
+	message := rule.definition.Message
+	if message == "" {
+		message = rule.definition.Description
+	}
+	if message == "" {
+		message = "the expression evaluated to false"
+	}
+
+	var findings []Finding
+
+	for _, item := range list {
+		if !rule.definition.Match.Matches(item) {
+			continue
+		}
+
+		out, _, err := rule.program.ContextEval(ctx, map[string]any{
+			"object":    item.Object.Object,
+			"resources": objects,
+		})
+		if err != nil {
+			findings = append(findings, Finding{
+				Resource: item,
+				Message:  fmt.Sprintf("could not evaluate expression: %s", err),
+			})
+			continue
+		}
+
+		valid, ok := out.Value().(bool)
+		if !ok {
+			return nil, fmt.Errorf("the expression must return a boolean, not %s", out.Type())
+		}
+
+		if !valid {
+			findings = append(findings, Finding{
+				Resource: item,
+				Message:  message,
+			})
+		}
+	}
+
+	return findings, nil
+}
@@ -0,0 +1,66 @@
+package lint
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/grafana/grafanactl/internal/format"
+)
+
+// Config configures the linter.
+//
+// Example:
+//
+//	rules:
+//	  dashboard-tags:
+//	    severity: error
+//	  dashboard-unique-title:
+//	    enabled: false
+//	customRules:
+//	  - id: dashboard-refresh
+//	    description: Dashboards must not refresh more often than every minute
+//	    match:
+//	      kind: Dashboard
+//	    expr: "!has(object.spec.refresh) || object.spec.refresh in ['', '1m', '5m', '15m']"
+//	    message: Refresh interval is too short
+type Config struct {
+	// Rules configures rules, by ID.
+	Rules map[string]RuleConfig `json:"rules,omitempty" yaml:"rules,omitempty"`
+	// CustomRules defines additional rules.
+	CustomRules []CustomRule `json:"customRules,omitempty" yaml:"customRules,omitempty"`
+}
+
+// RuleConfig configures a single rule.
+type RuleConfig struct {
+	// Enabled can be used to disable a rule. Rules are enabled by default.
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	// Severity overrides the default severity of the rule.
+	Severity Severity `json:"severity,omitempty" yaml:"severity,omitempty"`
+}
+
+// Enable enables or disables the rule with the given ID.
+func (cfg *Config) Enable(id string, enabled bool) {
+	if cfg.Rules == nil {
+		cfg.Rules = make(map[string]RuleConfig)
+	}
+
+	ruleCfg := cfg.Rules[id]
+	ruleCfg.Enabled = &enabled
+	cfg.Rules[id] = ruleCfg
+}
+
+// LoadConfig loads the linter configuration from the given file.
+func LoadConfig(file string) (Config, error) {
+	handle, err := os.Open(file)
+	if err != nil {
+		return Config{}, err
+	}
+	defer handle.Close()
+
+	cfg := Config{}
+	if err := format.NewYAMLCodec().Decode(handle, &cfg); err != nil {
+		return Config{}, fmt.Errorf("invalid lint configuration '%s': %w", file, err)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,259 @@
+package lint
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafanactl/internal/resources"
+)
+
+const dashboardGroup = "dashboard.grafana.app"
+
+func builtinRules() []Rule {
+	return []Rule{
+		&dashboardFolderRule{},
+		&dashboardDatasourceRule{},
+		&dashboardTagsRule{},
+		&dashboardPanelIDsRule{},
+		&dashboardTitleRule{},
+	}
+}
+
+// dashboardFolderRule ensures that dashboards live in a folder.
+type dashboardFolderRule struct{}
+
+func (rule *dashboardFolderRule) ID() string { return "dashboard-folder" }
+
+func (rule *dashboardFolderRule) Description() string {
+	return "Dashboards must live in a folder"
+}
+
+func (rule *dashboardFolderRule) Severity() Severity { return SeverityError }
+
+func (rule *dashboardFolderRule) Check(_ context.Context, res *resources.Resources) ([]Finding, error) {
+	var findings []Finding
+
+	for _, dashboard := range dashboards(res) {
+		if dashboard.GetFolder() != "" {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Resource: dashboard,
+			Path:     "$.metadata",
+			Message:  fmt.Sprintf("dashboard is not in a folder: set the '%s' annotation", utils.AnnoKeyFolder),
+		})
+	}
+
+	return findings, nil
+}
+
+// dashboardDatasourceRule ensures that dashboards reference datasources by
+// UID or through template variables, rather than by name.
+type dashboardDatasourceRule struct{}
+
+func (rule *dashboardDatasourceRule) ID() string { return "dashboard-datasource-ref" }
+
+func (rule *dashboardDatasourceRule) Description() string {
+	return "Dashboards must not use hardcoded datasource names"
+}
+
+func (rule *dashboardDatasourceRule) Severity() Severity { return SeverityError }
+
+func (rule *dashboardDatasourceRule) Check(_ context.Context, res *resources.Resources) ([]Finding, error) {
+	var findings []Finding
+
+	for _, dashboard := range dashboards(res) {
+		walk(dashboardSpec(dashboard), "$.spec", func(path string, key string, value any) {
+			name, ok := value.(string)
+			if key != "datasource" || !ok || isDatasourceVariable(name) {
+				return
+			}
+
+			findings = append(findings, Finding{
+				Resource: dashboard,
+				Path:     path,
+				Message:  fmt.Sprintf("datasource '%s' is referenced by name: use its UID or a template variable", name),
+			})
+		})
+	}
+
+	return findings, nil
+}
+
+func isDatasourceVariable(name string) bool {
+	// Built-in datasources are referenced as "-- Grafana --", "-- Mixed --", ...
+	return name == "" || strings.HasPrefix(name, "$") || strings.HasPrefix(name, "-- ")
+}
+
+// dashboardTagsRule ensures that dashboards have tags.
+type dashboardTagsRule struct{}
+
+func (rule *dashboardTagsRule) ID() string { return "dashboard-tags" }
+
+func (rule *dashboardTagsRule) Description() string {
+	return "Dashboards must have tags"
+}
+
+func (rule *dashboardTagsRule) Severity() Severity { return SeverityWarning }
+
+func (rule *dashboardTagsRule) Check(_ context.Context, res *resources.Resources) ([]Finding, error) {
+	var findings []Finding
+
+	for _, dashboard := range dashboards(res) {
+		tags, _ := dashboardSpec(dashboard)["tags"].([]any)
+		if len(tags) != 0 {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Resource: dashboard,
+			Path:     "$.spec.tags",
+			Message:  "dashboard has no tags",
+		})
+	}
+
+	return findings, nil
+}
+
+// dashboardPanelIDsRule ensures that panel IDs are unique within a dashboard.
+type dashboardPanelIDsRule struct{}
+
+func (rule *dashboardPanelIDsRule) ID() string { return "dashboard-unique-panel-ids" }
+
+func (rule *dashboardPanelIDsRule) Description() string {
+	return "Dashboards must not reuse panel IDs"
+}
+
+func (rule *dashboardPanelIDsRule) Severity() Severity { return SeverityError }
+
+func (rule *dashboardPanelIDsRule) Check(_ context.Context, res *resources.Resources) ([]Finding, error) {
+	var findings []Finding
+
+	for _, dashboard := range dashboards(res) {
+		seen := make(map[string]string)
+
+		forEachPanel(dashboardSpec(dashboard), "$.spec", func(path string, panel map[string]any) {
+			id, ok := panel["id"]
+			if !ok {
+				return
+			}
+
+			key := fmt.Sprint(id)
+			if first, exists := seen[key]; exists {
+				findings = append(findings, Finding{
+					Resource: dashboard,
+					Path:     path + ".id",
+					Message:  fmt.Sprintf("panel ID %s is already used by the panel at %s", key, first),
+				})
+				return
+			}
+
+			seen[key] = path
+		})
+	}
+
+	return findings, nil
+}
+
+// dashboardTitleRule ensures that dashboard titles are unique within a folder.
+type dashboardTitleRule struct{}
+
+func (rule *dashboardTitleRule) ID() string { return "dashboard-unique-title" }
+
+func (rule *dashboardTitleRule) Description() string {
+	return "Dashboards must have unique titles within a folder"
+}
+
+func (rule *dashboardTitleRule) Severity() Severity { return SeverityError }
+
+func (rule *dashboardTitleRule) Check(_ context.Context, res *resources.Resources) ([]Finding, error) {
+	var findings []Finding
+
+	list := dashboards(res)
+	slices.SortFunc(list, func(a, b *resources.Resource) int {
+		return cmp.Compare(a.Name(), b.Name())
+	})
+
+	type folderTitle struct {
+		folder string
+		title  string
+	}
+
+	seen := make(map[folderTitle]string)
+	for _, dashboard := range list {
+		title, _ := dashboardSpec(dashboard)["title"].(string)
+		if title == "" {
+			continue
+		}
+
+		key := folderTitle{folder: dashboard.GetFolder(), title: title}
+		if first, exists := seen[key]; exists {
+			findings = append(findings, Finding{
+				Resource: dashboard,
+				Path:     "$.spec.title",
+				Message:  fmt.Sprintf("title '%s' is already used by dashboard '%s' in the same folder", title, first),
+			})
+			continue
+		}
+
+		seen[key] = dashboard.Name()
+	}
+
+	return findings, nil
+}
+
+func dashboards(res *resources.Resources) []*resources.Resource {
+	var list []*resources.Resource
+
+	for _, item := range res.AsList() {
+		if item.Group() == dashboardGroup && item.Kind() == "Dashboard" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+func dashboardSpec(dashboard *resources.Resource) map[string]any {
+	spec, _ := dashboard.Object.Object["spec"].(map[string]any)
+
+	return spec
+}
+
+// forEachPanel calls fn for each panel of the dashboard, including the panels
+// nested in collapsed rows.
+func forEachPanel(container map[string]any, path string, fn func(path string, panel map[string]any)) {
+	panels, _ := container["panels"].([]any)
+
+	for i, item := range panels {
+		panel, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		panelPath := fmt.Sprintf("%s.panels[%d]", path, i)
+		fn(panelPath, panel)
+		forEachPanel(panel, panelPath, fn)
+	}
+}
+
+// walk calls fn for every field of the given value, recursively.
+func walk(value any, path string, fn func(path string, key string, value any)) {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, child := range typed {
+			childPath := path + "." + key
+			fn(childPath, key, child)
+			walk(child, childPath, fn)
+		}
+	case []any:
+		for i, child := range typed {
+			walk(child, fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+	}
+}
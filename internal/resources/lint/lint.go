@@ -0,0 +1,249 @@
+package lint
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/local"
+)
+
+// Severity describes how important a finding is.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Validate returns an error if the severity is unknown.
+func (s Severity) Validate() error {
+	switch s {
+	case SeverityError, SeverityWarning, SeverityInfo:
+		return nil
+	default:
+		return fmt.Errorf("invalid severity '%s': expected one of %s, %s or %s", s, SeverityError, SeverityWarning, SeverityInfo)
+	}
+}
+
+// Finding describes a resource that does not follow a rule.
+type Finding struct {
+	// Rule is the ID of the rule that reported the finding.
+	// Set by the linter.
+	Rule string
+	// Severity of the finding. Set by the linter.
+	Severity Severity
+	// Resource is the resource that does not follow the rule.
+	Resource *resources.Resource
+	// Path is the JSON path of the offending field, if any. Example: $.spec.tags
+	Path string
+	// Line is the 1-based line of the offending field in the source file of
+	// the resource. Set by the linter, 0 when unknown.
+	Line int
+	// Message describes the finding.
+	Message string
+}
+
+// Rule checks that resources follow a convention.
+type Rule interface {
+	// ID uniquely identifies the rule. Example: dashboard-tags
+	ID() string
+	// Description describes the convention enforced by the rule.
+	Description() string
+	// Severity is the default severity of the findings reported by the rule.
+	Severity() Severity
+	// Check returns a finding for every resource that doesn't follow the rule.
+	Check(ctx context.Context, resources *resources.Resources) ([]Finding, error)
+}
+
+// Registry holds the rules known to the linter.
+type Registry struct {
+	rules map[string]Rule
+}
+
+// NewRegistry creates a registry holding the given rules.
+func NewRegistry(rules ...Rule) (*Registry, error) {
+	registry := &Registry{
+		rules: make(map[string]Rule, len(rules)),
+	}
+
+	for _, rule := range rules {
+		if err := registry.Register(rule); err != nil {
+			return nil, err
+		}
+	}
+
+	return registry, nil
+}
+
+// NewDefaultRegistry creates a registry holding the built-in rules.
+func NewDefaultRegistry() *Registry {
+	registry, err := NewRegistry(builtinRules()...)
+	if err != nil {
+		// Built-in rules are guaranteed to have unique IDs.
+		panic(err)
+	}
+
+	return registry
+}
+
+// Register adds a rule to the registry.
+func (registry *Registry) Register(rule Rule) error {
+	if _, exists := registry.rules[rule.ID()]; exists {
+		return fmt.Errorf("rule '%s' is already registered", rule.ID())
+	}
+
+	registry.rules[rule.ID()] = rule
+
+	return nil
+}
+
+// Get returns the rule with the given ID.
+func (registry *Registry) Get(id string) (Rule, bool) {
+	rule, ok := registry.rules[id]
+	return rule, ok
+}
+
+// Rules returns all the rules of the registry, sorted by ID.
+func (registry *Registry) Rules() []Rule {
+	rules := make([]Rule, 0, len(registry.rules))
+	for _, rule := range registry.rules {
+		rules = append(rules, rule)
+	}
+
+	slices.SortFunc(rules, func(a, b Rule) int {
+		return cmp.Compare(a.ID(), b.ID())
+	})
+
+	return rules
+}
+
+// Linter runs the enabled rules of a registry.
+type Linter struct {
+	rules      []Rule
+	severities map[string]Severity
+}
+
+// NewLinter creates a linter running the rules of the registry, as configured
+// by cfg. Custom rules described in cfg are added to the registry.
+func NewLinter(registry *Registry, cfg Config) (*Linter, error) {
+	for _, definition := range cfg.CustomRules {
+		rule, err := NewCELRule(definition)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := registry.Register(rule); err != nil {
+			return nil, err
+		}
+	}
+
+	for id, ruleCfg := range cfg.Rules {
+		if _, ok := registry.Get(id); !ok {
+			return nil, fmt.Errorf("unknown rule '%s'", id)
+		}
+
+		if ruleCfg.Severity != "" {
+			if err := ruleCfg.Severity.Validate(); err != nil {
+				return nil, fmt.Errorf("rule '%s': %w", id, err)
+			}
+		}
+	}
+
+	linter := &Linter{
+		severities: make(map[string]Severity),
+	}
+
+	for _, rule := range registry.Rules() {
+		ruleCfg := cfg.Rules[rule.ID()]
+		if ruleCfg.Enabled != nil && !*ruleCfg.Enabled {
+			continue
+		}
+
+		severity := rule.Severity()
+		if ruleCfg.Severity != "" {
+			severity = ruleCfg.Severity
+		}
+
+		linter.rules = append(linter.rules, rule)
+		linter.severities[rule.ID()] = severity
+	}
+
+	return linter, nil
+}
+
+// Rules returns the rules run by the linter, sorted by ID.
+func (linter *Linter) Rules() []Rule {
+	return linter.rules
+}
+
+// Severity returns the severity of the findings reported by the given rule.
+func (linter *Linter) Severity(rule Rule) Severity {
+	return linter.severities[rule.ID()]
+}
+
+// Lint runs every enabled rule against the resources.
+// Findings are sorted by file, line and rule.
+func (linter *Linter) Lint(ctx context.Context, res *resources.Resources) ([]Finding, error) {
+	var findings []Finding
+
+	for _, rule := range linter.rules {
+		ruleFindings, err := rule.Check(ctx, res)
+		if err != nil {
+			return nil, fmt.Errorf("rule '%s': %w", rule.ID(), err)
+		}
+
+		for _, finding := range ruleFindings {
+			finding.Rule = rule.ID()
+			finding.Severity = linter.severities[rule.ID()]
+			findings = append(findings, finding)
+		}
+	}
+
+	locate(findings)
+
+	slices.SortStableFunc(findings, func(a, b Finding) int {
+		return cmp.Or(
+			cmp.Compare(sourcePath(a.Resource), sourcePath(b.Resource)),
+			cmp.Compare(a.Line, b.Line),
+			cmp.Compare(a.Rule, b.Rule),
+			cmp.Compare(a.Path, b.Path),
+		)
+	})
+
+	return findings, nil
+}
+
+// locate resolves the lines of the findings from the source files of the resources.
+func locate(findings []Finding) {
+	byResource := make(map[*resources.Resource][]int)
+	for i, finding := range findings {
+		if finding.Resource == nil || finding.Path == "" {
+			continue
+		}
+
+		byResource[finding.Resource] = append(byResource[finding.Resource], i)
+	}
+
+	for res, indices := range byResource {
+		paths := make([]string, len(indices))
+		for i, idx := range indices {
+			paths[i] = findings[idx].Path
+		}
+
+		for i, line := range local.SourceLines(res, paths) {
+			findings[indices[i]].Line = line
+		}
+	}
+}
+
+func sourcePath(res *resources.Resource) string {
+	if res == nil {
+		return ""
+	}
+
+	return res.SourcePath()
+}
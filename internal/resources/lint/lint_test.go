@@ -0,0 +1,217 @@
+package lint_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/lint"
+	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/stretchr/testify/require"
+)
+
+const validDashboard = `apiVersion: dashboard.grafana.app/v1
+kind: Dashboard
+metadata:
+  name: valid
+  annotations:
+    grafana.app/folder: team
+spec:
+  title: Valid
+  tags: [infra]
+  panels:
+    - id: 1
+      datasource:
+        uid: ${datasource}
+`
+
+const invalidDashboard = `apiVersion: dashboard.grafana.app/v1
+kind: Dashboard
+metadata:
+  name: invalid
+spec:
+  title: Valid
+  panels:
+    - id: 1
+      datasource: Prometheus
+    - id: 2
+      type: row
+      panels:
+        - id: 1
+          datasource: -- Grafana --
+`
+
+const duplicateTitleDashboard = `apiVersion: dashboard.grafana.app/v1
+kind: Dashboard
+metadata:
+  name: zz-duplicate
+  annotations:
+    grafana.app/folder: team
+spec:
+  title: Valid
+  tags: [infra]
+`
+
+type finding struct {
+	Rule     string
+	Severity lint.Severity
+	Name     string
+	Path     string
+	Line     int
+}
+
+func readResources(t *testing.T, files map[string]string) *resources.Resources {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+
+	reader := local.FSReader{Decoders: format.Codecs(), StopOnError: true}
+
+	dst := resources.NewResources()
+	require.NoError(t, reader.Read(t.Context(), dst, nil, []string{dir}))
+
+	return dst
+}
+
+func lintResources(t *testing.T, cfg lint.Config, res *resources.Resources) []finding {
+	t.Helper()
+
+	linter, err := lint.NewLinter(lint.NewDefaultRegistry(), cfg)
+	require.NoError(t, err)
+
+	findings, err := linter.Lint(t.Context(), res)
+	require.NoError(t, err)
+
+	simplified := make([]finding, 0, len(findings))
+	for _, f := range findings {
+		require.NotEmpty(t, f.Message)
+
+		simplified = append(simplified, finding{
+			Rule:     f.Rule,
+			Severity: f.Severity,
+			Name:     f.Resource.Name(),
+			Path:     f.Path,
+			Line:     f.Line,
+		})
+	}
+
+	return simplified
+}
+
+func TestLinter_Lint_builtinRules(t *testing.T) {
+	req := require.New(t)
+
+	res := readResources(t, map[string]string{
+		"valid.yaml":     validDashboard,
+		"invalid.yaml":   invalidDashboard,
+		"duplicate.yaml": duplicateTitleDashboard,
+	})
+
+	req.ElementsMatch([]finding{
+		{Rule: "dashboard-folder", Severity: lint.SeverityError, Name: "invalid", Path: "$.metadata", Line: 4},
+		{Rule: "dashboard-tags", Severity: lint.SeverityWarning, Name: "invalid", Path: "$.spec.tags", Line: 6},
+		{Rule: "dashboard-datasource-ref", Severity: lint.SeverityError, Name: "invalid", Path: "$.spec.panels[0].datasource", Line: 9},
+		{Rule: "dashboard-unique-panel-ids", Severity: lint.SeverityError, Name: "invalid", Path: "$.spec.panels[1].panels[0].id", Line: 13},
+		{Rule: "dashboard-unique-title", Severity: lint.SeverityError, Name: "zz-duplicate", Path: "$.spec.title", Line: 8},
+	}, lintResources(t, lint.Config{}, res))
+}
+
+func TestLinter_Lint_config(t *testing.T) {
+	req := require.New(t)
+
+	res := readResources(t, map[string]string{
+		"invalid.yaml": invalidDashboard,
+	})
+
+	cfg := lint.Config{
+		Rules: map[string]lint.RuleConfig{
+			"dashboard-tags": {Severity: lint.SeverityInfo},
+		},
+	}
+	cfg.Enable("dashboard-folder", false)
+	cfg.Enable("dashboard-datasource-ref", false)
+	cfg.Enable("dashboard-unique-panel-ids", false)
+
+	req.Equal([]finding{
+		{Rule: "dashboard-tags", Severity: lint.SeverityInfo, Name: "invalid", Path: "$.spec.tags", Line: 6},
+	}, lintResources(t, cfg, res))
+}
+
+func TestLinter_Lint_customRules(t *testing.T) {
+	req := require.New(t)
+
+	res := readResources(t, map[string]string{
+		"valid.yaml":   validDashboard,
+		"invalid.yaml": invalidDashboard,
+	})
+
+	cfg := lint.Config{
+		CustomRules: []lint.CustomRule{
+			{
+				ID:       "dashboard-name-prefix",
+				Severity: lint.SeverityWarning,
+				Match:    &lint.Match{Kind: "Dashboard"},
+				Expr:     "object.metadata.name.startsWith('val')",
+			},
+			{
+				ID:   "single-dashboard-title",
+				Expr: "resources.filter(r, r.spec.title == object.spec.title).size() == 1",
+			},
+		},
+	}
+	for _, rule := range lint.NewDefaultRegistry().Rules() {
+		cfg.Enable(rule.ID(), false)
+	}
+
+	req.ElementsMatch([]finding{
+		{Rule: "dashboard-name-prefix", Severity: lint.SeverityWarning, Name: "invalid"},
+		{Rule: "single-dashboard-title", Severity: lint.SeverityError, Name: "invalid"},
+		{Rule: "single-dashboard-title", Severity: lint.SeverityError, Name: "valid"},
+	}, lintResources(t, cfg, res))
+}
+
+func TestNewLinter_invalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  lint.Config
+		err  string
+	}{
+		{
+			name: "unknown rule",
+			cfg:  lint.Config{Rules: map[string]lint.RuleConfig{"unknown": {}}},
+			err:  "unknown rule 'unknown'",
+		},
+		{
+			name: "invalid severity",
+			cfg:  lint.Config{Rules: map[string]lint.RuleConfig{"dashboard-tags": {Severity: "fatal"}}},
+			err:  "invalid severity 'fatal'",
+		},
+		{
+			name: "invalid expression",
+			cfg:  lint.Config{CustomRules: []lint.CustomRule{{ID: "broken", Expr: "object.("}}},
+			err:  "custom rule 'broken': invalid expression",
+		},
+		{
+			name: "non-boolean expression",
+			cfg:  lint.Config{CustomRules: []lint.CustomRule{{ID: "broken", Expr: "'hello'"}}},
+			err:  "the expression must return a boolean",
+		},
+		{
+			name: "duplicate rule",
+			cfg:  lint.Config{CustomRules: []lint.CustomRule{{ID: "dashboard-tags", Expr: "true"}}},
+			err:  "rule 'dashboard-tags' is already registered",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := lint.NewLinter(lint.NewDefaultRegistry(), test.cfg)
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"cuelang.org/go/cue"
+	cueerrors "cuelang.org/go/cue/errors"
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const cueModuleDir = "cue.mod"
+
+// cueEvaluator evaluates CUE packages into concrete values.
+// It is implemented by format.CUECodec.
+type cueEvaluator interface {
+	Evaluate(path string) (cue.Value, error)
+}
+
+// ReadCUE evaluates the CUE package (or single .cue file) at the given path and
+// adds every resource it exports to dst.
+//
+// Resources are looked up in the top-level value of the package: the value
+// itself if it describes a resource, otherwise each of its top-level fields
+// (or list items) that describe a resource.
+func (reader *FSReader) ReadCUE(ctx context.Context, dst *resources.Resources, path string) error {
+	logger := logging.FromContext(ctx).With(slog.String("component", "fs_reader"))
+	logger.Debug("Evaluating CUE package", slog.String("path", path))
+
+	objects, err := reader.readCUE(path)
+	if err != nil {
+		return err
+	}
+
+	for i := range objects {
+		dst.Add(&objects[i])
+	}
+
+	return nil
+}
+
+func (reader *FSReader) readCUE(path string) ([]resources.Resource, error) {
+	evaluator, ok := reader.Decoders[format.CUE].(cueEvaluator)
+	if !ok {
+		return nil, UnrecognisedFormatError{File: path, Format: string(format.CUE)}
+	}
+
+	value, err := evaluator.Evaluate(path)
+	if err != nil {
+		return nil, cueParseError(path, err)
+	}
+
+	values, err := cueResourceValues(value)
+	if err != nil {
+		return nil, cueParseError(path, err)
+	}
+
+	objects := make([]resources.Resource, 0, len(values))
+	for _, val := range values {
+		object := &unstructured.Unstructured{}
+		if err := format.DecodeCUEValue(val, object); err != nil {
+			return nil, cueParseError(path, err)
+		}
+
+		var res resources.Resource
+		if err := res.SetUnstructured(object); err != nil {
+			return nil, err
+		}
+
+		source := path
+		if filename := val.Pos().Filename(); filename != "" {
+			source = cueSourcePath(path, filename)
+		}
+
+		res.SetSource(resources.SourceInfo{
+			Path:   source,
+			Format: format.CUE,
+		})
+
+		if err := reader.process(&res); err != nil {
+			return nil, err
+		}
+
+		objects = append(objects, res)
+	}
+
+	return objects, nil
+}
+
+// cueSourcePath returns the path of the file of a CUE package that defines a
+// value, relative to the same directory as the path of the package: CUE always
+// reports absolute file names, while resources read from other formats keep
+// the paths they were given.
+func cueSourcePath(packagePath string, filename string) string {
+	if filepath.IsAbs(packagePath) {
+		return filename
+	}
+
+	dir := packagePath
+	if isCUEFile(packagePath) {
+		dir = filepath.Dir(packagePath)
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return filename
+	}
+
+	rel, err := filepath.Rel(absDir, filename)
+	if err != nil {
+		return filename
+	}
+
+	return filepath.Join(dir, rel)
+}
+
+func cueResourceValues(value cue.Value) ([]cue.Value, error) {
+	if isCUEResource(value) {
+		return []cue.Value{value}, nil
+	}
+
+	var iter interface {
+		Next() bool
+		Value() cue.Value
+	}
+
+	switch value.Kind() {
+	case cue.StructKind:
+		fields, err := value.Fields()
+		if err != nil {
+			return nil, err
+		}
+		iter = fields
+	case cue.ListKind:
+		items, err := value.List()
+		if err != nil {
+			return nil, err
+		}
+		iter = &items
+	default:
+		return nil, fmt.Errorf("expected a struct or a list at the top-level, got %s", value.Kind())
+	}
+
+	var values []cue.Value
+	for iter.Next() {
+		if isCUEResource(iter.Value()) {
+			values = append(values, iter.Value())
+		}
+	}
+
+	return values, nil
+}
+
+func isCUEResource(value cue.Value) bool {
+	if value.Kind() != cue.StructKind {
+		return false
+	}
+
+	return value.LookupPath(cue.ParsePath("apiVersion")).Exists() &&
+		value.LookupPath(cue.ParsePath("kind")).Exists()
+}
+
+// cueParseError converts CUE evaluation errors into a ParseError,
+// pointing at the file and line of the first error.
+func cueParseError(path string, err error) ParseError {
+	parseErr := ParseError{File: path, Err: err}
+
+	var cueErr cueerrors.Error
+	if !errors.As(err, &cueErr) {
+		return parseErr
+	}
+
+	for _, pos := range cueerrors.Positions(cueErr) {
+		if pos.Filename() == "" {
+			continue
+		}
+
+		parseErr.File = pos.Filename()
+		parseErr.Line = pos.Line()
+
+		break
+	}
+
+	return parseErr
+}
+
+func isCUEFile(path string) bool {
+	return filepath.Ext(path) == "."+string(format.CUE)
+}
@@ -0,0 +1,163 @@
+package local_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/stretchr/testify/require"
+)
+
+const cueDashboards = `package dashboards
+
+env: *"dev" | string @tag(env)
+
+#Dashboard: {
+	name:  string
+	title: string
+
+	apiVersion: "dashboard.grafana.app/v1"
+	kind:       "Dashboard"
+	metadata: {
+		"name": name
+		namespace: "default"
+	}
+	spec: {
+		"title": "\(title) (\(env))"
+	}
+}
+
+overview: #Dashboard & {name: "overview", title: "Overview"}
+`
+
+const cueFolders = `package dashboards
+
+folder: {
+	apiVersion: "folder.grafana.app/v1"
+	kind:       "Folder"
+	metadata: {
+		name:      "team"
+		namespace: "default"
+	}
+	spec: title: "Team (\(env))"
+}
+`
+
+func writeCUEPackage(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+
+	return dir
+}
+
+func TestFSReader_Read_CUEPackage(t *testing.T) {
+	req := require.New(t)
+	dir := writeCUEPackage(t, map[string]string{
+		"dashboards.cue": cueDashboards,
+		"folders.cue":    cueFolders,
+	})
+
+	reader := local.FSReader{
+		Decoders: map[format.Format]format.Codec{
+			format.CUE: format.NewCUECodec("env=prod"),
+		},
+		StopOnError: true,
+	}
+
+	dst := resources.NewResources()
+	req.NoError(reader.Read(t.Context(), dst, nil, []string{dir}))
+	req.Equal(2, dst.Len())
+
+	dashboard, ok := dst.Find("Dashboard", "overview")
+	req.True(ok)
+	req.Equal(format.CUE, dashboard.SourceFormat())
+	req.Equal(filepath.Join(dir, "dashboards.cue"), dashboard.SourcePath())
+
+	spec, err := dashboard.Spec()
+	req.NoError(err)
+	req.Equal(map[string]any{"title": "Overview (prod)"}, spec)
+
+	folder, ok := dst.Find("Folder", "team")
+	req.True(ok)
+	req.Equal(filepath.Join(dir, "folders.cue"), folder.SourcePath())
+}
+
+func TestFSReader_Read_CUEPackage_relativePath(t *testing.T) {
+	req := require.New(t)
+	dir := writeCUEPackage(t, map[string]string{
+		"dashboards.cue": cueDashboards,
+	})
+	t.Chdir(filepath.Dir(dir))
+
+	reader := local.FSReader{
+		Decoders:    format.Codecs(),
+		StopOnError: true,
+	}
+
+	// Sources keep the path they were read from, as for other formats: push
+	// --watch matches them against the paths of the files that changed.
+	packagePath := filepath.Base(dir)
+	for _, path := range []string{packagePath, filepath.Join(packagePath, "dashboards.cue")} {
+		dst := resources.NewResources()
+		req.NoError(reader.Read(t.Context(), dst, nil, []string{path}))
+
+		dashboard, ok := dst.Find("Dashboard", "overview")
+		req.True(ok)
+		req.Equal(filepath.Join(packagePath, "dashboards.cue"), dashboard.SourcePath())
+	}
+}
+
+func TestFSReader_ReadCUE_defaultTags(t *testing.T) {
+	req := require.New(t)
+	dir := writeCUEPackage(t, map[string]string{
+		"dashboards.cue": cueDashboards,
+	})
+
+	reader := local.FSReader{
+		Decoders: format.Codecs(),
+	}
+
+	dst := resources.NewResources()
+	req.NoError(reader.ReadCUE(t.Context(), dst, dir))
+
+	dashboard, ok := dst.Find("Dashboard", "overview")
+	req.True(ok)
+
+	spec, err := dashboard.Spec()
+	req.NoError(err)
+	req.Equal(map[string]any{"title": "Overview (dev)"}, spec)
+}
+
+func TestFSReader_ReadCUE_evaluationError(t *testing.T) {
+	req := require.New(t)
+	dir := writeCUEPackage(t, map[string]string{
+		"dashboards.cue": `package dashboards
+
+dashboard: {
+	apiVersion: "dashboard.grafana.app/v1"
+	kind:       "Dashboard"
+	metadata: name: "broken"
+	spec: title: string
+}
+`,
+	})
+
+	reader := local.FSReader{
+		Decoders: format.Codecs(),
+	}
+
+	err := reader.ReadCUE(t.Context(), resources.NewResources(), dir)
+	req.Error(err)
+
+	parseErr := local.ParseError{}
+	req.ErrorAs(err, &parseErr)
+	req.Equal(filepath.Join(dir, "dashboards.cue"), parseErr.File)
+	req.Equal(7, parseErr.Line)
+}
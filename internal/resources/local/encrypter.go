@@ -0,0 +1,83 @@
+package local
+
+import (
+	"bytes"
+	"errors"
+	"os"
+
+	"filippo.io/age"
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/secrets"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ErrSOPSEncrypted is returned when encrypting a file already encrypted with SOPS.
+var ErrSOPSEncrypted = errors.New("the file is encrypted with SOPS")
+
+// EncryptedFile is a resource file with its secret fields encrypted.
+type EncryptedFile struct {
+	// Path of the file.
+	Path string
+	// Content of the file, with its secret fields encrypted.
+	Content []byte
+	// Encrypted is the number of values encrypted.
+	Encrypted int
+}
+
+// Encrypter encrypts the secret fields of resource files with age.
+//
+// Secret fields are spec.secureJsonData and the fields listed in the
+// secrets.FieldsAnnotation annotation of resources. Values already encrypted
+// are left untouched.
+type Encrypter struct {
+	// Codecs used to decode and encode files, by format.
+	Codecs map[format.Format]format.Codec
+	// Recipients for which values are encrypted.
+	Recipients []age.Recipient
+	// Fields to mark as secret, in addition to the ones already marked.
+	Fields []string
+}
+
+// Encrypt encrypts the secret fields of a resource file.
+// The file itself is not modified.
+func (e Encrypter) Encrypt(path string) (EncryptedFile, error) {
+	result := EncryptedFile{Path: path}
+
+	fileFormat, err := fileFormat(path)
+	if err != nil {
+		return result, err
+	}
+
+	codec, ok := e.Codecs[fileFormat]
+	if !ok {
+		return result, UnrecognisedFormatError{File: path, Format: string(fileFormat)}
+	}
+
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return result, err
+	}
+
+	object := &unstructured.Unstructured{}
+	if err := codec.Decode(bytes.NewReader(original), object); err != nil {
+		return result, ParseError{File: path, Err: err}
+	}
+
+	if secrets.IsSOPS(object.Object) {
+		return result, ErrSOPSEncrypted
+	}
+
+	result.Encrypted, err = secrets.Encrypt(object.Object, e.Recipients, e.Fields)
+	if err != nil {
+		return result, err
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := codec.Encode(buffer, object.Object); err != nil {
+		return result, err
+	}
+
+	result.Content = buffer.Bytes()
+
+	return result, nil
+}
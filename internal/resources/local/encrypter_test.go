@@ -0,0 +1,96 @@
+package local_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/grafana/grafanactl/internal/secrets"
+	"github.com/stretchr/testify/require"
+)
+
+const datasource = `apiVersion: legacy.grafana.app/v0
+kind: DataSource
+metadata:
+  name: prom
+spec:
+  secureJsonData:
+    basicAuthPassword: hunter2
+  url: http://prometheus:9090
+`
+
+func TestEncrypter_Encrypt(t *testing.T) {
+	req := require.New(t)
+
+	identity, err := age.GenerateX25519Identity()
+	req.NoError(err)
+
+	encrypter := local.Encrypter{
+		Codecs:     format.Codecs(),
+		Recipients: []age.Recipient{identity.Recipient()},
+	}
+
+	path := writeFile(t, "datasource.yaml", datasource)
+
+	result, err := encrypter.Encrypt(path)
+	req.NoError(err)
+	req.Equal(1, result.Encrypted)
+	req.NotContains(string(result.Content), "hunter2")
+	req.Contains(string(result.Content), "basicAuthPassword: ENC[age,")
+
+	// The file itself is not modified.
+	original, err := os.ReadFile(path)
+	req.NoError(err)
+	req.Equal(datasource, string(original))
+
+	req.NoError(os.WriteFile(path, result.Content, 0600))
+
+	// Without a decrypter, encrypted values are read as-is.
+	reader := local.FSReader{Decoders: format.Codecs()}
+	res := &resources.Resource{}
+	req.NoError(reader.ReadFile(t.Context(), res, path))
+	req.True(secrets.HasEncryptedValues(res.Object.Object))
+
+	reader.Decrypter = &secrets.Decrypter{Identities: []age.Identity{identity}}
+	res = &resources.Resource{}
+	req.NoError(reader.ReadFile(t.Context(), res, path))
+	req.Equal(map[string]any{"basicAuthPassword": "hunter2"}, res.Object.Object["spec"].(map[string]any)["secureJsonData"])
+
+	// Decrypted values are never written to disk.
+	onDisk, err := os.ReadFile(path)
+	req.NoError(err)
+	req.Equal(result.Content, onDisk)
+}
+
+func TestFSReader_ReadFile_sops(t *testing.T) {
+	req := require.New(t)
+
+	path := writeFile(t, "datasource.yaml", strings.ReplaceAll(datasource, "hunter2", "ENC[AES256_GCM,data:abc]")+`sops:
+  mac: ENC[AES256_GCM,data:def]
+`)
+
+	reader := local.FSReader{
+		Decoders: format.Codecs(),
+		Decrypter: &secrets.Decrypter{
+			DecryptSOPS: func(_ context.Context, data []byte, inputFormat string) ([]byte, error) {
+				req.Equal("yaml", inputFormat)
+				req.Contains(string(data), "sops:")
+
+				return []byte(datasource), nil
+			},
+		},
+	}
+
+	res := &resources.Resource{}
+	req.NoError(reader.ReadFile(t.Context(), res, path))
+	req.NotContains(res.Object.Object, "sops")
+	req.Equal(map[string]any{"basicAuthPassword": "hunter2"}, res.Object.Object["spec"].(map[string]any)["secureJsonData"])
+
+	_, err := local.Encrypter{Codecs: format.Codecs()}.Encrypt(path)
+	req.ErrorIs(err, local.ErrSOPSEncrypted)
+}
@@ -0,0 +1,131 @@
+package local
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/process"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// FormattedFile is a resource file in its canonical form.
+type FormattedFile struct {
+	// Path of the original file.
+	Path string
+	// Target is the path of the formatted file.
+	// It differs from Path when the file is converted to another format.
+	Target string
+	// Content of the formatted file.
+	Content []byte
+	// Changed is true if the formatted file differs from the original one.
+	Changed bool
+}
+
+// Formatter rewrites resource files into a canonical form: the form in which
+// resources are pulled from Grafana.
+//
+// Keys are sorted, the indentation is the one of the codec used to encode
+// the file, and the fields set by the server are removed.
+type Formatter struct {
+	// Codecs used to decode and encode files, by format.
+	Codecs map[format.Format]format.Codec
+}
+
+// Format formats a resource file.
+// If the target format is empty, the file keeps its format.
+func (f Formatter) Format(path string, to format.Format) (FormattedFile, error) {
+	result := FormattedFile{Path: path, Target: path}
+
+	from, err := fileFormat(path)
+	if err != nil {
+		return result, err
+	}
+
+	decoder, ok := f.Codecs[from]
+	if !ok {
+		return result, UnrecognisedFormatError{File: path, Format: string(from)}
+	}
+
+	if to == "" {
+		to = from
+	}
+
+	encoder, ok := f.Codecs[to]
+	if !ok {
+		return result, UnrecognisedFormatError{Format: string(to)}
+	}
+
+	if to != from {
+		result.Target = strings.TrimSuffix(path, filepath.Ext(path)) + "." + string(to)
+	}
+
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return result, err
+	}
+
+	object := &unstructured.Unstructured{}
+	if err := decoder.Decode(bytes.NewReader(original), object); err != nil {
+		return result, ParseError{File: path, Err: err}
+	}
+
+	res, err := resources.FromUnstructured(object)
+	if err != nil {
+		return result, err
+	}
+
+	stripper := &process.ServerFieldsStripper{}
+	if err := stripper.Process(res); err != nil {
+		return result, err
+	}
+
+	// Empty metadata fields are noise in files written by hand.
+	for _, field := range []string{"namespace", "annotations", "labels"} {
+		value, found, _ := unstructured.NestedFieldNoCopy(res.Object.Object, "metadata", field)
+		if found && isEmpty(value) {
+			unstructured.RemoveNestedField(res.Object.Object, "metadata", field)
+		}
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := encoder.Encode(buffer, res.Object.Object); err != nil {
+		return result, err
+	}
+
+	result.Content = buffer.Bytes()
+	result.Changed = result.Target != path || !bytes.Equal(original, result.Content)
+
+	return result, nil
+}
+
+func fileFormat(path string) (format.Format, error) {
+	switch strings.TrimPrefix(filepath.Ext(path), ".") {
+	case "json":
+		return format.JSON, nil
+	case "yaml", "yml":
+		return format.YAML, nil
+	default:
+		return "", UnrecognisedFormatError{File: path}
+	}
+}
+
+func isEmpty(value any) bool {
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,92 @@
+package local_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/stretchr/testify/require"
+)
+
+const unformattedDashboard = `kind: Dashboard
+apiVersion: dashboard.grafana.app/v1
+metadata:
+    name: foo
+    resourceVersion: "12"
+    annotations:
+        grafana.app/updatedBy: someone
+spec:
+    title: Foo
+    panels: [{id: 1}]
+status: {}
+`
+
+const formattedDashboard = `apiVersion: dashboard.grafana.app/v1
+kind: Dashboard
+metadata:
+  name: foo
+spec:
+  panels:
+    - id: 1
+  title: Foo
+`
+
+func writeFile(t *testing.T, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	return path
+}
+
+func TestFormatter_Format(t *testing.T) {
+	req := require.New(t)
+	formatter := local.Formatter{Codecs: format.Codecs()}
+
+	path := writeFile(t, "dashboard.yaml", unformattedDashboard)
+
+	result, err := formatter.Format(path, "")
+	req.NoError(err)
+	req.True(result.Changed)
+	req.Equal(path, result.Target)
+	req.Equal(formattedDashboard, string(result.Content))
+
+	// Formatted files are left unchanged.
+	path = writeFile(t, "dashboard.yml", formattedDashboard)
+
+	result, err = formatter.Format(path, "")
+	req.NoError(err)
+	req.False(result.Changed)
+}
+
+func TestFormatter_Format_convert(t *testing.T) {
+	req := require.New(t)
+	formatter := local.Formatter{Codecs: format.Codecs()}
+
+	path := writeFile(t, "dashboard.yaml", formattedDashboard)
+
+	result, err := formatter.Format(path, format.JSON)
+	req.NoError(err)
+	req.True(result.Changed)
+	req.Equal(filepath.Join(filepath.Dir(path), "dashboard.json"), result.Target)
+	req.JSONEq(`{
+		"apiVersion": "dashboard.grafana.app/v1",
+		"kind": "Dashboard",
+		"metadata": {"name": "foo"},
+		"spec": {"panels": [{"id": 1}], "title": "Foo"}
+	}`, string(result.Content))
+}
+
+func TestFormatter_Format_invalidFile(t *testing.T) {
+	req := require.New(t)
+	formatter := local.Formatter{Codecs: format.Codecs()}
+
+	_, err := formatter.Format(writeFile(t, "dashboard.yaml", "kind: [\n"), "")
+	req.ErrorAs(err, &local.ParseError{})
+
+	_, err = formatter.Format(writeFile(t, "dashboard.txt", ""), "")
+	req.ErrorAs(err, &local.UnrecognisedFormatError{})
+}
@@ -0,0 +1,80 @@
+package local
+
+import (
+	"os"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources"
+)
+
+// SourceLines resolves the 1-based lines at which the fields designated by
+// the given JSON paths (example: $.spec.panels[0].title) are defined, in the
+// source file of the resource.
+//
+// Fields that do not exist in the source file are located at their closest
+// existing parent. Lines are set to 0 when they can not be resolved: the
+// resource doesn't come from a JSON or YAML file, the file can't be read, ...
+func SourceLines(res *resources.Resource, paths []string) []int {
+	lines := make([]int, len(paths))
+
+	if res.SourcePath() == "" {
+		return lines
+	}
+
+	if res.SourceFormat() != format.JSON && res.SourceFormat() != format.YAML {
+		return lines
+	}
+
+	src, err := os.ReadFile(res.SourcePath())
+	if err != nil {
+		return lines
+	}
+
+	// JSON documents are valid YAML documents.
+	file, err := parser.ParseBytes(src, 0)
+	if err != nil {
+		return lines
+	}
+
+	for i, path := range paths {
+		lines[i] = lineOf(file, path)
+	}
+
+	return lines
+}
+
+// lineOf returns the line of the node at the given path, or of its closest
+// existing parent.
+func lineOf(file *ast.File, path string) int {
+	for path != "$" && path != "" {
+		if yamlPath, err := yaml.PathString(path); err == nil {
+			if node, err := yamlPath.FilterFile(file); err == nil && node != nil {
+				return node.GetToken().Position.Line
+			}
+		}
+
+		path = parentPath(path)
+	}
+
+	return 0
+}
+
+// parentPath returns the path of the parent of the given path.
+// Example: $.spec.panels[0] -> $.spec.panels
+func parentPath(path string) string {
+	if strings.HasSuffix(path, "]") {
+		if idx := strings.LastIndex(path, "["); idx != -1 {
+			return path[:idx]
+		}
+	}
+
+	if idx := strings.LastIndex(path, "."); idx != -1 {
+		return path[:idx]
+	}
+
+	return ""
+}
@@ -15,7 +15,9 @@ import (
 	"github.com/grafana/grafanactl/internal/format"
 	"github.com/grafana/grafanactl/internal/logs"
 	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/secrets"
 	"golang.org/x/sync/errgroup"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
@@ -35,13 +37,29 @@ func (e UnrecognisedFormatError) Error() string {
 
 type ParseError struct {
 	File string
+	// Line is the 1-based line at which the error occurred.
+	// It is set to 0 when unknown.
+	Line int
 	Err  error
 }
 
 func (err ParseError) Error() string {
+	if err.Line > 0 {
+		return fmt.Sprintf("parse error in '%s:%d': %s", err.File, err.Line, err.Err)
+	}
+
 	return fmt.Sprintf("parse error in '%s': %s", err.File, err.Err)
 }
 
+func (err ParseError) Unwrap() error {
+	return err.Err
+}
+
+// Processor modifies a resource in-place, after it is read.
+type Processor interface {
+	Process(res *resources.Resource) error
+}
+
 // FSReader is a reader that reads resources from the filesystem.
 //
 // The reader will read all resources from the filesystem and return them as
@@ -54,6 +72,17 @@ type FSReader struct {
 	// MaxConcurrentReads is the maximum number of concurrent file reads.
 	// If not set, the default is 1.
 	MaxConcurrentReads int
+	// Template used to render files before decoding them.
+	// If not set, files are decoded as-is.
+	// CUE packages are never rendered: they are configured using tags.
+	Template *Template
+	// Decrypter used to decrypt the secrets of resources, in memory.
+	// If not set, encrypted values are read as-is.
+	// Resources read with encrypted values have SourceInfo.Encrypted set:
+	// they must not be written back to disk.
+	Decrypter *secrets.Decrypter
+	// Processors applied to resources once they are read and decrypted.
+	Processors []Processor
 }
 
 // Read reads all resources from the filesystem and returns them as an unstructured list.
@@ -75,10 +104,14 @@ func (reader *FSReader) Read(
 	gr, ctx := errgroup.WithContext(ctx)
 
 	// Read directories.
-	pathCh := make(chan string, reader.MaxConcurrentReads)
+	pathCh := make(chan readTarget, reader.MaxConcurrentReads)
 	gr.Go(func() error {
 		defer close(pathCh)
 
+		// CUE files are evaluated as a whole package: we only need to send
+		// each package directory once.
+		cuePackages := make(map[string]struct{})
+
 		for _, path := range paths {
 			info, err := os.Stat(path)
 			if err != nil {
@@ -96,7 +129,7 @@ func (reader *FSReader) Read(
 				select {
 				case <-ctx.Done():
 					return nil
-				case pathCh <- path:
+				case pathCh <- readTarget{Path: path, CUE: isCUEFile(path)}:
 				}
 
 				continue
@@ -118,13 +151,29 @@ func (reader *FSReader) Read(
 
 				// For directories, return nil to continue traversing
 				if info.IsDir() {
+					// CUE modules metadata & dependencies aren't resources.
+					if info.Name() == cueModuleDir {
+						return filepath.SkipDir
+					}
+
 					return nil
 				}
 
+				target := readTarget{Path: path}
+				if isCUEFile(path) {
+					dir := filepath.Dir(path)
+					if _, ok := cuePackages[dir]; ok {
+						return nil
+					}
+
+					cuePackages[dir] = struct{}{}
+					target = readTarget{Path: dir, CUE: true}
+				}
+
 				select {
 				case <-ctx.Done():
 					return filepath.SkipAll
-				case pathCh <- path:
+				case pathCh <- target:
 				}
 
 				return nil
@@ -143,7 +192,44 @@ func (reader *FSReader) Read(
 		readg, ctx := errgroup.WithContext(ctx)
 		readg.SetLimit(reader.MaxConcurrentReads)
 
-		for path := range pathCh {
+		for target := range pathCh {
+			path := target.Path
+
+			if target.CUE {
+				readg.Go(func() error {
+					objects, err := reader.readCUE(path)
+					if err != nil {
+						if reader.StopOnError {
+							return fmt.Errorf("failed to evaluate CUE package %s: %w", path, err)
+						}
+
+						logger.Warn("failed to evaluate CUE package", slog.String("path", path), logs.Err(err))
+						return nil
+					}
+
+					for _, object := range objects {
+						if !filters.Matches(object) {
+							logger.Debug("skipping object because it does not match any filters",
+								"path", path,
+								"gvk", object.GroupVersionKind(),
+								"name", object.Name(),
+							)
+							continue
+						}
+
+						select {
+						case <-ctx.Done():
+							return nil
+						case resCh <- readResult{Object: object, Path: object.SourcePath()}:
+						}
+					}
+
+					return nil
+				})
+
+				continue
+			}
+
 			readg.Go(func() error {
 				var object resources.Resource
 
@@ -230,13 +316,93 @@ func (reader *FSReader) ReadFile(ctx context.Context, result *resources.Resource
 
 	logger.Debug("Parsing file", slog.String("file", filePath), slog.String("codec", string(decoder.Format())))
 
-	file, err := os.Open(filePath)
+	if reader.Template == nil && reader.Decrypter == nil {
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		if err := reader.readRaw(decoder, file, filePath, result); err != nil {
+			return err
+		}
+
+		return reader.process(result)
+	}
+
+	raw, err := os.ReadFile(filePath)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	return reader.readRaw(decoder, file, filePath, result)
+	rendered := raw
+	if reader.Template != nil {
+		rendered, err = reader.Template.Render(filePath, raw)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := reader.readRaw(decoder, bytes.NewReader(rendered), filePath, result); err != nil {
+		return err
+	}
+
+	encrypted := false
+	if reader.Decrypter != nil {
+		encrypted = secrets.IsSOPS(result.Object.Object) || secrets.HasEncryptedValues(result.Object.Object)
+
+		if err := reader.decrypt(ctx, decoder, rendered, filePath, result); err != nil {
+			return err
+		}
+	}
+
+	// Files without placeholders are left as-is by the template.
+	result.Source.Rendered = !bytes.Equal(rendered, raw)
+	result.Source.Encrypted = encrypted
+
+	return reader.process(result)
+}
+
+// process applies the processors of the reader to a resource.
+func (reader *FSReader) process(res *resources.Resource) error {
+	if len(reader.Processors) == 0 {
+		return nil
+	}
+
+	original := res.Object.DeepCopy()
+
+	for _, processor := range reader.Processors {
+		if err := processor.Process(res); err != nil {
+			return fmt.Errorf("could not process %s: %w", res.SourcePath(), err)
+		}
+	}
+
+	// Resources left untouched by the processors still match their source.
+	res.Source.Processed = !equality.Semantic.DeepEqual(original.Object, res.Object.Object)
+
+	return nil
+}
+
+// decrypt decrypts the secrets of a resource read from a file, in memory.
+func (reader *FSReader) decrypt(
+	ctx context.Context, decoder format.Codec, raw []byte, filePath string, result *resources.Resource,
+) error {
+	if secrets.IsSOPS(result.Object.Object) {
+		decrypted, err := reader.Decrypter.DecryptFile(ctx, raw, string(decoder.Format()))
+		if err != nil {
+			return fmt.Errorf("could not decrypt %s: %w", filePath, err)
+		}
+
+		if err := reader.readRaw(decoder, bytes.NewReader(decrypted), filePath, result); err != nil {
+			return err
+		}
+	}
+
+	if err := reader.Decrypter.Decrypt(result.Object.Object); err != nil {
+		return fmt.Errorf("could not decrypt %s: %w", filePath, err)
+	}
+
+	return nil
 }
 
 // ReadBytes reads a resource from a byte slice.
@@ -266,7 +432,7 @@ func (reader *FSReader) readRaw(
 	object := &unstructured.Unstructured{}
 
 	if err := decoder.Decode(src, object); err != nil {
-		return ParseError{Err: err}
+		return ParseError{File: path, Err: err}
 	}
 
 	if err := dst.SetUnstructured(object); err != nil {
@@ -288,6 +454,8 @@ func (reader *FSReader) decoderForFormat(input string) (format.Codec, error) {
 		return reader.Decoders[format.JSON], nil
 	case "yaml", "yml":
 		return reader.Decoders[format.YAML], nil
+	case "cue":
+		return reader.Decoders[format.CUE], nil
 	default:
 		return nil, UnrecognisedFormatError{Format: input}
 	}
@@ -298,6 +466,13 @@ type objIdx struct {
 	name string
 }
 
+type readTarget struct {
+	Path string
+	// CUE is set when Path is a CUE package (or file) that must be evaluated
+	// as a whole, and can yield several resources.
+	CUE bool
+}
+
 type readResult struct {
 	Object resources.Resource
 	Path   string
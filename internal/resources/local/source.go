@@ -0,0 +1,54 @@
+package local
+
+import (
+	"bytes"
+	"os"
+
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// UpdateSource returns the content of the file the resource was read from,
+// and the content it should have to represent the given object.
+// YAML files are patched rather than re-encoded, to preserve their comments
+// and formatting.
+func UpdateSource(resource *resources.Resource, object *unstructured.Unstructured) ([]byte, []byte, error) {
+	if err := resource.Source.Writable(); err != nil {
+		return nil, nil, err
+	}
+
+	original, err := os.ReadFile(resource.SourcePath())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Files that can't be patched are encoded again.
+	if resource.SourceFormat() == format.YAML {
+		if patched, err := format.PatchYAML(original, object.Object); err == nil {
+			return original, patched, nil
+		}
+	}
+
+	var codec format.Encoder = format.NewJSONCodec()
+	if resource.SourceFormat() == format.YAML {
+		codec = format.NewYAMLCodec()
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := codec.Encode(buffer, object); err != nil {
+		return nil, nil, err
+	}
+
+	return original, buffer.Bytes(), nil
+}
+
+// WriteSource writes an object back to the file the resource was read from.
+func WriteSource(resource *resources.Resource, object *unstructured.Unstructured) error {
+	_, updated, err := UpdateSource(resource, object)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(resource.SourcePath(), updated, 0644)
+}
@@ -0,0 +1,63 @@
+package local_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateSource(t *testing.T) {
+	req := require.New(t)
+
+	source := "# A comment\n" + formattedDashboard
+	path := writeFile(t, "dashboard.yaml", source)
+
+	res := resources.MustFromObject(map[string]any{
+		"apiVersion": "dashboard.grafana.app/v1",
+		"kind":       "Dashboard",
+		"metadata":   map[string]any{"name": "foo"},
+		"spec": map[string]any{
+			"panels": []any{map[string]any{"id": int64(1)}},
+			"title":  "Bar",
+		},
+	}, resources.SourceInfo{Path: path, Format: format.YAML})
+
+	original, updated, err := local.UpdateSource(res, &res.Object)
+	req.NoError(err)
+	req.Equal(source, string(original))
+	req.Equal("# A comment\n"+`apiVersion: dashboard.grafana.app/v1
+kind: Dashboard
+metadata:
+  name: foo
+spec:
+  panels:
+    - id: 1
+  title: Bar
+`, string(updated))
+
+	// The file is only modified by WriteSource.
+	content, err := os.ReadFile(path)
+	req.NoError(err)
+	req.Equal(source, string(content))
+
+	req.NoError(local.WriteSource(res, &res.Object))
+
+	content, err = os.ReadFile(path)
+	req.NoError(err)
+	req.Equal(string(updated), string(content))
+}
+
+func TestUpdateSource_CUE(t *testing.T) {
+	res := resources.MustFromObject(map[string]any{
+		"apiVersion": "dashboard.grafana.app/v1",
+		"kind":       "Dashboard",
+		"metadata":   map[string]any{"name": "foo"},
+	}, resources.SourceInfo{Path: "dashboards", Format: format.CUE})
+
+	_, _, err := local.UpdateSource(res, &res.Object)
+	require.ErrorContains(t, err, "CUE")
+}
@@ -0,0 +1,133 @@
+package local
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"text/template"
+)
+
+const templateName = "resource"
+
+var (
+	envVarPattern        = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	templateErrorPattern = regexp.MustCompile(`^template: ` + templateName + `:(\d+)(?::\d+)?: (.*)$`)
+)
+
+// Template renders resource files before they are decoded.
+//
+// Two kinds of substitutions are supported, in this order:
+//   - `${VAR}` is replaced by the value of the VAR environment variable.
+//     `$${VAR}` is rendered as a literal `${VAR}`, which is useful for
+//     Grafana template variables.
+//   - Go templates, with values exposed as `{{ .Values.x }}`.
+//
+// Rendering is strict: referencing an undefined environment variable or value
+// is an error.
+type Template struct {
+	// Values exposed to Go templates as `.Values`.
+	Values map[string]any
+	// LookupEnv is used to resolve environment variables.
+	// If not set, os.LookupEnv is used.
+	LookupEnv func(key string) (string, bool)
+}
+
+// Render renders the given source. The file name is only used in errors.
+func (t *Template) Render(file string, src []byte) ([]byte, error) {
+	expanded, err := t.expandEnv(file, src)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(templateName).Option("missingkey=error").Parse(string(expanded))
+	if err != nil {
+		return nil, templateError(file, err)
+	}
+
+	values := t.Values
+	if values == nil {
+		values = map[string]any{}
+	}
+
+	out := &bytes.Buffer{}
+	if err := tmpl.Execute(out, map[string]any{"Values": values}); err != nil {
+		return nil, templateError(file, err)
+	}
+
+	return out.Bytes(), nil
+}
+
+func (t *Template) expandEnv(file string, src []byte) ([]byte, error) {
+	lookup := t.LookupEnv
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+
+	lines := bytes.Split(src, []byte("\n"))
+	for i, line := range lines {
+		var missing string
+
+		lines[i] = envVarPattern.ReplaceAllFunc(line, func(match []byte) []byte {
+			// Escaped variable: render it without the leading '$'.
+			if bytes.HasPrefix(match, []byte("$$")) {
+				return match[1:]
+			}
+
+			name := string(envVarPattern.FindSubmatch(match)[1])
+			value, ok := lookup(name)
+			if !ok {
+				if missing == "" {
+					missing = name
+				}
+				return match
+			}
+
+			return []byte(value)
+		})
+
+		if missing != "" {
+			return nil, TemplateError{
+				File: file,
+				Line: i + 1,
+				Err:  fmt.Errorf("environment variable '%s' is not defined", missing),
+			}
+		}
+	}
+
+	return bytes.Join(lines, []byte("\n")), nil
+}
+
+func templateError(file string, err error) error {
+	matches := templateErrorPattern.FindStringSubmatch(err.Error())
+	if matches == nil {
+		return TemplateError{File: file, Err: err}
+	}
+
+	line, _ := strconv.Atoi(matches[1])
+
+	return TemplateError{File: file, Line: line, Err: errors.New(matches[2])}
+}
+
+// TemplateError is returned when a file can not be rendered by a Template.
+type TemplateError struct {
+	File string
+	// Line is the 1-based line at which the error occurred.
+	// It is set to 0 when unknown.
+	Line int
+	Err  error
+}
+
+func (err TemplateError) Error() string {
+	if err.Line > 0 {
+		return fmt.Sprintf("template error in '%s:%d': %s", err.File, err.Line, err.Err)
+	}
+
+	return fmt.Sprintf("template error in '%s': %s", err.File, err.Err)
+}
+
+func (err TemplateError) Unwrap() error {
+	return err.Err
+}
@@ -0,0 +1,127 @@
+package local_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/stretchr/testify/require"
+)
+
+func lookupEnv(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+func TestTemplate_Render(t *testing.T) {
+	req := require.New(t)
+
+	tmpl := local.Template{
+		Values: map[string]any{
+			"datasource": map[string]any{"uid": "prometheus-prod"},
+		},
+		LookupEnv: lookupEnv(map[string]string{"ENV": "prod"}),
+	}
+
+	rendered, err := tmpl.Render("dashboard.yaml", []byte(`title: Overview (${ENV})
+datasource: {{ .Values.datasource.uid }}
+query: rate(http_requests{env="$${env}"}[5m])
+`))
+	req.NoError(err)
+	req.Equal(`title: Overview (prod)
+datasource: prometheus-prod
+query: rate(http_requests{env="${env}"}[5m])
+`, string(rendered))
+}
+
+func TestTemplate_Render_undefinedEnvVar(t *testing.T) {
+	req := require.New(t)
+
+	tmpl := local.Template{LookupEnv: lookupEnv(nil)}
+
+	_, err := tmpl.Render("dashboard.yaml", []byte("kind: Dashboard\ntitle: ${TITLE}\n"))
+	req.Error(err)
+
+	templateErr := local.TemplateError{}
+	req.ErrorAs(err, &templateErr)
+	req.Equal("dashboard.yaml", templateErr.File)
+	req.Equal(2, templateErr.Line)
+	req.ErrorContains(err, "environment variable 'TITLE' is not defined")
+}
+
+func TestTemplate_Render_undefinedValue(t *testing.T) {
+	req := require.New(t)
+
+	tmpl := local.Template{
+		Values:    map[string]any{"datasource": map[string]any{}},
+		LookupEnv: lookupEnv(nil),
+	}
+
+	_, err := tmpl.Render("dashboard.yaml", []byte("kind: Dashboard\n\ndatasource: {{ .Values.datasource.uid }}\n"))
+	req.Error(err)
+
+	templateErr := local.TemplateError{}
+	req.ErrorAs(err, &templateErr)
+	req.Equal(3, templateErr.Line)
+	req.ErrorContains(err, "uid")
+}
+
+func TestFSReader_Read_template(t *testing.T) {
+	req := require.New(t)
+
+	dir := t.TempDir()
+	req.NoError(os.WriteFile(filepath.Join(dir, "folder.yaml"), []byte(`apiVersion: folder.grafana.app/v1
+kind: Folder
+metadata:
+  name: {{ .Values.name }}
+spec:
+  title: Team (${ENV})
+`), 0600))
+
+	reader := local.FSReader{
+		Decoders:    format.Codecs(),
+		StopOnError: true,
+		Template: &local.Template{
+			Values:    map[string]any{"name": "team"},
+			LookupEnv: lookupEnv(map[string]string{"ENV": "prod"}),
+		},
+	}
+
+	dst := resources.NewResources()
+	req.NoError(reader.Read(t.Context(), dst, nil, []string{dir}))
+
+	folder, ok := dst.Find("Folder", "team")
+	req.True(ok)
+
+	spec, err := folder.Spec()
+	req.NoError(err)
+	req.Equal(map[string]any{"title": "Team (prod)"}, spec)
+	req.True(folder.Source.Rendered)
+}
+
+func TestFSReader_ReadFile_templateWithoutPlaceholders(t *testing.T) {
+	req := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "folder.yaml")
+	req.NoError(os.WriteFile(path, []byte(`apiVersion: folder.grafana.app/v1
+kind: Folder
+metadata:
+  name: team
+spec:
+  title: Team
+`), 0600))
+
+	reader := local.FSReader{
+		Decoders: format.Codecs(),
+		Template: &local.Template{LookupEnv: lookupEnv(nil)},
+	}
+
+	folder := &resources.Resource{}
+	req.NoError(reader.ReadFile(t.Context(), folder, path))
+	req.False(folder.Source.Rendered)
+}
@@ -71,6 +71,38 @@ func (writer *FSWriter) Write(ctx context.Context, resources *resources.Resource
 	return nil
 }
 
+// Delete removes the files in which the given resources would be written.
+// Files that don't exist are ignored.
+func (writer *FSWriter) Delete(ctx context.Context, resources *resources.Resources) error {
+	logger := logging.FromContext(ctx).With(slog.String("path", writer.Path))
+	logger.Debug("Deleting resources", slog.Int("resources", resources.Len()))
+
+	for _, resource := range resources.AsList() {
+		if err := writer.deleteSingle(resource); err != nil {
+			if writer.StopOnError {
+				return err
+			}
+
+			logger.Warn("could not delete resource: skipping", slog.String("kind", resource.Kind()), logs.Err(err))
+		}
+	}
+
+	return nil
+}
+
+func (writer *FSWriter) deleteSingle(resource *resources.Resource) error {
+	filename, err := writer.Namer(resource)
+	if err != nil {
+		return fmt.Errorf("could not generate resource path: %w", err)
+	}
+
+	if err := os.Remove(filepath.Join(writer.Path, filename)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("could not delete resource file: %w", err)
+	}
+
+	return nil
+}
+
 func (writer *FSWriter) writeSingle(resource *resources.Resource) error {
 	filename, err := writer.Namer(resource)
 	if err != nil {
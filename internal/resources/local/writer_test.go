@@ -93,6 +93,29 @@ func TestFSWriter_Write_doesNothingWithNoResources(t *testing.T) {
 	req.NoDirExists(outputDir)
 }
 
+func TestFSWriter_Delete(t *testing.T) {
+	req := require.New(t)
+	outputDir := filepath.Join(t.TempDir(), "output")
+
+	writer := local.FSWriter{
+		Path:    outputDir,
+		Encoder: format.NewYAMLCodec(),
+		Namer:   local.GroupResourcesByKind("yaml"),
+	}
+
+	req.NoError(writer.Write(t.Context(), testResources()))
+
+	folder, ok := testResources().Find("Folder", "folder-uid")
+	req.True(ok)
+
+	req.NoError(writer.Delete(t.Context(), resources.NewResources(folder)))
+	req.NoFileExists(filepath.Join(outputDir, "Folder", "folder-uid.yaml"))
+	req.FileExists(filepath.Join(outputDir, "ServiceAccount", "sa-uid.yaml"))
+
+	// Deleting resources that were never written is a no-op.
+	req.NoError(writer.Delete(t.Context(), resources.NewResources(folder)))
+}
+
 func testResources() *resources.Resources {
 	res, err := resources.NewResourcesFromUnstructured(unstructured.UnstructuredList{
 		Items: []unstructured.Unstructured{
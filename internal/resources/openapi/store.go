@@ -0,0 +1,175 @@
+package openapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adrg/xdg"
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafanactl/internal/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+)
+
+const (
+	discoveryFile = "discovery.json"
+	schemasDir    = "schemas"
+)
+
+// ErrNoSchemas is returned when a store does not contain any schema.
+var ErrNoSchemas = errors.New("no OpenAPI schemas found")
+
+// Store is a directory holding the OpenAPI v3 schemas and the discovery
+// information of a Grafana instance, allowing resources to be validated offline.
+//
+// Layout:
+//
+//	discovery.json                       # API groups and resources
+//	schemas/apis/<group>/<version>.json  # OpenAPI v3 document of a group version
+type Store struct {
+	Dir string
+}
+
+// DefaultDir returns the directory in which the schemas of the given Grafana
+// server are cached.
+func DefaultDir(server string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSuffix(server, "/")))
+
+	return filepath.Join(xdg.CacheHome, config.StandardConfigFolder, "openapi", hex.EncodeToString(sum[:])[:16])
+}
+
+// Exists returns true if the store has been populated.
+func (store Store) Exists() bool {
+	_, err := os.Stat(filepath.Join(store.Dir, discoveryFile))
+
+	return err == nil
+}
+
+// Fetch downloads the discovery information and the OpenAPI v3 schemas from
+// the Grafana instance described by the given configuration, replacing the
+// content of the store.
+func (store Store) Fetch(ctx context.Context, cfg config.NamespacedRESTConfig) error {
+	logger := logging.FromContext(ctx).With(slog.String("component", "openapi_store"), slog.String("dir", store.Dir))
+
+	client, err := discovery.NewDiscoveryClientForConfig(&cfg.Config)
+	if err != nil {
+		return err
+	}
+
+	groups, resources, err := client.ServerGroupsAndResources()
+	if err != nil {
+		return err
+	}
+
+	paths, err := client.OpenAPIV3().Paths()
+	if err != nil {
+		return fmt.Errorf("could not list OpenAPI v3 schemas: %w", err)
+	}
+
+	if err := os.RemoveAll(filepath.Join(store.Dir, schemasDir)); err != nil {
+		return err
+	}
+
+	for path, groupVersion := range paths {
+		// Only API groups describe resources.
+		if !strings.HasPrefix(path, "apis/") {
+			continue
+		}
+
+		logger.Debug("Fetching OpenAPI schema", slog.String("path", path))
+
+		doc, err := groupVersion.Schema("application/json")
+		if err != nil {
+			return fmt.Errorf("could not fetch OpenAPI schema for '%s': %w", path, err)
+		}
+
+		if err := writeFile(filepath.Join(store.Dir, schemasDir, filepath.FromSlash(path)+".json"), doc); err != nil {
+			return err
+		}
+	}
+
+	raw, err := json.MarshalIndent(discoveryDocument{Groups: groups, Resources: resources}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// The discovery document is written last: it marks the store as complete.
+	return writeFile(filepath.Join(store.Dir, discoveryFile), raw)
+}
+
+// ServerGroupsAndResources returns the discovery information held by the store.
+// It allows the store to be used as a discovery.Client.
+func (store Store) ServerGroupsAndResources() ([]*metav1.APIGroup, []*metav1.APIResourceList, error) {
+	raw, err := os.ReadFile(filepath.Join(store.Dir, discoveryFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, ErrNoSchemas
+		}
+
+		return nil, nil, err
+	}
+
+	doc := discoveryDocument{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, nil, fmt.Errorf("could not parse '%s': %w", discoveryFile, err)
+	}
+
+	return doc.Groups, doc.Resources, nil
+}
+
+// Validator returns a validator using the schemas held by the store.
+func (store Store) Validator() (*Validator, error) {
+	validator := newValidator()
+
+	root := filepath.Join(store.Dir, schemasDir)
+	err := filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		if err := validator.load(raw); err != nil {
+			return fmt.Errorf("could not load OpenAPI schema '%s': %w", path, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNoSchemas
+		}
+
+		return nil, err
+	}
+
+	return validator, nil
+}
+
+type discoveryDocument struct {
+	Groups    []*metav1.APIGroup        `json:"groups"`
+	Resources []*metav1.APIResourceList `json:"resources"`
+}
+
+func writeFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content, 0644)
+}
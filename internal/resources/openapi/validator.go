@@ -0,0 +1,252 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/local"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+)
+
+const (
+	componentsRefPrefix = "#/components/schemas/"
+	gvkExtension        = "x-kubernetes-group-version-kind"
+)
+
+// Violation describes a field of a resource that does not conform to its schema.
+type Violation struct {
+	// Path is the JSON path of the invalid field. Example: $.spec.title
+	Path string
+	// Message describes the violation.
+	Message string
+	// Line is the 1-based line of the invalid field (or of its closest parent)
+	// in the source file of the resource. It is set to 0 when unknown.
+	Line int
+}
+
+// SchemaNotFoundError is returned when no schema describes a resource.
+type SchemaNotFoundError struct {
+	GVK schema.GroupVersionKind
+}
+
+func (e SchemaNotFoundError) Error() string {
+	return fmt.Sprintf("no OpenAPI schema found for %s", e.GVK)
+}
+
+// Validator validates resources against OpenAPI v3 schemas.
+type Validator struct {
+	// components of each loaded document, indexed by the GVK they describe.
+	documents map[schema.GroupVersionKind]map[string]*spec.Schema
+	// names of the schemas describing each GVK.
+	names map[schema.GroupVersionKind]string
+	// expanded holds schemas with resolved references.
+	expanded map[schema.GroupVersionKind]*spec.Schema
+}
+
+func newValidator() *Validator {
+	return &Validator{
+		documents: make(map[schema.GroupVersionKind]map[string]*spec.Schema),
+		names:     make(map[schema.GroupVersionKind]string),
+		expanded:  make(map[schema.GroupVersionKind]*spec.Schema),
+	}
+}
+
+func (v *Validator) load(raw []byte) error {
+	doc := &spec3.OpenAPI{}
+	if err := doc.UnmarshalJSON(raw); err != nil {
+		return err
+	}
+
+	if doc.Components == nil {
+		return nil
+	}
+
+	for name, component := range doc.Components.Schemas {
+		if component == nil {
+			continue
+		}
+
+		var gvks []schema.GroupVersionKind
+		if err := component.Extensions.GetObject(gvkExtension, &gvks); err != nil {
+			continue
+		}
+
+		for _, gvk := range gvks {
+			v.documents[gvk] = doc.Components.Schemas
+			v.names[gvk] = name
+		}
+	}
+
+	return nil
+}
+
+// Validate validates a resource against the schema of its GVK and returns
+// every violation found, sorted by path.
+// Lines are resolved from the source file of the resource, when available.
+func (v *Validator) Validate(res *resources.Resource) ([]Violation, error) {
+	gvk := res.GroupVersionKind()
+
+	resSchema, ok := v.schemaFor(gvk)
+	if !ok {
+		return nil, SchemaNotFoundError{GVK: gvk}
+	}
+
+	result := validate.NewSchemaValidator(resSchema, nil, "", strfmt.Default).Validate(res.Object.Object)
+
+	violations := make([]Violation, 0, len(result.Errors))
+	for _, err := range result.Errors {
+		violations = append(violations, toViolation(err))
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Path < violations[j].Path
+	})
+
+	if len(violations) != 0 {
+		locate(res, violations)
+	}
+
+	return violations, nil
+}
+
+func (v *Validator) schemaFor(gvk schema.GroupVersionKind) (*spec.Schema, bool) {
+	if expanded, ok := v.expanded[gvk]; ok {
+		return expanded, true
+	}
+
+	components, ok := v.documents[gvk]
+	if !ok {
+		return nil, false
+	}
+
+	expanded := expand(components[v.names[gvk]], components, map[string]bool{})
+	v.expanded[gvk] = expanded
+
+	return expanded, true
+}
+
+// expand returns a copy of the given schema in which references are replaced
+// by the schemas they point to, since the validator does not resolve them.
+// Recursive references are replaced by a schema accepting any value.
+func expand(s *spec.Schema, components map[string]*spec.Schema, visiting map[string]bool) *spec.Schema {
+	if s == nil {
+		return nil
+	}
+
+	if ref := s.Ref.String(); ref != "" {
+		name := strings.TrimPrefix(ref, componentsRefPrefix)
+		target, ok := components[name]
+		if !ok || visiting[name] {
+			return &spec.Schema{}
+		}
+
+		visiting[name] = true
+		defer delete(visiting, name)
+
+		return expand(target, components, visiting)
+	}
+
+	// References with sibling fields (default, description, ...) are wrapped
+	// in a single allOf: inline them, so that violations are reported on the
+	// fields themselves rather than on the allOf.
+	if len(s.AllOf) == 1 && len(s.Type) == 0 && s.Properties == nil && s.Items == nil {
+		inlined := *expand(&s.AllOf[0], components, visiting)
+		inlined.Nullable = inlined.Nullable || s.Nullable
+		if s.Default != nil {
+			inlined.Default = s.Default
+		}
+
+		return &inlined
+	}
+
+	out := *s
+
+	expandMap := func(schemas map[string]spec.Schema) map[string]spec.Schema {
+		if schemas == nil {
+			return nil
+		}
+
+		expanded := make(map[string]spec.Schema, len(schemas))
+		for name, child := range schemas {
+			expanded[name] = *expand(&child, components, visiting)
+		}
+
+		return expanded
+	}
+
+	expandSlice := func(schemas []spec.Schema) []spec.Schema {
+		if schemas == nil {
+			return nil
+		}
+
+		expanded := make([]spec.Schema, len(schemas))
+		for i := range schemas {
+			expanded[i] = *expand(&schemas[i], components, visiting)
+		}
+
+		return expanded
+	}
+
+	out.Properties = expandMap(s.Properties)
+	out.PatternProperties = expandMap(s.PatternProperties)
+	out.AllOf = expandSlice(s.AllOf)
+	out.AnyOf = expandSlice(s.AnyOf)
+	out.OneOf = expandSlice(s.OneOf)
+	out.Not = expand(s.Not, components, visiting)
+
+	if s.Items != nil {
+		out.Items = &spec.SchemaOrArray{
+			Schema:  expand(s.Items.Schema, components, visiting),
+			Schemas: expandSlice(s.Items.Schemas),
+		}
+	}
+
+	if s.AdditionalProperties != nil {
+		out.AdditionalProperties = &spec.SchemaOrBool{
+			Allows: s.AdditionalProperties.Allows,
+			Schema: expand(s.AdditionalProperties.Schema, components, visiting),
+		}
+	}
+
+	return &out
+}
+
+func toViolation(err error) Violation {
+	validationErr, ok := err.(*errors.Validation)
+	if !ok {
+		return Violation{Path: "$", Message: err.Error()}
+	}
+
+	name := strings.TrimPrefix(validationErr.Name, ".")
+	path := "$"
+	if name != "" {
+		path += "." + name
+	}
+
+	// Messages are prefixed by the name of the field, which is already
+	// described by the path.
+	message := validationErr.Error()
+	message = strings.TrimPrefix(message, validationErr.Name+" in "+validationErr.In+" ")
+	message = strings.TrimPrefix(message, name+" in "+validationErr.In+" ")
+
+	return Violation{Path: path, Message: message}
+}
+
+// locate resolves the line of each violation from the source file of the resource.
+func locate(res *resources.Resource, violations []Violation) {
+	paths := make([]string, len(violations))
+	for i, violation := range violations {
+		paths[i] = violation.Path
+	}
+
+	for i, line := range local.SourceLines(res, paths) {
+		violations[i].Line = line
+	}
+}
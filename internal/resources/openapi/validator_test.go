@@ -0,0 +1,147 @@
+package openapi_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/grafana/grafanactl/internal/resources/openapi"
+	"github.com/stretchr/testify/require"
+)
+
+func readResource(t *testing.T, name string, content string) *resources.Resource {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(file, []byte(content), 0600))
+
+	reader := local.FSReader{Decoders: format.Codecs()}
+
+	res := &resources.Resource{}
+	require.NoError(t, reader.ReadFile(t.Context(), res, file))
+
+	return res
+}
+
+func TestValidator_Validate(t *testing.T) {
+	req := require.New(t)
+
+	validator, err := openapi.Store{Dir: "testdata"}.Validator()
+	req.NoError(err)
+
+	res := readResource(t, "folder.yaml", `apiVersion: folder.grafana.app/v1
+kind: Folder
+metadata:
+  name: team
+  labels:
+    team: 42
+spec:
+  description: 12
+  tags:
+    - infra
+    - true
+`)
+
+	violations, err := validator.Validate(res)
+	req.NoError(err)
+	req.Equal([]openapi.Violation{
+		{Path: "$.metadata.labels.team", Message: "must be of type string: \"integer\"", Line: 6},
+		{Path: "$.spec.description", Message: "must be of type string: \"integer\"", Line: 8},
+		{Path: "$.spec.tags[1]", Message: "must be of type string: \"boolean\"", Line: 11},
+		{Path: "$.spec.title", Message: "is required", Line: 8},
+	}, violations)
+}
+
+func TestValidator_Validate_json(t *testing.T) {
+	req := require.New(t)
+
+	validator, err := openapi.Store{Dir: "testdata"}.Validator()
+	req.NoError(err)
+
+	res := readResource(t, "folder.json", `{
+  "apiVersion": "folder.grafana.app/v1",
+  "kind": "Folder",
+  "metadata": {
+    "name": "team"
+  },
+  "spec": {
+    "title": ["Team"]
+  }
+}
+`)
+
+	violations, err := validator.Validate(res)
+	req.NoError(err)
+	req.Equal([]openapi.Violation{
+		{Path: "$.spec.title", Message: "must be of type string: \"array\"", Line: 8},
+	}, violations)
+}
+
+func TestValidator_Validate_valid(t *testing.T) {
+	req := require.New(t)
+
+	validator, err := openapi.Store{Dir: "testdata"}.Validator()
+	req.NoError(err)
+
+	res := readResource(t, "folder.yaml", `apiVersion: folder.grafana.app/v1
+kind: Folder
+metadata:
+  name: team
+spec:
+  title: Team
+  parent:
+    title: Parent
+`)
+
+	violations, err := validator.Validate(res)
+	req.NoError(err)
+	req.Empty(violations)
+}
+
+func TestValidator_Validate_schemaNotFound(t *testing.T) {
+	req := require.New(t)
+
+	validator, err := openapi.Store{Dir: "testdata"}.Validator()
+	req.NoError(err)
+
+	res := readResource(t, "dashboard.yaml", `apiVersion: dashboard.grafana.app/v1
+kind: Dashboard
+metadata:
+  name: overview
+spec:
+  title: Overview
+`)
+
+	_, err = validator.Validate(res)
+	req.ErrorAs(err, &openapi.SchemaNotFoundError{})
+}
+
+func TestStore_ServerGroupsAndResources(t *testing.T) {
+	req := require.New(t)
+
+	store := openapi.Store{Dir: "testdata"}
+	req.True(store.Exists())
+
+	groups, resourceLists, err := store.ServerGroupsAndResources()
+	req.NoError(err)
+	req.Len(groups, 1)
+	req.Equal("folder.grafana.app", groups[0].Name)
+	req.Len(resourceLists, 1)
+	req.Equal("folders", resourceLists[0].APIResources[0].Name)
+}
+
+func TestStore_empty(t *testing.T) {
+	req := require.New(t)
+
+	store := openapi.Store{Dir: t.TempDir()}
+	req.False(store.Exists())
+
+	_, err := store.Validator()
+	req.ErrorIs(err, openapi.ErrNoSchemas)
+
+	_, _, err = store.ServerGroupsAndResources()
+	req.ErrorIs(err, openapi.ErrNoSchemas)
+}
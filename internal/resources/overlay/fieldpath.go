@@ -0,0 +1,154 @@
+package overlay
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const wildcard = "*"
+
+// getField returns the value found at the given dot-separated path.
+// When the path contains wildcards, the first matching value is returned.
+func getField(obj any, path string) (any, bool) {
+	return lookup(obj, splitFieldPath(path))
+}
+
+func lookup(obj any, segments []string) (any, bool) {
+	if len(segments) == 0 {
+		return obj, true
+	}
+
+	segment, rest := segments[0], segments[1:]
+
+	switch typed := obj.(type) {
+	case map[string]any:
+		if segment == wildcard {
+			for _, value := range typed {
+				if found, ok := lookup(value, rest); ok {
+					return found, true
+				}
+			}
+			return nil, false
+		}
+
+		value, ok := typed[segment]
+		if !ok {
+			return nil, false
+		}
+
+		return lookup(value, rest)
+	case []any:
+		if segment == wildcard {
+			for _, value := range typed {
+				if found, ok := lookup(value, rest); ok {
+					return found, true
+				}
+			}
+			return nil, false
+		}
+
+		idx, err := strconv.Atoi(segment)
+		if err != nil || idx < 0 || idx >= len(typed) {
+			return nil, false
+		}
+
+		return lookup(typed[idx], rest)
+	default:
+		return nil, false
+	}
+}
+
+// setField sets the value at the given dot-separated path.
+// Missing intermediate objects are created, except below wildcards:
+// wildcards only match existing values, and items that don't contain the
+// rest of the path are left untouched.
+func setField(obj map[string]any, path string, value any) error {
+	segments := splitFieldPath(path)
+	if len(segments) == 0 {
+		return errors.New("empty field path")
+	}
+
+	return assign(obj, segments, value, true)
+}
+
+func assign(obj any, segments []string, value any, create bool) error {
+	segment, rest := segments[0], segments[1:]
+
+	switch typed := obj.(type) {
+	case map[string]any:
+		if segment == wildcard {
+			for key := range typed {
+				if err := assignChild(typed[key], rest, value, false, func(v any) { typed[key] = v }); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		child, ok := typed[segment]
+		if !ok {
+			if !create {
+				return nil
+			}
+
+			if len(rest) == 0 {
+				typed[segment] = value
+				return nil
+			}
+
+			child = map[string]any{}
+			typed[segment] = child
+		}
+
+		return assignChild(child, rest, value, create, func(v any) { typed[segment] = v })
+	case []any:
+		if segment == wildcard {
+			for i := range typed {
+				if err := assignChild(typed[i], rest, value, false, func(v any) { typed[i] = v }); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		idx, err := strconv.Atoi(segment)
+		if err != nil {
+			return fmt.Errorf("invalid list index '%s'", segment)
+		}
+
+		if idx < 0 || idx >= len(typed) {
+			if !create {
+				return nil
+			}
+			return fmt.Errorf("list index %d out of range", idx)
+		}
+
+		return assignChild(typed[idx], rest, value, create, func(v any) { typed[idx] = v })
+	default:
+		if !create {
+			return nil
+		}
+
+		return fmt.Errorf("can not set field '%s' in a value of type %T", segment, obj)
+	}
+}
+
+func assignChild(child any, rest []string, value any, create bool, set func(any)) error {
+	if len(rest) == 0 {
+		set(value)
+		return nil
+	}
+
+	return assign(child, rest, value, create)
+}
+
+func splitFieldPath(path string) []string {
+	path = strings.Trim(path, ".")
+	if path == "" {
+		return nil
+	}
+
+	return strings.Split(path, ".")
+}
@@ -0,0 +1,219 @@
+package overlay
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// FileName is the name of the file describing an overlay.
+const FileName = "grafanactl.yaml"
+
+// Overlay describes how to render a set of resources: base resources are read
+// from disk, then patched and modified by replacements.
+//
+// Example:
+//
+//	resources:
+//	  - ../base
+//	patches:
+//	  - target:
+//	      kind: Dashboard
+//	      name: overview
+//	    patch: |
+//	      spec:
+//	        title: Overview (prod)
+//	replacements:
+//	  - source:
+//	      value: prometheus-prod
+//	    targets:
+//	      - select:
+//	          kind: Dashboard
+//	        fieldPaths:
+//	          - spec.panels.*.datasource.uid
+type Overlay struct {
+	// Resources lists the paths from which to read the resources, relative to
+	// the overlay file. Paths can point to files, directories or other overlays.
+	Resources []string `json:"resources" yaml:"resources"`
+
+	// Patches to apply on the resources.
+	Patches []Patch `json:"patches,omitempty" yaml:"patches,omitempty"`
+
+	// Replacements copy values from a source to fields of the resources.
+	// They are applied after the patches.
+	Replacements []Replacement `json:"replacements,omitempty" yaml:"replacements,omitempty"`
+
+	// dir is the directory containing the overlay file.
+	dir string
+}
+
+// Patch describes a patch applied to every resource matched by Target.
+//
+// The type of the patch is inferred from its content: a list of operations is
+// applied as a JSON patch (RFC 6902), an object is applied as a JSON merge
+// patch (RFC 7386).
+type Patch struct {
+	// Target selects the resources to patch. All resources are patched if not set.
+	Target *Target `json:"target,omitempty" yaml:"target,omitempty"`
+
+	// Patch is an inline patch, in JSON or YAML.
+	Patch string `json:"patch,omitempty" yaml:"patch,omitempty"`
+
+	// Path to a file containing the patch, relative to the overlay file.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// Replacement copies a value from a source into fields of the target resources.
+type Replacement struct {
+	Source  ReplacementSource   `json:"source" yaml:"source"`
+	Targets []ReplacementTarget `json:"targets" yaml:"targets"`
+}
+
+// ReplacementSource describes where the value of a replacement comes from:
+// either a literal value, or a field of a resource.
+type ReplacementSource struct {
+	// Value is a literal value.
+	Value any `json:"value,omitempty" yaml:"value,omitempty"`
+
+	// Target selects the resource to read the value from.
+	// The selection must match a single resource.
+	Target `json:",inline" yaml:",inline"`
+
+	// FieldPath is the path of the field to read, in the source resource.
+	FieldPath string `json:"fieldPath,omitempty" yaml:"fieldPath,omitempty"`
+}
+
+// ReplacementTarget describes which fields of which resources receive the value
+// of a replacement.
+type ReplacementTarget struct {
+	// Select selects the resources to modify. All resources are modified if not set.
+	Select *Target `json:"select,omitempty" yaml:"select,omitempty"`
+
+	// FieldPaths are the paths of the fields to modify.
+	// Paths are dot-separated, list items can be addressed by their index,
+	// and `*` matches every list item or map value.
+	// Example: spec.panels.*.datasource.uid
+	FieldPaths []string `json:"fieldPaths" yaml:"fieldPaths"`
+}
+
+// Target selects resources. Empty fields match every resource.
+type Target struct {
+	Group         string `json:"group,omitempty" yaml:"group,omitempty"`
+	Version       string `json:"version,omitempty" yaml:"version,omitempty"`
+	Kind          string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Name          string `json:"name,omitempty" yaml:"name,omitempty"`
+	LabelSelector string `json:"labelSelector,omitempty" yaml:"labelSelector,omitempty"`
+}
+
+// Matches returns true if the target matches the resource.
+func (t *Target) Matches(res *resources.Resource) (bool, error) {
+	if t == nil {
+		return true, nil
+	}
+
+	gvk := res.GroupVersionKind()
+
+	if t.Group != "" && t.Group != gvk.Group {
+		return false, nil
+	}
+
+	if t.Version != "" && t.Version != gvk.Version {
+		return false, nil
+	}
+
+	if t.Kind != "" && t.Kind != gvk.Kind {
+		return false, nil
+	}
+
+	if t.Name != "" && t.Name != res.Name() {
+		return false, nil
+	}
+
+	if t.LabelSelector != "" {
+		selector, err := labels.Parse(t.LabelSelector)
+		if err != nil {
+			return false, fmt.Errorf("invalid label selector '%s': %w", t.LabelSelector, err)
+		}
+
+		if !selector.Matches(labels.Set(res.Labels())) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// Find returns the path of the overlay file designated by the given path:
+// either the path itself if it is an overlay file, or the overlay file
+// contained in the directory it points to.
+func Find(path string) (string, bool) {
+	if filepath.Base(path) == FileName {
+		return path, true
+	}
+
+	candidate := filepath.Join(path, FileName)
+	if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+		return candidate, true
+	}
+
+	return "", false
+}
+
+// Load loads an overlay from the given file.
+func Load(file string) (*Overlay, error) {
+	handle, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer handle.Close()
+
+	overlay := &Overlay{}
+	if err := format.NewYAMLCodec().Decode(handle, overlay); err != nil {
+		return nil, InvalidOverlayError{File: file, Err: err}
+	}
+
+	if len(overlay.Resources) == 0 {
+		return nil, InvalidOverlayError{File: file, Err: errors.New("at least one resource path is required")}
+	}
+
+	for i, patch := range overlay.Patches {
+		if (patch.Patch == "") == (patch.Path == "") {
+			return nil, InvalidOverlayError{
+				File: file,
+				Err:  fmt.Errorf("patches[%d]: exactly one of 'patch' or 'path' must be set", i),
+			}
+		}
+	}
+
+	for i, replacement := range overlay.Replacements {
+		if replacement.Source.Value == nil && replacement.Source.FieldPath == "" {
+			return nil, InvalidOverlayError{
+				File: file,
+				Err:  fmt.Errorf("replacements[%d]: the source requires either a 'value' or a 'fieldPath'", i),
+			}
+		}
+	}
+
+	overlay.dir = filepath.Dir(file)
+
+	return overlay, nil
+}
+
+// InvalidOverlayError is returned when an overlay file can not be loaded or rendered.
+type InvalidOverlayError struct {
+	File string
+	Err  error
+}
+
+func (e InvalidOverlayError) Error() string {
+	return fmt.Sprintf("invalid overlay '%s': %s", e.File, e.Err)
+}
+
+func (e InvalidOverlayError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,300 @@
+package overlay_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/grafana/grafanactl/internal/resources/overlay"
+	"github.com/stretchr/testify/require"
+)
+
+const baseDashboard = `apiVersion: dashboard.grafana.app/v1
+kind: Dashboard
+metadata:
+  name: overview
+  namespace: default
+  labels:
+    team: infra
+spec:
+  title: Overview
+  panels:
+    - title: CPU
+      datasource:
+        uid: prometheus-dev
+    - title: Memory
+      datasource:
+        uid: prometheus-dev
+    - title: Text
+`
+
+const baseFolder = `apiVersion: folder.grafana.app/v1
+kind: Folder
+metadata:
+  name: team
+  namespace: default
+spec:
+  title: Team
+`
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+
+	return dir
+}
+
+func render(t *testing.T, paths ...string) (*resources.Resources, error) {
+	t.Helper()
+
+	renderer := overlay.Renderer{
+		Reader: &local.FSReader{
+			Decoders:    format.Codecs(),
+			StopOnError: true,
+		},
+	}
+
+	dst := resources.NewResources()
+	err := renderer.Read(t.Context(), dst, nil, paths)
+
+	return dst, err
+}
+
+func spec(t *testing.T, dst *resources.Resources, kind string, name string) map[string]any {
+	t.Helper()
+
+	res, ok := dst.Find(kind, name)
+	require.True(t, ok, "%s %s not found", kind, name)
+
+	spec, err := res.Spec()
+	require.NoError(t, err)
+
+	return spec.(map[string]any)
+}
+
+func TestRenderer_Read_mergePatch(t *testing.T) {
+	req := require.New(t)
+	dir := writeFiles(t, map[string]string{
+		"base/dashboard.yaml": baseDashboard,
+		"base/folder.yaml":    baseFolder,
+		"prod/grafanactl.yaml": `resources:
+  - ../base
+patches:
+  - target:
+      kind: Dashboard
+      name: overview
+    patch: |
+      spec:
+        title: Overview (prod)
+`,
+	})
+
+	dst, err := render(t, filepath.Join(dir, "prod"))
+	req.NoError(err)
+	req.Equal(2, dst.Len())
+
+	req.Equal("Overview (prod)", spec(t, dst, "Dashboard", "overview")["title"])
+	req.Equal("Team", spec(t, dst, "Folder", "team")["title"])
+
+	dashboard, _ := dst.Find("Dashboard", "overview")
+	req.Equal(filepath.Join(dir, "base", "dashboard.yaml"), dashboard.SourcePath())
+	req.Equal(filepath.Join(dir, "prod", overlay.FileName), dashboard.Source.Overlay)
+}
+
+func TestRenderer_Read_jsonPatchFromFile(t *testing.T) {
+	req := require.New(t)
+	dir := writeFiles(t, map[string]string{
+		"base/folder.yaml": baseFolder,
+		"prod/folder-patch.yaml": `- op: replace
+  path: /spec/title
+  value: Team (prod)
+- op: add
+  path: /metadata/labels
+  value:
+    env: prod
+`,
+		"prod/grafanactl.yaml": `resources:
+  - ../base/folder.yaml
+patches:
+  - target:
+      group: folder.grafana.app
+    path: folder-patch.yaml
+`,
+	})
+
+	dst, err := render(t, filepath.Join(dir, "prod", overlay.FileName))
+	req.NoError(err)
+
+	folder, ok := dst.Find("Folder", "team")
+	req.True(ok)
+	req.Equal(map[string]string{"env": "prod"}, folder.Labels())
+	req.Equal("Team (prod)", spec(t, dst, "Folder", "team")["title"])
+}
+
+func TestRenderer_Read_replacements(t *testing.T) {
+	req := require.New(t)
+	dir := writeFiles(t, map[string]string{
+		"base/dashboard.yaml": baseDashboard,
+		"base/folder.yaml":    baseFolder,
+		"prod/grafanactl.yaml": `resources:
+  - ../base
+replacements:
+  - source:
+      value: prometheus-prod
+    targets:
+      - select:
+          labelSelector: team=infra
+        fieldPaths:
+          - spec.panels.*.datasource.uid
+  - source:
+      kind: Dashboard
+      name: overview
+      fieldPath: spec.title
+    targets:
+      - select:
+          kind: Folder
+        fieldPaths:
+          - spec.title
+          - spec.description
+`,
+	})
+
+	dst, err := render(t, filepath.Join(dir, "prod"))
+	req.NoError(err)
+
+	panels := spec(t, dst, "Dashboard", "overview")["panels"].([]any)
+	req.Len(panels, 3)
+	req.Equal(map[string]any{"uid": "prometheus-prod"}, panels[0].(map[string]any)["datasource"])
+	req.Equal(map[string]any{"uid": "prometheus-prod"}, panels[1].(map[string]any)["datasource"])
+	// Wildcards only modify existing fields.
+	req.NotContains(panels[2].(map[string]any), "datasource")
+
+	folderSpec := spec(t, dst, "Folder", "team")
+	req.Equal("Overview", folderSpec["title"])
+	req.Equal("Overview", folderSpec["description"])
+}
+
+func TestRenderer_Read_nestedOverlays(t *testing.T) {
+	req := require.New(t)
+	dir := writeFiles(t, map[string]string{
+		"base/dashboard.yaml": baseDashboard,
+		"prod/grafanactl.yaml": `resources:
+  - ../base
+patches:
+  - patch: |
+      spec:
+        title: Overview (prod)
+`,
+		"prod-eu/grafanactl.yaml": `resources:
+  - ../prod
+patches:
+  - patch: |
+      metadata:
+        name: overview-eu
+`,
+	})
+
+	dst, err := render(t, filepath.Join(dir, "prod-eu"))
+	req.NoError(err)
+	req.Equal(1, dst.Len())
+
+	req.Equal("Overview (prod)", spec(t, dst, "Dashboard", "overview-eu")["title"])
+
+	dashboard, _ := dst.Find("Dashboard", "overview-eu")
+	req.Equal(filepath.Join(dir, "prod-eu", overlay.FileName), dashboard.Source.Overlay)
+}
+
+func TestRenderer_Read_plainPaths(t *testing.T) {
+	req := require.New(t)
+	dir := writeFiles(t, map[string]string{
+		"base/dashboard.yaml": baseDashboard,
+	})
+
+	dst, err := render(t, filepath.Join(dir, "base"))
+	req.NoError(err)
+
+	dashboard, ok := dst.Find("Dashboard", "overview")
+	req.True(ok)
+	req.Empty(dashboard.Source.Overlay)
+}
+
+func TestRenderer_Read_circularReference(t *testing.T) {
+	req := require.New(t)
+	dir := writeFiles(t, map[string]string{
+		"a/grafanactl.yaml": "resources: [../b]\n",
+		"b/grafanactl.yaml": "resources: [../a]\n",
+	})
+
+	_, err := render(t, filepath.Join(dir, "a"))
+	req.ErrorContains(err, "circular reference")
+}
+
+func TestLoad_invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{
+			name:    "no resources",
+			content: "patches: []\n",
+			err:     "at least one resource path is required",
+		},
+		{
+			name:    "unknown field",
+			content: "resources: [../base]\nunknown: true\n",
+			err:     "unknown",
+		},
+		{
+			name:    "patch without content",
+			content: "resources: [../base]\npatches:\n  - target:\n      kind: Dashboard\n",
+			err:     "exactly one of 'patch' or 'path' must be set",
+		},
+		{
+			name:    "replacement without source",
+			content: "resources: [../base]\nreplacements:\n  - targets:\n      - fieldPaths: [spec.title]\n",
+			err:     "the source requires either a 'value' or a 'fieldPath'",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := require.New(t)
+			dir := writeFiles(t, map[string]string{overlay.FileName: test.content})
+
+			_, err := overlay.Load(filepath.Join(dir, overlay.FileName))
+			req.ErrorContains(err, test.err)
+
+			invalidErr := overlay.InvalidOverlayError{}
+			req.ErrorAs(err, &invalidErr)
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	req := require.New(t)
+	dir := writeFiles(t, map[string]string{
+		"prod/grafanactl.yaml": "resources: [../base]\n",
+		"base/dashboard.yaml":  baseDashboard,
+	})
+
+	file, ok := overlay.Find(filepath.Join(dir, "prod"))
+	req.True(ok)
+	req.Equal(filepath.Join(dir, "prod", overlay.FileName), file)
+
+	file, ok = overlay.Find(filepath.Join(dir, "prod", overlay.FileName))
+	req.True(ok)
+	req.Equal(filepath.Join(dir, "prod", overlay.FileName), file)
+
+	_, ok = overlay.Find(filepath.Join(dir, "base"))
+	req.False(ok)
+}
@@ -0,0 +1,292 @@
+package overlay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/goccy/go-yaml"
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/local"
+	jsonpatch "gopkg.in/evanphx/json-patch.v4"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+)
+
+// Renderer renders overlays into resources.
+type Renderer struct {
+	// Reader used to read the resources referenced by overlays.
+	Reader *local.FSReader
+}
+
+// Read reads resources from the given paths, similarly to local.FSReader.Read.
+// Paths pointing to an overlay (see Find) are rendered in memory.
+func (renderer *Renderer) Read(
+	ctx context.Context, dst *resources.Resources, filters resources.Filters, paths []string,
+) error {
+	var plainPaths []string
+
+	for _, path := range paths {
+		file, ok := Find(path)
+		if !ok {
+			plainPaths = append(plainPaths, path)
+			continue
+		}
+
+		rendered := resources.NewResources()
+		if err := renderer.Render(ctx, rendered, file); err != nil {
+			return err
+		}
+
+		_ = rendered.ForEach(func(res *resources.Resource) error {
+			if filters.Matches(*res) {
+				dst.Add(res)
+			}
+			return nil
+		})
+	}
+
+	if len(plainPaths) == 0 {
+		return nil
+	}
+
+	return renderer.Reader.Read(ctx, dst, filters, plainPaths)
+}
+
+// Render renders the overlay described in the given file into dst.
+func (renderer *Renderer) Render(ctx context.Context, dst *resources.Resources, file string) error {
+	return renderer.render(ctx, dst, file, nil)
+}
+
+func (renderer *Renderer) render(ctx context.Context, dst *resources.Resources, file string, visited []string) error {
+	logger := logging.FromContext(ctx).With(slog.String("component", "overlay"), slog.String("file", file))
+	logger.Debug("Rendering overlay")
+
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+
+	for _, seen := range visited {
+		if seen == absFile {
+			return InvalidOverlayError{File: file, Err: errors.New("circular reference between overlays")}
+		}
+	}
+	visited = append(visited, absFile)
+
+	overlay, err := Load(file)
+	if err != nil {
+		return err
+	}
+
+	rendered := resources.NewResources()
+	for _, path := range overlay.Resources {
+		path = overlay.resolve(path)
+
+		if base, ok := Find(path); ok {
+			if err := renderer.render(ctx, rendered, base, visited); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := renderer.Reader.Read(ctx, rendered, nil, []string{path}); err != nil {
+			return err
+		}
+	}
+
+	list := rendered.AsList()
+
+	for i, patch := range overlay.Patches {
+		if err := overlay.applyPatch(patch, list); err != nil {
+			return InvalidOverlayError{File: file, Err: fmt.Errorf("patches[%d]: %w", i, err)}
+		}
+	}
+
+	for i, replacement := range overlay.Replacements {
+		if err := applyReplacement(replacement, list); err != nil {
+			return InvalidOverlayError{File: file, Err: fmt.Errorf("replacements[%d]: %w", i, err)}
+		}
+	}
+
+	for _, res := range list {
+		// Keep track of the outermost overlay the resource was rendered from.
+		source := res.Source
+		source.Overlay = file
+		res.SetSource(source)
+	}
+
+	// Patches can modify the name of the resources, which means that their
+	// reference in the collection might have changed: add them again.
+	dst.Add(list...)
+
+	return nil
+}
+
+func (overlay *Overlay) resolve(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(overlay.dir, path)
+}
+
+func (overlay *Overlay) applyPatch(patch Patch, list []*resources.Resource) error {
+	raw := []byte(patch.Patch)
+	if patch.Path != "" {
+		contents, err := os.ReadFile(overlay.resolve(patch.Path))
+		if err != nil {
+			return err
+		}
+		raw = contents
+	}
+
+	// Patches can be written in YAML: normalize them to JSON.
+	var decoded any
+	if err := yaml.Unmarshal(raw, &decoded); err != nil {
+		return fmt.Errorf("could not parse patch: %w", err)
+	}
+
+	patchJSON, err := json.Marshal(decoded)
+	if err != nil {
+		return err
+	}
+
+	var apply func(doc []byte) ([]byte, error)
+	switch decoded.(type) {
+	case []any:
+		ops, err := jsonpatch.DecodePatch(patchJSON)
+		if err != nil {
+			return fmt.Errorf("could not parse JSON patch: %w", err)
+		}
+		apply = ops.Apply
+	case map[string]any:
+		apply = func(doc []byte) ([]byte, error) {
+			return jsonpatch.MergePatch(doc, patchJSON)
+		}
+	default:
+		return errors.New("a patch must either be a list of JSON patch operations or a JSON merge patch object")
+	}
+
+	for _, res := range list {
+		matches, err := patch.Target.Matches(res)
+		if err != nil {
+			return err
+		}
+		if !matches {
+			continue
+		}
+
+		doc, err := res.Object.MarshalJSON()
+		if err != nil {
+			return err
+		}
+
+		patched, err := apply(doc)
+		if err != nil {
+			return fmt.Errorf("could not patch %s: %w", res.Ref(), err)
+		}
+
+		if err := setJSON(res, patched); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func applyReplacement(replacement Replacement, list []*resources.Resource) error {
+	value, err := normalizeValue(replacement.Source.Value)
+	if err != nil {
+		return err
+	}
+
+	if replacement.Source.FieldPath != "" {
+		var source *resources.Resource
+		for _, res := range list {
+			matches, err := replacement.Source.Matches(res)
+			if err != nil {
+				return err
+			}
+			if !matches {
+				continue
+			}
+
+			if source != nil {
+				return errors.New("the source matches more than one resource")
+			}
+			source = res
+		}
+
+		if source == nil {
+			return errors.New("the source does not match any resource")
+		}
+
+		found, ok := getField(source.Object.Object, replacement.Source.FieldPath)
+		if !ok {
+			return fmt.Errorf("field '%s' not found in %s", replacement.Source.FieldPath, source.Ref())
+		}
+		value = found
+	}
+
+	for _, target := range replacement.Targets {
+		for _, res := range list {
+			matches, err := target.Select.Matches(res)
+			if err != nil {
+				return err
+			}
+			if !matches {
+				continue
+			}
+
+			obj := res.Object.DeepCopy()
+
+			for _, fieldPath := range target.FieldPaths {
+				if err := setField(obj.Object, fieldPath, value); err != nil {
+					return fmt.Errorf("could not set field '%s' in %s: %w", fieldPath, res.Ref(), err)
+				}
+			}
+
+			if err := res.SetUnstructured(obj); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// normalizeValue converts values decoded from YAML into values that can be
+// stored in unstructured objects (e.g. integers are stored as int64).
+func normalizeValue(value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalized any
+	if err := utiljson.Unmarshal(raw, &normalized); err != nil {
+		return nil, err
+	}
+
+	return normalized, nil
+}
+
+func setJSON(res *resources.Resource, raw []byte) error {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(bytes.TrimSpace(raw)); err != nil {
+		return err
+	}
+
+	return res.SetUnstructured(obj)
+}
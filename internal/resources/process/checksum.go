@@ -0,0 +1,61 @@
+package process
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafanactl/internal/resources"
+)
+
+// Checksum computes a checksum of the content of a resource: its kind, name,
+// labels, annotations and spec.
+//
+// Fields set by the server, the namespace and the manager and source properties
+// are ignored, so that the checksum of a resource is the same in a local file
+// and in Grafana once pushed.
+func Checksum(r *resources.Resource) (string, error) {
+	spec, err := r.Spec()
+	if err != nil {
+		return "", err
+	}
+
+	annotations := maps.Clone(r.Annotations())
+	delete(annotations, utils.AnnoKeyCreatedBy)
+	delete(annotations, utils.AnnoKeyUpdatedBy)
+	delete(annotations, utils.AnnoKeyUpdatedTimestamp)
+	maps.DeleteFunc(annotations, func(key string, _ string) bool {
+		return resources.IsReservedAnnotation(key)
+	})
+
+	labels := maps.Clone(r.Labels())
+	delete(labels, utils.LabelKeyDeprecatedInternalID)
+
+	content := map[string]any{
+		"apiVersion": r.APIVersion(),
+		"kind":       r.Kind(),
+		"name":       r.Name(),
+		"spec":       spec,
+	}
+
+	// Missing and empty maps are equivalent.
+	if len(annotations) != 0 {
+		content["annotations"] = annotations
+	}
+
+	if len(labels) != 0 {
+		content["labels"] = labels
+	}
+
+	// Keys of maps are sorted when encoded, which makes the encoding stable.
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(raw)
+
+	return hex.EncodeToString(sum[:]), nil
+}
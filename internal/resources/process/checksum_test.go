@@ -0,0 +1,69 @@
+package process_test
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/process"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksum(t *testing.T) {
+	req := require.New(t)
+
+	local := resources.MustFromObject(map[string]any{
+		"apiVersion": "dashboard.grafana.app/v1",
+		"kind":       "Dashboard",
+		"metadata": map[string]any{
+			"name":      "example",
+			"namespace": "default",
+			"labels":    map[string]any{"team": "platform"},
+		},
+		"spec": map[string]any{
+			"title": "example",
+		},
+	}, resources.SourceInfo{})
+
+	// The same resource, once pushed to Grafana.
+	remote := resources.MustFromObject(map[string]any{
+		"apiVersion": "dashboard.grafana.app/v1",
+		"kind":       "Dashboard",
+		"metadata": map[string]any{
+			"name":            "example",
+			"namespace":       "stacks-12",
+			"resourceVersion": "42",
+			"labels":          map[string]any{"team": "platform"},
+			"annotations": map[string]any{
+				utils.AnnoKeyManagerKind:      string(resources.ResourceManagerKind),
+				utils.AnnoKeyManagerIdentity:  resources.ResourceManagerIdentity,
+				utils.AnnoKeySourceChecksum:   "abc",
+				utils.AnnoKeyUpdatedBy:        "user:1",
+				utils.AnnoKeyUpdatedTimestamp: "2025-01-01T00:00:00Z",
+			},
+		},
+		"spec": map[string]any{
+			"title": "example",
+		},
+		"status": map[string]any{},
+	}, resources.SourceInfo{})
+
+	localChecksum, err := process.Checksum(local)
+	req.NoError(err)
+	req.Len(localChecksum, 64)
+
+	remoteChecksum, err := process.Checksum(remote)
+	req.NoError(err)
+	req.Equal(localChecksum, remoteChecksum)
+
+	// Changes to the spec, labels or annotations are detected.
+	remote.Object.Object["spec"] = map[string]any{"title": "changed"}
+	changedSpec, err := process.Checksum(remote)
+	req.NoError(err)
+	req.NotEqual(localChecksum, changedSpec)
+
+	local.Object.SetLabels(map[string]string{"team": "other"})
+	changedLabels, err := process.Checksum(local)
+	req.NoError(err)
+	req.NotEqual(localChecksum, changedLabels)
+}
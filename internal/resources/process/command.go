@@ -0,0 +1,133 @@
+package process
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/grafana/grafanactl/internal/resources"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// CommandRunner is a processor that transforms resources with an external command.
+// The command is given the resource as JSON on its standard input, and must
+// write the transformed resource as JSON on its standard output.
+type CommandRunner struct {
+	command []string
+}
+
+// NewCommandRunner creates a new CommandRunner for the given command and arguments.
+func NewCommandRunner(command []string) (*CommandRunner, error) {
+	if len(command) == 0 || command[0] == "" {
+		return nil, errors.New("a command is required")
+	}
+
+	return &CommandRunner{command: command}, nil
+}
+
+// Process replaces the resource with the output of the command.
+// If the resource is empty, it returns immediately without error.
+func (c *CommandRunner) Process(r *resources.Resource) error {
+	if r.IsEmpty() {
+		return nil
+	}
+
+	input, err := json.Marshal(r.Object.Object)
+	if err != nil {
+		return err
+	}
+
+	//nolint:gosec
+	cmd := exec.Command(c.command[0], c.command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return fmt.Errorf("command %s failed: %w: %s", c.command[0], err, message)
+		}
+
+		return fmt.Errorf("command %s failed: %w", c.command[0], err)
+	}
+
+	// Decoding through unstructured keeps integers as such.
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(output); err != nil {
+		return fmt.Errorf("command %s must output a resource: %w", c.command[0], err)
+	}
+
+	return r.SetUnstructured(obj)
+}
+
+// SplitCommand splits a command line into a command and its arguments,
+// following the quoting rules of POSIX shells: arguments can be quoted with
+// single or double quotes, and characters escaped with backslashes.
+// Other shell features, such as variables or pipes, aren't supported.
+func SplitCommand(line string) ([]string, error) {
+	var (
+		words   []string
+		current strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, char := range line {
+		switch {
+		case escaped:
+			// In double quotes, backslashes only escape a few characters.
+			if quote == '"' && !strings.ContainsRune("\"\\$`", char) {
+				current.WriteRune('\\')
+			}
+			current.WriteRune(char)
+			escaped = false
+		case quote == '\'':
+			if char == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(char)
+			}
+		case char == '\\':
+			escaped = true
+			inWord = true
+		case quote == '"':
+			if char == '"' {
+				quote = 0
+			} else {
+				current.WriteRune(char)
+			}
+		case char == '\'' || char == '"':
+			quote = char
+			inWord = true
+		case char == ' ' || char == '\t' || char == '\n':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(char)
+			inWord = true
+		}
+	}
+
+	if escaped {
+		return nil, errors.New("unterminated escape sequence")
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+
+	if inWord {
+		words = append(words, current.String())
+	}
+
+	return words, nil
+}
@@ -0,0 +1,65 @@
+package process_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/grafana/grafanactl/internal/resources/process"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandRunner(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	req := require.New(t)
+
+	args, err := process.SplitCommand(`sed 's/"example"/"renamed"/g'`)
+	req.NoError(err)
+
+	runner, err := process.NewCommandRunner(args)
+	req.NoError(err)
+
+	res := testDashboard()
+	req.NoError(runner.Process(res))
+	req.Equal("renamed", res.Name())
+	req.Equal(int64(41), res.Object.Object["spec"].(map[string]any)["schemaVersion"])
+
+	failing, err := process.NewCommandRunner([]string{"sh", "-c", "echo boom >&2; exit 1"})
+	req.NoError(err)
+	req.ErrorContains(failing.Process(testDashboard()), "boom")
+
+	_, err = process.NewCommandRunner(nil)
+	req.Error(err)
+}
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{line: "jq .spec", want: []string{"jq", ".spec"}},
+		{line: "  jq   .spec  ", want: []string{"jq", ".spec"}},
+		{line: "jq '.spec.title |= ascii_upcase'", want: []string{"jq", ".spec.title |= ascii_upcase"}},
+		{line: `jq ".spec.title = \"a \\\"b\\\"\""`, want: []string{"jq", `.spec.title = "a \"b\""`}},
+		{line: `jq ".spec.title = \"$\n\""`, want: []string{"jq", `.spec.title = "$\n"`}},
+		{line: `jq a\ b ''`, want: []string{"jq", "a b", ""}},
+		{line: `jq 'a'"b"c`, want: []string{"jq", "abc"}},
+		{line: "", want: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			words, err := process.SplitCommand(test.line)
+			require.NoError(t, err)
+			require.Equal(t, test.want, words)
+		})
+	}
+
+	_, err := process.SplitCommand("jq '.spec")
+	require.ErrorContains(t, err, "unterminated")
+
+	_, err = process.SplitCommand(`jq \`)
+	require.ErrorContains(t, err, "unterminated")
+}
@@ -0,0 +1,72 @@
+package process
+
+import (
+	"github.com/grafana/grafanactl/internal/resources"
+)
+
+// DatasourceUIDMapper is a processor that rewrites the UIDs of the datasources
+// referenced by resources, through a mapping table.
+//
+// It can be used to push resources to an instance in which datasources
+// have different UIDs, e.g. "prometheus-dev" in development and
+// "prometheus-prod" in production.
+type DatasourceUIDMapper struct {
+	uids map[string]string
+}
+
+// NewDatasourceUIDMapper creates a new DatasourceUIDMapper rewriting the
+// datasource UIDs that are keys of the mapping to their values.
+func NewDatasourceUIDMapper(uids map[string]string) *DatasourceUIDMapper {
+	return &DatasourceUIDMapper{
+		uids: uids,
+	}
+}
+
+// Process rewrites the datasource references found in the spec of the resource:
+// "datasource" fields holding either a UID, or an object with a "uid" field.
+// Datasources served by the legacy HTTP API are renamed, as their name is their UID.
+// If the resource is empty, it returns immediately without error.
+func (m *DatasourceUIDMapper) Process(r *resources.Resource) error {
+	if r.IsEmpty() {
+		return nil
+	}
+
+	if r.Group() == resources.LegacyGroup && r.Kind() == "DataSource" {
+		if uid, ok := m.uids[r.Name()]; ok {
+			r.Object.SetName(uid)
+		}
+	}
+
+	m.rewrite(r.Object.Object["spec"])
+
+	return nil
+}
+
+func (m *DatasourceUIDMapper) rewrite(value any) {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, item := range typed {
+			if key != "datasource" {
+				m.rewrite(item)
+				continue
+			}
+
+			switch ref := item.(type) {
+			case string:
+				if uid, ok := m.uids[ref]; ok {
+					typed[key] = uid
+				}
+			case map[string]any:
+				if current, ok := ref["uid"].(string); ok {
+					if uid, ok := m.uids[current]; ok {
+						ref["uid"] = uid
+					}
+				}
+			}
+		}
+	case []any:
+		for _, item := range typed {
+			m.rewrite(item)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package process_test
+
+import (
+	"testing"
+
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/process"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatasourceUIDMapper(t *testing.T) {
+	req := require.New(t)
+
+	mapper := process.NewDatasourceUIDMapper(map[string]string{"old": "new", "prom-dev": "prom-prod"})
+
+	res := testDashboard()
+	res.Object.Object["spec"].(map[string]any)["templating"] = map[string]any{
+		"list": []any{map[string]any{"datasource": "prom-dev"}},
+	}
+
+	req.NoError(mapper.Process(res))
+	req.Equal([]any{
+		map[string]any{"datasource": map[string]any{"uid": "new"}},
+		map[string]any{"datasource": map[string]any{"uid": "other"}},
+	}, res.Object.Object["spec"].(map[string]any)["panels"])
+	req.Equal(map[string]any{
+		"list": []any{map[string]any{"datasource": "prom-prod"}},
+	}, res.Object.Object["spec"].(map[string]any)["templating"])
+
+	// Datasources served by the legacy HTTP API are named after their UID.
+	datasource := resources.MustFromObject(map[string]any{
+		"apiVersion": "legacy.grafana.app/v0",
+		"kind":       "DataSource",
+		"metadata":   map[string]any{"name": "prom-dev"},
+		"spec":       map[string]any{"type": "prometheus"},
+	}, resources.SourceInfo{})
+
+	req.NoError(mapper.Process(datasource))
+	req.Equal("prom-prod", datasource.Name())
+}
@@ -0,0 +1,25 @@
+package process
+
+import (
+	"github.com/grafana/grafanactl/internal/resources"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// IDStripper is a processor that strips the numeric database id from the
+// spec of resources, as found in dashboards.
+//
+// These ids are specific to a Grafana instance: keeping them in files
+// leads to conflicts when resources are pushed to another instance.
+type IDStripper struct{}
+
+// Process removes the id field from the spec of the resource.
+// If the resource is empty, it returns immediately without error.
+func (s *IDStripper) Process(r *resources.Resource) error {
+	if r.IsEmpty() {
+		return nil
+	}
+
+	unstructured.RemoveNestedField(r.Object.Object, "spec", "id")
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package process_test
+
+import (
+	"testing"
+
+	"github.com/grafana/grafanactl/internal/resources/process"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDStripper(t *testing.T) {
+	req := require.New(t)
+
+	res := testDashboard()
+	res.Object.Object["spec"].(map[string]any)["id"] = int64(42)
+
+	req.NoError((&process.IDStripper{}).Process(res))
+
+	spec := res.Object.Object["spec"].(map[string]any)
+	req.NotContains(spec, "id")
+	req.Equal("example", spec["title"])
+}
@@ -0,0 +1,97 @@
+package process
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/itchyny/gojq"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// JQTransformer is a processor that transforms resources with a jq expression.
+// The expression is given the resource as input, and must output exactly one
+// object: the transformed resource.
+type JQTransformer struct {
+	code *gojq.Code
+}
+
+// NewJQTransformer compiles the given jq expression into a JQTransformer.
+func NewJQTransformer(expr string) (*JQTransformer, error) {
+	query, err := gojq.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jq expression: %w", err)
+	}
+
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jq expression: %w", err)
+	}
+
+	return &JQTransformer{code: code}, nil
+}
+
+// Process replaces the resource with the output of the expression.
+// If the resource is empty, it returns immediately without error.
+func (t *JQTransformer) Process(r *resources.Resource) error {
+	if r.IsEmpty() {
+		return nil
+	}
+
+	input, err := jqInput(r.Object.Object)
+	if err != nil {
+		return err
+	}
+
+	iter := t.code.Run(input)
+
+	output, ok := iter.Next()
+	if !ok {
+		return errors.New("the jq expression produced no output")
+	}
+
+	if err, ok := output.(error); ok {
+		return err
+	}
+
+	if _, ok := iter.Next(); ok {
+		return errors.New("the jq expression produced more than one output")
+	}
+
+	if _, ok := output.(map[string]any); !ok {
+		return fmt.Errorf("the jq expression must output an object, got %s", gojq.TypeOf(output))
+	}
+
+	raw, err := json.Marshal(output)
+	if err != nil {
+		return err
+	}
+
+	// Decoding through unstructured keeps integers as such.
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(raw); err != nil {
+		return fmt.Errorf("the jq expression must output a resource: %w", err)
+	}
+
+	return r.SetUnstructured(obj)
+}
+
+// jqInput converts an object to the generic representation expected by gojq.
+func jqInput(object map[string]any) (any, error) {
+	raw, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var input any
+	if err := decoder.Decode(&input); err != nil {
+		return nil, err
+	}
+
+	return input, nil
+}
@@ -0,0 +1,76 @@
+package process_test
+
+import (
+	"testing"
+
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/process"
+	"github.com/stretchr/testify/require"
+)
+
+func testDashboard() *resources.Resource {
+	return resources.MustFromObject(map[string]any{
+		"apiVersion": "dashboard.grafana.app/v1",
+		"kind":       "Dashboard",
+		"metadata": map[string]any{
+			"name": "example",
+		},
+		"spec": map[string]any{
+			"title":         "example",
+			"schemaVersion": int64(41),
+			"panels": []any{
+				map[string]any{"datasource": map[string]any{"uid": "old"}},
+				map[string]any{"datasource": map[string]any{"uid": "other"}},
+			},
+		},
+	}, resources.SourceInfo{Path: "dashboards/example.yaml"})
+}
+
+func TestJQTransformer(t *testing.T) {
+	req := require.New(t)
+
+	transformer, err := process.NewJQTransformer(
+		`(.spec.panels[].datasource | select(.uid == "old") | .uid) = "new" | .spec.title |= ascii_upcase`,
+	)
+	req.NoError(err)
+
+	res := testDashboard()
+	req.NoError(transformer.Process(res))
+
+	req.Equal(map[string]any{
+		"title":         "EXAMPLE",
+		"schemaVersion": int64(41),
+		"panels": []any{
+			map[string]any{"datasource": map[string]any{"uid": "new"}},
+			map[string]any{"datasource": map[string]any{"uid": "other"}},
+		},
+	}, res.Object.Object["spec"])
+	req.Equal("example", res.Name())
+	req.Equal("dashboards/example.yaml", res.SourcePath())
+}
+
+func TestJQTransformer_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr string
+	}{
+		{name: "no output", expr: `empty`, wantErr: "no output"},
+		{name: "several outputs", expr: `., .`, wantErr: "more than one output"},
+		{name: "not an object", expr: `.spec.title`, wantErr: "must output an object"},
+		{name: "runtime error", expr: `error("boom")`, wantErr: "boom"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			transformer, err := process.NewJQTransformer(tc.expr)
+			require.NoError(t, err)
+
+			err = transformer.Process(testDashboard())
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+
+	_, err := process.NewJQTransformer(`.spec |`)
+	require.ErrorContains(t, err, "invalid jq expression")
+}
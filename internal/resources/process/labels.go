@@ -0,0 +1,49 @@
+package process
+
+import (
+	"maps"
+
+	"github.com/grafana/grafanactl/internal/resources"
+)
+
+// LabelSetter is a processor that sets and removes labels on resources.
+type LabelSetter struct {
+	set    map[string]string
+	remove []string
+}
+
+// NewLabelSetter creates a new LabelSetter that will set the given labels on
+// all processed resources, and remove the given ones.
+func NewLabelSetter(set map[string]string, remove []string) *LabelSetter {
+	return &LabelSetter{
+		set:    set,
+		remove: remove,
+	}
+}
+
+// Process sets and removes the labels of the resource.
+// If the resource is empty, it returns immediately without error.
+func (l *LabelSetter) Process(r *resources.Resource) error {
+	if r.IsEmpty() {
+		return nil
+	}
+
+	labels := r.Object.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string, len(l.set))
+	}
+
+	maps.Copy(labels, l.set)
+
+	for _, key := range l.remove {
+		delete(labels, key)
+	}
+
+	if len(labels) == 0 {
+		labels = nil
+	}
+
+	r.Object.SetLabels(labels)
+
+	return nil
+}
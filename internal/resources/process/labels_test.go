@@ -0,0 +1,27 @@
+package process_test
+
+import (
+	"testing"
+
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/process"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelSetter(t *testing.T) {
+	req := require.New(t)
+
+	res := testDashboard()
+	res.Object.SetLabels(map[string]string{"team": "ops", "tmp": "true"})
+
+	setter := process.NewLabelSetter(map[string]string{"env": "prod", "team": "sre"}, []string{"tmp"})
+	req.NoError(setter.Process(res))
+	req.Equal(map[string]string{"env": "prod", "team": "sre"}, res.Labels())
+
+	// Removing the last labels doesn't leave an empty map behind.
+	req.NoError(process.NewLabelSetter(nil, []string{"env", "team"}).Process(res))
+	_, found := res.Object.Object["metadata"].(map[string]any)["labels"]
+	req.False(found)
+
+	req.NoError(setter.Process(&resources.Resource{}))
+}
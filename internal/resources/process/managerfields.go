@@ -6,6 +6,7 @@ import (
 )
 
 // ManagerFieldsAppender is a processor that appends manager and source fields to a resource.
+// The source checksum is the Checksum of the resource.
 // It will return an error if the resource is already managed by another manager.
 type ManagerFieldsAppender struct {
 }
@@ -23,12 +24,19 @@ func (m *ManagerFieldsAppender) Process(r *resources.Resource) error {
 
 	r.Raw.SetManagerProperties(utils.ManagerProperties{
 		Kind:     resources.ResourceManagerKind,
-		Identity: "grafanactl", // TODO: use version information to set the identity.
+		Identity: resources.ResourceManagerIdentity,
 	})
 
-	// TODO: should we set timestamp & checksum as well?
+	// The checksum allows detecting changes made on either side since the push.
+	checksum, err := Checksum(r)
+	if err != nil {
+		return err
+	}
+
+	// TODO: should we set the timestamp as well?
 	r.Raw.SetSourceProperties(utils.SourceProperties{
-		Path: r.Source.String(),
+		Path:     r.Source.String(),
+		Checksum: checksum,
 	})
 
 	return nil
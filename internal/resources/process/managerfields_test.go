@@ -16,6 +16,8 @@ func TestManagerFieldsAppender(t *testing.T) {
 		input   *resources.Resource
 		want    unstructured.Unstructured
 		wantErr bool
+		// If set, the checksum of the input is expected in the annotations.
+		wantChecksum bool
 	}{
 		{
 			name:    "empty resource",
@@ -59,7 +61,8 @@ func TestManagerFieldsAppender(t *testing.T) {
 					},
 				},
 			},
-			wantErr: false,
+			wantErr:      false,
+			wantChecksum: true,
 		},
 		{
 			name: "resource managed by Terraform",
@@ -140,12 +143,21 @@ func TestManagerFieldsAppender(t *testing.T) {
 					},
 				},
 			},
-			wantErr: false,
+			wantErr:      false,
+			wantChecksum: true,
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
+			if test.wantChecksum {
+				checksum, err := process.Checksum(test.input)
+				require.NoError(t, err)
+
+				annotations := test.want.Object["metadata"].(map[string]any)["annotations"].(map[string]any)
+				annotations[utils.AnnoKeySourceChecksum] = checksum
+			}
+
 			appender := &process.ManagerFieldsAppender{}
 			err := appender.Process(test.input)
 			if test.wantErr {
@@ -10,13 +10,19 @@ import (
 	"github.com/grafana/grafanactl/internal/resources"
 	"github.com/grafana/grafanactl/internal/resources/discovery"
 	"github.com/grafana/grafanactl/internal/resources/dynamic"
+	"github.com/grafana/grafanactl/internal/resources/journal"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // DeleteClient is a client that can delete resources from Grafana.
 type DeleteClient interface {
 	Delete(ctx context.Context, desc resources.Descriptor, name string, opts metav1.DeleteOptions) error
+
+	Get(
+		ctx context.Context, desc resources.Descriptor, name string, opts metav1.GetOptions,
+	) (*unstructured.Unstructured, error)
 }
 
 // Deleter takes care of deleting resources from Grafana.
@@ -27,7 +33,12 @@ type Deleter struct {
 
 // NewDeleter creates a new Deleter.
 func NewDeleter(ctx context.Context, cfg config.NamespacedRESTConfig) (*Deleter, error) {
-	cli, err := dynamic.NewDefaultNamespacedClient(cfg)
+	kubernetes, err := dynamic.NewDefaultNamespacedClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	cli, err := withLegacyClient(cfg, kubernetes)
 	if err != nil {
 		return nil, err
 	}
@@ -65,6 +76,10 @@ type DeleteRequest struct {
 
 	// If set to true, the deleter will simulate the delete operations.
 	DryRun bool
+
+	// Recorder, if set, records the previous state of every resource deleted.
+	// Nothing is recorded in dry-run mode.
+	Recorder ChangeRecorder
 }
 
 func (deleter *Deleter) Delete(ctx context.Context, request DeleteRequest) (*OperationSummary, error) {
@@ -95,7 +110,7 @@ func (deleter *Deleter) Delete(ctx context.Context, request DeleteRequest) (*Ope
 				return nil
 			}
 
-			if err := deleter.deleteResource(ctx, desc, res, request.DryRun); err != nil {
+			if err := deleter.deleteResource(ctx, desc, res, request); err != nil {
 				summary.RecordFailure(res, err)
 				if request.StopOnError {
 					return err
@@ -117,15 +132,35 @@ func (deleter *Deleter) Delete(ctx context.Context, request DeleteRequest) (*Ope
 	return summary, nil
 }
 
-func (deleter *Deleter) deleteResource(ctx context.Context, descriptor resources.Descriptor, res *resources.Resource, dryRun bool) error {
+func (deleter *Deleter) deleteResource(
+	ctx context.Context, descriptor resources.Descriptor, res *resources.Resource, request DeleteRequest,
+) error {
 	var dryRunOpts []string
-	if dryRun {
+	if request.DryRun {
 		dryRunOpts = []string{"All"}
 	}
 
-	return deleter.client.Delete(ctx, descriptor, res.Name(), metav1.DeleteOptions{
+	// The resource to delete might have been read from a file:
+	// its previous state is fetched from Grafana.
+	var previous *unstructured.Unstructured
+	if request.Recorder != nil && !request.DryRun {
+		var err error
+		if previous, err = deleter.client.Get(ctx, descriptor, res.Name(), metav1.GetOptions{}); err != nil {
+			return err
+		}
+	}
+
+	if err := deleter.client.Delete(ctx, descriptor, res.Name(), metav1.DeleteOptions{
 		DryRun: dryRunOpts,
-	})
+	}); err != nil {
+		return err
+	}
+
+	if previous != nil {
+		recordChange(request.Recorder, journal.ActionDeleted, res, previous)
+	}
+
+	return nil
 }
 
 func (deleter *Deleter) supportedDescriptors() map[schema.GroupVersionKind]resources.Descriptor {
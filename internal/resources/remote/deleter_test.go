@@ -6,9 +6,11 @@ import (
 	"testing"
 
 	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/journal"
 	"github.com/grafana/grafanactl/internal/resources/remote"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
@@ -30,6 +32,12 @@ func (m *mockDeleteClient) Delete(
 	return nil
 }
 
+func (m *mockDeleteClient) Get(
+	_ context.Context, _ resources.Descriptor, name string, _ metav1.GetOptions,
+) (*unstructured.Unstructured, error) {
+	return makeExistingDashboard(name, "1"), nil
+}
+
 func TestDeleter_Delete(t *testing.T) {
 	dashboardDescriptor := resources.Descriptor{
 		GroupVersion: schema.GroupVersion{Group: "dashboard.grafana.app", Version: "v1"},
@@ -214,3 +222,40 @@ func TestDeleter_Delete_FailureDetails(t *testing.T) {
 	require.Equal(t, "dashboard-bad", failures[0].Resource.Name())
 	require.Equal(t, deleteErr, failures[0].Error)
 }
+
+func TestDeleter_Delete_RecordsChanges(t *testing.T) {
+	req := require.New(t)
+
+	mockRegistry := &mockPushRegistry{
+		supportedResources: []resources.Descriptor{{
+			GroupVersion: schema.GroupVersion{Group: "dashboard.grafana.app", Version: "v1"},
+			Kind:         "Dashboard",
+			Singular:     "dashboard",
+			Plural:       "dashboards",
+		}},
+	}
+
+	deleter := remote.NewDeleterWithClient(&mockDeleteClient{}, mockRegistry)
+	operation := &journal.Operation{}
+
+	_, err := deleter.Delete(t.Context(), remote.DeleteRequest{
+		Resources: resources.NewResources(createDashboardResource("dashboard-1")),
+		Recorder:  operation,
+	})
+	req.NoError(err)
+
+	req.Len(operation.Changes, 1)
+	req.Equal(journal.ActionDeleted, operation.Changes[0].Action)
+	req.Equal("dashboard-1", operation.Changes[0].Name)
+	req.Equal("1", operation.Changes[0].Previous.GetResourceVersion())
+
+	// Nothing is recorded in dry-run mode.
+	dryRun := &journal.Operation{}
+	_, err = deleter.Delete(t.Context(), remote.DeleteRequest{
+		Resources: resources.NewResources(createDashboardResource("dashboard-1")),
+		DryRun:    true,
+		Recorder:  dryRun,
+	})
+	req.NoError(err)
+	req.True(dryRun.IsEmpty())
+}
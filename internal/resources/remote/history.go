@@ -0,0 +1,263 @@
+package remote
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafanactl/internal/config"
+	"github.com/grafana/grafanactl/internal/grafana"
+	"github.com/grafana/grafanactl/internal/logs"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/dynamic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ErrVersionNotFound is returned when a version of a resource does not exist.
+var ErrVersionNotFound = errors.New("version not found")
+
+// Version describes a version of a resource in Grafana.
+type Version struct {
+	Version   int64     `json:"version"`
+	Author    string    `json:"author,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Message   string    `json:"message,omitempty"`
+}
+
+// HistoryClient is a client that can list the versions of resources, and update them.
+type HistoryClient interface {
+	List(
+		ctx context.Context, desc resources.Descriptor, opts metav1.ListOptions,
+	) (*unstructured.UnstructuredList, error)
+
+	Get(
+		ctx context.Context, desc resources.Descriptor, name string, opts metav1.GetOptions,
+	) (*unstructured.Unstructured, error)
+
+	Update(
+		ctx context.Context, desc resources.Descriptor, obj *unstructured.Unstructured, opts metav1.UpdateOptions,
+	) (*unstructured.Unstructured, error)
+}
+
+// LegacyDashboardVersions gives access to the versions of dashboards with the legacy HTTP API.
+type LegacyDashboardVersions interface {
+	List(ctx context.Context, uid string) ([]grafana.DashboardVersion, error)
+	Get(ctx context.Context, uid string, version int64) (*grafana.DashboardVersion, error)
+	Restore(ctx context.Context, uid string, version int64) error
+}
+
+// History gives access to the previous versions of resources in Grafana.
+//
+// Versions are listed with the Kubernetes-style API, using the history label
+// selector. For dashboards, the legacy HTTP API is used when the history is
+// not available through the Kubernetes-style API.
+type History struct {
+	client HistoryClient
+	legacy LegacyDashboardVersions
+}
+
+// NewHistory creates a new History.
+// legacy can be nil, in which case the legacy HTTP API is never used.
+func NewHistory(cfg config.NamespacedRESTConfig, legacy LegacyDashboardVersions) (*History, error) {
+	cli, err := dynamic.NewDefaultNamespacedClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewHistoryWithClient(cli, legacy), nil
+}
+
+// NewHistoryWithClient creates a new History with the given clients.
+// This is primarily useful for testing.
+func NewHistoryWithClient(client HistoryClient, legacy LegacyDashboardVersions) *History {
+	return &History{
+		client: client,
+		legacy: legacy,
+	}
+}
+
+// Versions returns the versions of a resource, most recent first.
+func (h *History) Versions(ctx context.Context, desc resources.Descriptor, name string) ([]Version, error) {
+	items, err := h.list(ctx, desc, name)
+	if h.useLegacy(ctx, desc, items, err) {
+		legacyVersions, err := h.legacy.List(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		versions := make([]Version, 0, len(legacyVersions))
+		for _, version := range legacyVersions {
+			versions = append(versions, Version{
+				Version:   version.Version,
+				Author:    version.CreatedBy,
+				Timestamp: version.Created,
+				Message:   version.Message,
+			})
+		}
+
+		return versions, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]Version, 0, len(items))
+	for _, item := range items {
+		versions = append(versions, versionOf(item))
+	}
+
+	return versions, nil
+}
+
+// Get returns a resource as it was at the given version.
+func (h *History) Get(ctx context.Context, desc resources.Descriptor, name string, version int64) (*resources.Resource, error) {
+	items, err := h.list(ctx, desc, name)
+	if h.useLegacy(ctx, desc, items, err) {
+		legacyVersion, err := h.legacy.Get(ctx, name, version)
+		if err != nil {
+			return nil, err
+		}
+
+		return legacyDashboard(desc, name, legacyVersion)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if item.GetGeneration() == version {
+			return resources.FromUnstructured(&item)
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s/%s version %d", ErrVersionNotFound, desc.Kind, name, version)
+}
+
+// Rollback restores a resource to the given version.
+// The restored content is saved as a new version of the resource.
+func (h *History) Rollback(ctx context.Context, desc resources.Descriptor, name string, version int64) error {
+	items, err := h.list(ctx, desc, name)
+	if h.useLegacy(ctx, desc, items, err) {
+		return h.legacy.Restore(ctx, name, version)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	idx := slices.IndexFunc(items, func(item unstructured.Unstructured) bool {
+		return item.GetGeneration() == version
+	})
+	if idx == -1 {
+		return fmt.Errorf("%w: %s/%s version %d", ErrVersionNotFound, desc.Kind, name, version)
+	}
+
+	current, err := h.client.Get(ctx, desc, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	obj := items[idx].DeepCopy()
+	obj.SetResourceVersion(current.GetResourceVersion())
+	obj.SetManagedFields(nil)
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	annotations[utils.AnnoKeyMessage] = fmt.Sprintf("Restored from version %d", version)
+	obj.SetAnnotations(annotations)
+
+	_, err = h.client.Update(ctx, desc, obj, metav1.UpdateOptions{})
+
+	return err
+}
+
+// list returns the versions of a resource with the Kubernetes-style API, most recent first.
+func (h *History) list(ctx context.Context, desc resources.Descriptor, name string) ([]unstructured.Unstructured, error) {
+	list, err := h.client.List(ctx, desc, metav1.ListOptions{
+		LabelSelector: utils.LabelKeyGetHistory + "=true",
+		FieldSelector: "metadata.name=" + name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := slices.DeleteFunc(list.Items, func(item unstructured.Unstructured) bool {
+		return item.GetName() != name
+	})
+
+	slices.SortFunc(items, func(a, b unstructured.Unstructured) int {
+		return cmp.Compare(b.GetGeneration(), a.GetGeneration())
+	})
+
+	return items, nil
+}
+
+// useLegacy returns true if the legacy HTTP API must be used, because the
+// history of the resource is not available through the Kubernetes-style API.
+func (h *History) useLegacy(ctx context.Context, desc resources.Descriptor, items []unstructured.Unstructured, err error) bool {
+	if h.legacy == nil || desc.GroupVersion.Group != "dashboard.grafana.app" || desc.Kind != "Dashboard" {
+		return false
+	}
+
+	if err == nil && len(items) != 0 {
+		return false
+	}
+
+	if err != nil {
+		logging.FromContext(ctx).Debug("Falling back to the legacy versions API", logs.Err(err))
+	}
+
+	return true
+}
+
+func versionOf(obj unstructured.Unstructured) Version {
+	annotations := obj.GetAnnotations()
+
+	version := Version{
+		Version:   obj.GetGeneration(),
+		Author:    cmp.Or(annotations[utils.AnnoKeyUpdatedBy], annotations[utils.AnnoKeyCreatedBy]),
+		Timestamp: obj.GetCreationTimestamp().Time,
+		Message:   annotations[utils.AnnoKeyMessage],
+	}
+
+	if updated, err := time.Parse(time.RFC3339, annotations[utils.AnnoKeyUpdatedTimestamp]); err == nil {
+		version.Timestamp = updated
+	}
+
+	return version
+}
+
+// legacyDashboard converts a version of a dashboard from the legacy HTTP API to a resource.
+func legacyDashboard(desc resources.Descriptor, name string, version *grafana.DashboardVersion) (*resources.Resource, error) {
+	annotations := map[string]any{
+		utils.AnnoKeyUpdatedTimestamp: version.Created.UTC().Format(time.RFC3339),
+	}
+	if version.CreatedBy != "" {
+		annotations[utils.AnnoKeyUpdatedBy] = version.CreatedBy
+	}
+	if version.Message != "" {
+		annotations[utils.AnnoKeyMessage] = version.Message
+	}
+
+	return resources.FromUnstructured(&unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": desc.GroupVersion.String(),
+		"kind":       desc.Kind,
+		"metadata": map[string]any{
+			"name":        name,
+			"generation":  version.Version,
+			"annotations": annotations,
+		},
+		"spec": version.Data,
+	}})
+}
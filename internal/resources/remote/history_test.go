@@ -0,0 +1,220 @@
+package remote_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafanactl/internal/grafana"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/remote"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// mockHistoryClient implements remote.HistoryClient for testing.
+type mockHistoryClient struct {
+	versions    []unstructured.Unstructured
+	listErr     error
+	listOptions []metav1.ListOptions
+	updated     []*unstructured.Unstructured
+}
+
+func (m *mockHistoryClient) List(
+	_ context.Context, _ resources.Descriptor, opts metav1.ListOptions,
+) (*unstructured.UnstructuredList, error) {
+	m.listOptions = append(m.listOptions, opts)
+
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+
+	items := make([]unstructured.Unstructured, len(m.versions))
+	for i := range m.versions {
+		items[i] = *m.versions[i].DeepCopy()
+	}
+
+	return &unstructured.UnstructuredList{Items: items}, nil
+}
+
+func (m *mockHistoryClient) Get(
+	_ context.Context, _ resources.Descriptor, name string, _ metav1.GetOptions,
+) (*unstructured.Unstructured, error) {
+	return makeExistingDashboard(name, "99"), nil
+}
+
+func (m *mockHistoryClient) Update(
+	_ context.Context, _ resources.Descriptor, obj *unstructured.Unstructured, _ metav1.UpdateOptions,
+) (*unstructured.Unstructured, error) {
+	m.updated = append(m.updated, obj)
+
+	return obj, nil
+}
+
+// mockLegacyVersions implements remote.LegacyDashboardVersions for testing.
+type mockLegacyVersions struct {
+	versions []grafana.DashboardVersion
+	restored []int64
+}
+
+func (m *mockLegacyVersions) List(_ context.Context, _ string) ([]grafana.DashboardVersion, error) {
+	return m.versions, nil
+}
+
+func (m *mockLegacyVersions) Get(_ context.Context, uid string, version int64) (*grafana.DashboardVersion, error) {
+	for _, v := range m.versions {
+		if v.Version == version {
+			v.Data = map[string]any{"title": uid}
+			return &v, nil
+		}
+	}
+
+	return nil, errors.New("not found")
+}
+
+func (m *mockLegacyVersions) Restore(_ context.Context, _ string, version int64) error {
+	m.restored = append(m.restored, version)
+
+	return nil
+}
+
+func historyVersion(generation int64, title string, author string) unstructured.Unstructured {
+	obj := *makeExistingDashboard("foo", "1")
+	obj.SetGeneration(generation)
+	obj.SetAnnotations(map[string]string{
+		utils.AnnoKeyUpdatedBy:        author,
+		utils.AnnoKeyUpdatedTimestamp: fmt.Sprintf("2026-01-%02dT10:00:00Z", generation),
+		utils.AnnoKeyMessage:          "version " + title,
+	})
+	_ = unstructured.SetNestedField(obj.Object, title, "spec", "title")
+
+	return obj
+}
+
+func historyDashboardDescriptor() resources.Descriptor {
+	return resources.Descriptor{
+		GroupVersion: schema.GroupVersion{Group: "dashboard.grafana.app", Version: "v1"},
+		Kind:         "Dashboard",
+		Singular:     "dashboard",
+		Plural:       "dashboards",
+	}
+}
+
+func TestHistory_Versions(t *testing.T) {
+	req := require.New(t)
+
+	client := &mockHistoryClient{
+		versions: []unstructured.Unstructured{
+			historyVersion(1, "first", "user:alice"),
+			historyVersion(3, "third", "user:carol"),
+			historyVersion(2, "second", "user:bob"),
+		},
+	}
+
+	history := remote.NewHistoryWithClient(client, &mockLegacyVersions{})
+
+	versions, err := history.Versions(t.Context(), historyDashboardDescriptor(), "foo")
+	req.NoError(err)
+
+	req.Len(versions, 3)
+	req.Equal(int64(3), versions[0].Version)
+	req.Equal("user:carol", versions[0].Author)
+	req.Equal("version third", versions[0].Message)
+	req.Equal(time.Date(2026, 1, 3, 10, 0, 0, 0, time.UTC), versions[0].Timestamp.UTC())
+	req.Equal(int64(1), versions[2].Version)
+
+	req.Equal(utils.LabelKeyGetHistory+"=true", client.listOptions[0].LabelSelector)
+	req.Equal("metadata.name=foo", client.listOptions[0].FieldSelector)
+}
+
+func TestHistory_Get(t *testing.T) {
+	req := require.New(t)
+
+	client := &mockHistoryClient{
+		versions: []unstructured.Unstructured{
+			historyVersion(1, "first", "user:alice"),
+			historyVersion(2, "second", "user:bob"),
+		},
+	}
+
+	history := remote.NewHistoryWithClient(client, nil)
+
+	res, err := history.Get(t.Context(), historyDashboardDescriptor(), "foo", 1)
+	req.NoError(err)
+
+	spec, err := res.Spec()
+	req.NoError(err)
+	req.Equal("first", spec.(map[string]any)["title"])
+
+	_, err = history.Get(t.Context(), historyDashboardDescriptor(), "foo", 7)
+	req.ErrorIs(err, remote.ErrVersionNotFound)
+}
+
+func TestHistory_Rollback(t *testing.T) {
+	req := require.New(t)
+
+	client := &mockHistoryClient{
+		versions: []unstructured.Unstructured{
+			historyVersion(1, "first", "user:alice"),
+			historyVersion(2, "second", "user:bob"),
+		},
+	}
+
+	history := remote.NewHistoryWithClient(client, nil)
+
+	req.NoError(history.Rollback(t.Context(), historyDashboardDescriptor(), "foo", 1))
+
+	req.Len(client.updated, 1)
+	updated := client.updated[0]
+	req.Equal("99", updated.GetResourceVersion())
+	req.Equal("Restored from version 1", updated.GetAnnotations()[utils.AnnoKeyMessage])
+
+	title, _, _ := unstructured.NestedString(updated.Object, "spec", "title")
+	req.Equal("first", title)
+}
+
+func TestHistory_LegacyFallback(t *testing.T) {
+	req := require.New(t)
+
+	client := &mockHistoryClient{listErr: errors.New("history not supported")}
+	legacy := &mockLegacyVersions{
+		versions: []grafana.DashboardVersion{
+			{Version: 2, CreatedBy: "bob", Created: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)},
+			{Version: 1, CreatedBy: "alice", Created: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Message: "initial"},
+		},
+	}
+
+	history := remote.NewHistoryWithClient(client, legacy)
+	desc := historyDashboardDescriptor()
+
+	versions, err := history.Versions(t.Context(), desc, "foo")
+	req.NoError(err)
+	req.Equal([]remote.Version{
+		{Version: 2, Author: "bob", Timestamp: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{Version: 1, Author: "alice", Timestamp: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Message: "initial"},
+	}, versions)
+
+	res, err := history.Get(t.Context(), desc, "foo", 1)
+	req.NoError(err)
+	req.Equal("Dashboard", res.Kind())
+	req.Equal("foo", res.Name())
+
+	req.NoError(history.Rollback(t.Context(), desc, "foo", 1))
+	req.Equal([]int64{1}, legacy.restored)
+
+	// The legacy API only supports dashboards.
+	folders := resources.Descriptor{
+		GroupVersion: schema.GroupVersion{Group: "folder.grafana.app", Version: "v1"},
+		Kind:         "Folder",
+		Singular:     "folder",
+		Plural:       "folders",
+	}
+
+	_, err = history.Versions(t.Context(), folders, "foo")
+	req.ErrorContains(err, "history not supported")
+}
@@ -0,0 +1,199 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafanactl/internal/config"
+	"github.com/grafana/grafanactl/internal/logs"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/discovery"
+	"github.com/grafana/grafanactl/internal/resources/dynamic"
+	"github.com/grafana/grafanactl/internal/resources/legacy"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// PatchClient is a client that can patch resources in Grafana.
+type PatchClient interface {
+	Patch(
+		ctx context.Context, desc resources.Descriptor, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions,
+	) (*unstructured.Unstructured, error)
+}
+
+// Patcher takes care of patching resources in Grafana.
+type Patcher struct {
+	client   PatchClient
+	registry PushRegistry
+}
+
+// NewPatcher creates a new Patcher.
+func NewPatcher(ctx context.Context, cfg config.NamespacedRESTConfig) (*Patcher, error) {
+	cli, err := dynamic.NewDefaultNamespacedClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	registry, err := discovery.NewDefaultRegistry(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Patcher{
+		client:   cli,
+		registry: registry,
+	}, nil
+}
+
+// NewPatcherWithClient creates a new Patcher with the given client and registry.
+// This is primarily useful for testing.
+func NewPatcherWithClient(client PatchClient, registry PushRegistry) *Patcher {
+	return &Patcher{
+		client:   client,
+		registry: registry,
+	}
+}
+
+// PatchRequest is a request for patching resources in Grafana.
+type PatchRequest struct {
+	// A list of resources to patch, as they currently are in Grafana.
+	Resources *resources.Resources
+
+	// The type of the patch: JSON patch, merge patch or strategic merge patch.
+	Type types.PatchType
+
+	// The patch to apply to every resource, in JSON.
+	Patch []byte
+
+	// PatchFor computes the patch to apply to a given resource.
+	// If set, it is used instead of Patch.
+	PatchFor func(res *resources.Resource) ([]byte, error)
+
+	// The maximum number of concurrent patches.
+	MaxConcurrency int
+
+	// Whether the operation should stop upon encountering an error.
+	StopOnError bool
+
+	// If set to true, the patcher will simulate the patch operations.
+	DryRun bool
+
+	// Whether resources managed by other tools should be patched.
+	IncludeManaged bool
+
+	// OnPatch is called for every resource successfully patched,
+	// with the resource as returned by the server.
+	OnPatch func(res *resources.Resource)
+}
+
+// Patch applies the patch of the request to every resource.
+// Resources managed by other tools are skipped, unless request.IncludeManaged is set.
+func (patcher *Patcher) Patch(ctx context.Context, request PatchRequest) (*OperationSummary, error) {
+	summary := &OperationSummary{}
+	supported := patcher.supportedDescriptors()
+
+	if request.MaxConcurrency < 1 {
+		request.MaxConcurrency = 1
+	}
+
+	err := request.Resources.ForEachConcurrently(ctx, request.MaxConcurrency,
+		func(ctx context.Context, res *resources.Resource) error {
+			name := res.Name()
+			gvk := res.GroupVersionKind()
+
+			logger := logging.FromContext(ctx).With(
+				"gvk", gvk,
+				"name", name,
+			)
+
+			desc, ok := supported[gvk]
+			if !ok {
+				if request.StopOnError {
+					return fmt.Errorf("resource not supported by the API: %s/%s", gvk, name)
+				}
+
+				logger.Warn("Skipping resource not supported by the API")
+				return nil
+			}
+
+			if !res.IsManaged() && !request.IncludeManaged {
+				logger.Info(fmt.Sprintf("Skipping resource managed by %s", res.GetManagerKind()))
+				return nil
+			}
+
+			// Resources of the legacy HTTP API have no metadata that could be patched.
+			var err error
+			if desc.IsLegacy() {
+				err = fmt.Errorf("%s/%s: %w", gvk.Kind, name, legacy.ErrMetadataNotSupported)
+			}
+
+			var patched *resources.Resource
+			if err == nil {
+				patched, err = patcher.patchResource(ctx, desc, res, request)
+			}
+			if err != nil {
+				summary.RecordFailure(res, err)
+				if request.StopOnError {
+					return err
+				}
+
+				logger.Warn("Failed to patch resource", logs.Err(err))
+				return nil
+			}
+
+			logger.Info("Resource patched")
+			summary.RecordSuccess()
+
+			if request.OnPatch != nil {
+				request.OnPatch(patched)
+			}
+
+			return nil
+		},
+	)
+	if err != nil {
+		return summary, err
+	}
+
+	return summary, nil
+}
+
+func (patcher *Patcher) patchResource(
+	ctx context.Context, descriptor resources.Descriptor, res *resources.Resource, request PatchRequest,
+) (*resources.Resource, error) {
+	var dryRunOpts []string
+	if request.DryRun {
+		dryRunOpts = []string{"All"}
+	}
+
+	patch := request.Patch
+	if request.PatchFor != nil {
+		var err error
+		if patch, err = request.PatchFor(res); err != nil {
+			return nil, err
+		}
+	}
+
+	obj, err := patcher.client.Patch(ctx, descriptor, res.Name(), request.Type, patch, metav1.PatchOptions{
+		DryRun: dryRunOpts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resources.FromUnstructured(obj)
+}
+
+func (patcher *Patcher) supportedDescriptors() map[schema.GroupVersionKind]resources.Descriptor {
+	supported := patcher.registry.SupportedResources()
+
+	supportedDescriptors := make(map[schema.GroupVersionKind]resources.Descriptor)
+	for _, sup := range supported {
+		supportedDescriptors[sup.GroupVersionKind()] = sup
+	}
+
+	return supportedDescriptors
+}
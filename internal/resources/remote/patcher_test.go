@@ -0,0 +1,228 @@
+package remote_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/legacy"
+	"github.com/grafana/grafanactl/internal/resources/remote"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// mockPatchClient implements remote.PatchClient for testing.
+type mockPatchClient struct {
+	mu           sync.Mutex
+	shouldFail   map[string]bool
+	failureError error
+	patchedNames []string
+	patchTypes   []types.PatchType
+	dryRuns      [][]string
+}
+
+func (m *mockPatchClient) Patch(
+	_ context.Context, _ resources.Descriptor, name string, pt types.PatchType, _ []byte, opts metav1.PatchOptions,
+) (*unstructured.Unstructured, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shouldFail != nil && m.shouldFail[name] {
+		return nil, m.failureError
+	}
+
+	m.patchedNames = append(m.patchedNames, name)
+	m.patchTypes = append(m.patchTypes, pt)
+	m.dryRuns = append(m.dryRuns, opts.DryRun)
+
+	return makeExistingDashboard(name, "2"), nil
+}
+
+func createManagedDashboard(name string, kind utils.ManagerKind) *resources.Resource {
+	res := createDashboardResource(name)
+	res.Object.SetAnnotations(map[string]string{
+		utils.AnnoKeyManagerKind:     string(kind),
+		utils.AnnoKeyManagerIdentity: "someone",
+	})
+
+	return res
+}
+
+func TestPatcher_Patch(t *testing.T) {
+	dashboardDescriptor := resources.Descriptor{
+		GroupVersion: schema.GroupVersion{Group: "dashboard.grafana.app", Version: "v1"},
+		Kind:         "Dashboard",
+		Singular:     "dashboard",
+		Plural:       "dashboards",
+	}
+
+	tests := []struct {
+		name               string
+		resources          []*resources.Resource
+		supportedResources []resources.Descriptor
+		includeManaged     bool
+		clientShouldFail   map[string]bool
+		stopOnError        bool
+		wantSuccessCount   int
+		wantFailedCount    int
+		wantErr            bool
+		wantPatchedNames   []string
+	}{
+		{
+			name: "patch multiple dashboards successfully",
+			resources: []*resources.Resource{
+				createDashboardResource("dashboard-1"),
+				createDashboardResource("dashboard-2"),
+			},
+			supportedResources: []resources.Descriptor{dashboardDescriptor},
+			wantSuccessCount:   2,
+			wantPatchedNames:   []string{"dashboard-1", "dashboard-2"},
+		},
+		{
+			name: "skip resource with unsupported GVK",
+			resources: []*resources.Resource{
+				createDashboardResource("dashboard-1"),
+			},
+			supportedResources: []resources.Descriptor{},
+			wantPatchedNames:   []string{},
+		},
+		{
+			name: "skip resources managed by other tools",
+			resources: []*resources.Resource{
+				createManagedDashboard("dashboard-1", utils.ManagerKindTerraform),
+				createManagedDashboard("dashboard-2", resources.ResourceManagerKind),
+			},
+			supportedResources: []resources.Descriptor{dashboardDescriptor},
+			wantSuccessCount:   1,
+			wantPatchedNames:   []string{"dashboard-2"},
+		},
+		{
+			name: "include resources managed by other tools",
+			resources: []*resources.Resource{
+				createManagedDashboard("dashboard-1", utils.ManagerKindTerraform),
+			},
+			supportedResources: []resources.Descriptor{dashboardDescriptor},
+			includeManaged:     true,
+			wantSuccessCount:   1,
+			wantPatchedNames:   []string{"dashboard-1"},
+		},
+		{
+			name: "record failure when patch call fails",
+			resources: []*resources.Resource{
+				createDashboardResource("dashboard-1"),
+				createDashboardResource("dashboard-2"),
+			},
+			supportedResources: []resources.Descriptor{dashboardDescriptor},
+			clientShouldFail:   map[string]bool{"dashboard-1": true},
+			wantSuccessCount:   1,
+			wantFailedCount:    1,
+			wantPatchedNames:   []string{"dashboard-2"},
+		},
+		{
+			name: "reject resources of the legacy HTTP API",
+			resources: []*resources.Resource{
+				createDashboardResource("dashboard-1"),
+				resources.MustFromObject(map[string]any{
+					"apiVersion": "legacy.grafana.app/v0",
+					"kind":       "DataSource",
+					"metadata":   map[string]any{"name": "prom"},
+					"spec":       map[string]any{"type": "prometheus"},
+				}, resources.SourceInfo{}),
+			},
+			supportedResources: append(legacy.Descriptors(), dashboardDescriptor),
+			includeManaged:     true,
+			wantSuccessCount:   1,
+			wantFailedCount:    1,
+			wantPatchedNames:   []string{"dashboard-1"},
+		},
+		{
+			name: "stop on error when StopOnError is set",
+			resources: []*resources.Resource{
+				createDashboardResource("dashboard-1"),
+			},
+			supportedResources: []resources.Descriptor{dashboardDescriptor},
+			clientShouldFail:   map[string]bool{"dashboard-1": true},
+			stopOnError:        true,
+			wantErr:            true,
+			wantFailedCount:    1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := require.New(t)
+
+			mockClient := &mockPatchClient{
+				shouldFail:   tc.clientShouldFail,
+				failureError: errors.New("patch API error"),
+			}
+
+			mockRegistry := &mockPushRegistry{
+				supportedResources: tc.supportedResources,
+			}
+
+			patcher := remote.NewPatcherWithClient(mockClient, mockRegistry)
+
+			summary, err := patcher.Patch(t.Context(), remote.PatchRequest{
+				Resources:      resources.NewResources(tc.resources...),
+				Type:           types.MergePatchType,
+				Patch:          []byte(`{"spec":{"title":"patched"}}`),
+				MaxConcurrency: 1,
+				StopOnError:    tc.stopOnError,
+				IncludeManaged: tc.includeManaged,
+			})
+
+			if tc.wantErr {
+				req.Error(err)
+				req.NotNil(summary)
+				req.Equal(tc.wantFailedCount, summary.FailedCount())
+				return
+			}
+
+			req.NoError(err)
+			req.Equal(tc.wantSuccessCount, summary.SuccessCount())
+			req.Equal(tc.wantFailedCount, summary.FailedCount())
+			req.ElementsMatch(tc.wantPatchedNames, mockClient.patchedNames)
+		})
+	}
+}
+
+func TestPatcher_Patch_DryRun(t *testing.T) {
+	req := require.New(t)
+
+	mockClient := &mockPatchClient{}
+	mockRegistry := &mockPushRegistry{
+		supportedResources: []resources.Descriptor{{
+			GroupVersion: schema.GroupVersion{Group: "dashboard.grafana.app", Version: "v1"},
+			Kind:         "Dashboard",
+			Singular:     "dashboard",
+			Plural:       "dashboards",
+		}},
+	}
+
+	var patched []*resources.Resource
+
+	patcher := remote.NewPatcherWithClient(mockClient, mockRegistry)
+	summary, err := patcher.Patch(t.Context(), remote.PatchRequest{
+		Resources: resources.NewResources(createDashboardResource("dashboard-1")),
+		Type:      types.JSONPatchType,
+		Patch:     []byte(`[{"op":"replace","path":"/spec/title","value":"patched"}]`),
+		DryRun:    true,
+		OnPatch: func(res *resources.Resource) {
+			patched = append(patched, res)
+		},
+	})
+
+	req.NoError(err)
+	req.Equal(1, summary.SuccessCount())
+	req.Equal([]types.PatchType{types.JSONPatchType}, mockClient.patchTypes)
+	req.Equal([][]string{{"All"}}, mockClient.dryRuns)
+	req.Len(patched, 1)
+	req.Equal("2", patched[0].Object.GetResourceVersion())
+}
@@ -13,6 +13,7 @@ import (
 	"golang.org/x/sync/errgroup"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
 // PullClient is a client that can pull resources from Grafana.
@@ -28,6 +29,10 @@ type PullClient interface {
 	List(
 		ctx context.Context, desc resources.Descriptor, opts metav1.ListOptions,
 	) (*unstructured.UnstructuredList, error)
+
+	Watch(
+		ctx context.Context, desc resources.Descriptor, opts metav1.ListOptions,
+	) (watch.Interface, error)
 }
 
 // PullRegistry is a registry of resources that can be pulled from Grafana.
@@ -43,7 +48,12 @@ type Puller struct {
 
 // NewDefaultPuller creates a new Puller.
 func NewDefaultPuller(ctx context.Context, restConfig config.NamespacedRESTConfig) (*Puller, error) {
-	client, err := dynamic.NewDefaultVersionedClient(restConfig)
+	kubernetes, err := dynamic.NewDefaultVersionedClient(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := withLegacyClient(restConfig, kubernetes)
 	if err != nil {
 		return nil, err
 	}
@@ -85,21 +95,7 @@ type PullRequest struct {
 // Pull pulls resources from Grafana.
 func (p *Puller) Pull(ctx context.Context, req PullRequest) (*OperationSummary, error) {
 	summary := &OperationSummary{}
-	filters := req.Filters
-
-	// If no filters are provided, we need to pull all available resources.
-	if filters.IsEmpty() {
-		// When pulling all resources, we need to use preferred versions.
-		preferred := p.registry.PreferredResources()
-
-		filters = make(resources.Filters, 0, len(preferred))
-		for _, r := range preferred {
-			filters = append(filters, resources.Filter{
-				Type:       resources.FilterTypeAll,
-				Descriptor: r,
-			})
-		}
-	}
+	filters := p.resolveFilters(req.Filters)
 
 	logger := logging.FromContext(ctx)
 	logger.Debug("Pulling resources")
@@ -183,6 +179,32 @@ func (p *Puller) Pull(ctx context.Context, req PullRequest) (*OperationSummary,
 	return summary, nil
 }
 
+// resolveFilters returns the given filters,
+// or filters selecting all the available resources if none are given.
+// Resources served by the legacy HTTP API are only pulled when selected explicitly.
+func (p *Puller) resolveFilters(filters resources.Filters) resources.Filters {
+	if !filters.IsEmpty() {
+		return filters
+	}
+
+	// When pulling all resources, we need to use preferred versions.
+	preferred := p.registry.PreferredResources()
+
+	filters = make(resources.Filters, 0, len(preferred))
+	for _, r := range preferred {
+		if r.IsLegacy() {
+			continue
+		}
+
+		filters = append(filters, resources.Filter{
+			Type:       resources.FilterTypeAll,
+			Descriptor: r,
+		})
+	}
+
+	return filters
+}
+
 func (p *Puller) process(res *resources.Resource, processors []Processor) error {
 	for _, processor := range processors {
 		if err := processor.Process(res); err != nil {
@@ -11,6 +11,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
 // mockPullClient implements PullClient for testing.
@@ -19,6 +20,12 @@ type mockPullClient struct {
 	listResults map[string][]unstructured.Unstructured
 	// listErrors maps descriptor plural to the error returned by List.
 	listErrors map[string]error
+	// listVersion is the resource version of the lists returned by List.
+	listVersion string
+	// watchers are returned, in order, by Watch.
+	watchers []watch.Interface
+	// watchOptions records the options passed to Watch.
+	watchOptions []metav1.ListOptions
 }
 
 func (m *mockPullClient) Get(
@@ -43,7 +50,27 @@ func (m *mockPullClient) List(
 	}
 
 	items := m.listResults[desc.Plural]
-	return &unstructured.UnstructuredList{Items: items}, nil
+	list := &unstructured.UnstructuredList{Items: items}
+	list.SetResourceVersion(m.listVersion)
+
+	return list, nil
+}
+
+func (m *mockPullClient) Watch(
+	ctx context.Context, _ resources.Descriptor, opts metav1.ListOptions,
+) (watch.Interface, error) {
+	m.watchOptions = append(m.watchOptions, opts)
+
+	if len(m.watchers) == 0 {
+		// Block until the watch is stopped.
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	watcher := m.watchers[0]
+	m.watchers = m.watchers[1:]
+
+	return watcher, nil
 }
 
 // mockPullRegistry implements PullRegistry for testing.
@@ -190,3 +217,35 @@ func TestPuller_Pull(t *testing.T) {
 		})
 	}
 }
+
+func TestPuller_Pull_SkipsLegacyResources(t *testing.T) {
+	req := require.New(t)
+
+	legacyDesc := resources.Descriptor{
+		GroupVersion: schema.GroupVersion{Group: resources.LegacyGroup, Version: "v0"},
+		Kind:         "DataSource",
+		Singular:     "datasource",
+		Plural:       "datasources",
+	}
+
+	mockClient := &mockPullClient{
+		listResults: map[string][]unstructured.Unstructured{
+			"dashboards": {makeUnstructuredDashboard("dashboard-1")},
+		},
+		// Legacy resources are only pulled when selected explicitly.
+		listErrors: map[string]error{"datasources": errors.New("should not be listed")},
+	}
+
+	puller := remote.NewPuller(mockClient, &mockPullRegistry{
+		descriptors: resources.Descriptors{dashboardDescriptor(), legacyDesc},
+	})
+
+	dest := resources.NewResources()
+	summary, err := puller.Pull(t.Context(), remote.PullRequest{
+		Resources:   dest,
+		StopOnError: true,
+	})
+	req.NoError(err)
+	req.Equal(1, summary.SuccessCount())
+	req.Equal(1, dest.Len())
+}
@@ -0,0 +1,474 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafanactl/internal/logs"
+	"github.com/grafana/grafanactl/internal/resources"
+	"golang.org/x/sync/errgroup"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const (
+	minWatchRetryDelay = time.Second
+	maxWatchRetryDelay = 30 * time.Second
+)
+
+// ErrWatchNotSupported is returned when watching resources served by the legacy HTTP API.
+var ErrWatchNotSupported = errors.New("watching is not supported for resources served by the legacy HTTP API")
+
+// WatchEvent describes a change made to a resource.
+type WatchEvent struct {
+	// Type of the event: watch.Added, watch.Modified or watch.Deleted.
+	Type watch.EventType
+
+	// Resource is the state of the resource after the change.
+	// For deletions, it is the last known state of the resource.
+	Resource *resources.Resource
+}
+
+// WatchRequest is a request for watching resources in Grafana.
+type WatchRequest struct {
+	// Which resources to watch.
+	Filters resources.Filters
+
+	// Processors to apply to resources before they are reported.
+	Processors []Processor
+
+	// Whether to exclude resources managed by other tools.
+	ExcludeManaged bool
+
+	// Whether the operation should stop upon encountering an error.
+	// Connection errors are always retried.
+	StopOnError bool
+
+	// Whether to report every existing resource with an watch.Added event
+	// before reporting changes.
+	SendInitialEvents bool
+
+	// OnEvent is called for every event. It is never called concurrently.
+	// Returning an error stops the watch.
+	OnEvent func(event WatchEvent) error
+}
+
+// callbackError wraps errors returned by WatchRequest.OnEvent,
+// which always stop the watch.
+type callbackError struct {
+	err error
+}
+
+func (e callbackError) Error() string {
+	return e.err.Error()
+}
+
+// Watch watches resources in Grafana and reports changes as they happen,
+// until the context is cancelled.
+//
+// Resources are listed first, then watched from the resource version of the
+// list. When the connection is lost, the watch is resumed from the last
+// resource version observed, including the ones received through bookmarks.
+// If that version is too old, the resources are listed again and the
+// differences with the last known state are reported as events.
+//
+// Resources served by the legacy HTTP API can not be watched: they are
+// skipped when watching every resource, and rejected when selected explicitly.
+func (p *Puller) Watch(ctx context.Context, req WatchRequest) error {
+	filters, err := watchableFilters(ctx, req, p.resolveFilters(req.Filters))
+	if err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	emit := func(event WatchEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if err := req.OnEvent(event); err != nil {
+			return callbackError{err: err}
+		}
+
+		return nil
+	}
+
+	errg, ctx := errgroup.WithContext(ctx)
+
+	for _, filt := range filters {
+		errg.Go(func() error {
+			watcher := &filterWatcher{
+				client:  p.client,
+				filter:  filt,
+				request: req,
+				emit:    emit,
+				known:   make(map[string]knownResource),
+				logger:  logging.FromContext(ctx).With(slog.String("cmd", filt.String())),
+			}
+
+			err := watcher.run(ctx)
+			if err == nil || req.StopOnError || errors.As(err, &callbackError{}) {
+				return err
+			}
+
+			watcher.logger.Warn("Could not watch resources", logs.Err(err))
+			return nil
+		})
+	}
+
+	err = errg.Wait()
+
+	var cbErr callbackError
+	if errors.As(err, &cbErr) {
+		return cbErr.err
+	}
+
+	return err
+}
+
+// watchableFilters removes the filters selecting resources served by the legacy HTTP API.
+func watchableFilters(ctx context.Context, req WatchRequest, filters resources.Filters) (resources.Filters, error) {
+	logger := logging.FromContext(ctx)
+	watchable := make(resources.Filters, 0, len(filters))
+
+	var unsupported error
+	for _, filt := range filters {
+		if !filt.Descriptor.IsLegacy() {
+			watchable = append(watchable, filt)
+			continue
+		}
+
+		if req.Filters.IsEmpty() {
+			logger.Debug("Skipping resources served by the legacy HTTP API", slog.String("cmd", filt.String()))
+			continue
+		}
+
+		unsupported = fmt.Errorf("%s: %w", filt.Descriptor.Kind, ErrWatchNotSupported)
+		if req.StopOnError {
+			return nil, unsupported
+		}
+
+		logger.Warn("Could not watch resources", slog.String("cmd", filt.String()), logs.Err(unsupported))
+	}
+
+	if len(watchable) == 0 && unsupported != nil {
+		return nil, unsupported
+	}
+
+	return watchable, nil
+}
+
+type knownResource struct {
+	resourceVersion string
+	resource        *resources.Resource
+}
+
+// filterWatcher watches the resources selected by a single filter.
+type filterWatcher struct {
+	client  PullClient
+	filter  resources.Filter
+	request WatchRequest
+	emit    func(WatchEvent) error
+	logger  logging.Logger
+
+	// known resources, by name.
+	known map[string]knownResource
+	// resourceVersion to resume watching from. Empty if resources must be listed.
+	resourceVersion string
+	retryDelay      time.Duration
+}
+
+func (w *filterWatcher) run(ctx context.Context) error {
+	initial := true
+	w.retryDelay = minWatchRetryDelay
+
+	for {
+		if w.resourceVersion == "" {
+			if err := w.list(ctx, initial); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+
+				var cbErr callbackError
+				if initial || errors.As(err, &cbErr) {
+					return err
+				}
+
+				w.logger.Warn("Could not list resources, retrying", logs.Err(err))
+				if !w.wait(ctx) {
+					return nil
+				}
+
+				continue
+			}
+
+			initial = false
+		}
+
+		watcher, err := w.client.Watch(ctx, w.filter.Descriptor, metav1.ListOptions{
+			ResourceVersion:     w.resourceVersion,
+			AllowWatchBookmarks: true,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			if isExpired(err) {
+				w.logger.Debug("Resource version expired, listing resources again")
+				w.resourceVersion = ""
+				continue
+			}
+
+			w.logger.Warn("Could not watch resources, retrying", logs.Err(err))
+			if !w.wait(ctx) {
+				return nil
+			}
+
+			continue
+		}
+
+		err = w.consume(ctx, watcher)
+		watcher.Stop()
+
+		if err != nil {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// list lists the resources and reports the differences with the known ones.
+func (w *filterWatcher) list(ctx context.Context, initial bool) error {
+	list, err := w.client.List(ctx, w.filter.Descriptor, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]struct{}, len(list.Items))
+
+	for i := range list.Items {
+		obj := &list.Items[i]
+		if !w.matches(obj.GetName()) {
+			continue
+		}
+
+		seen[obj.GetName()] = struct{}{}
+
+		if known, ok := w.known[obj.GetName()]; ok && known.resourceVersion == obj.GetResourceVersion() {
+			continue
+		}
+
+		if err := w.update(obj, initial && !w.request.SendInitialEvents); err != nil {
+			return err
+		}
+	}
+
+	for name, known := range w.known {
+		if _, ok := seen[name]; ok {
+			continue
+		}
+
+		delete(w.known, name)
+
+		if err := w.emit(WatchEvent{Type: watch.Deleted, Resource: known.resource}); err != nil {
+			return err
+		}
+	}
+
+	w.resourceVersion = list.GetResourceVersion()
+
+	return nil
+}
+
+// consume reports the events received by the watcher until it is closed.
+func (w *filterWatcher) consume(ctx context.Context, watcher watch.Interface) error {
+	received := false
+
+	for {
+		var event watch.Event
+		var ok bool
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok = <-watcher.ResultChan():
+		}
+
+		// The server closed the connection: resume from the last resource version.
+		if !ok {
+			w.logger.Debug("Watch closed by the server, resuming", slog.String("resourceVersion", w.resourceVersion))
+
+			// Avoid reconnecting in a loop if the server keeps closing the connection.
+			if !received {
+				w.wait(ctx)
+			}
+
+			return nil
+		}
+
+		received = true
+
+		switch event.Type {
+		case watch.Error:
+			err := apierrors.FromObject(event.Object)
+			if isExpired(err) {
+				w.logger.Debug("Resource version expired, listing resources again")
+				w.resourceVersion = ""
+				return nil
+			}
+
+			w.logger.Warn("Watch error, resuming", logs.Err(err))
+			w.wait(ctx)
+
+			return nil
+		case watch.Bookmark:
+			if obj, err := meta.Accessor(event.Object); err == nil {
+				w.resourceVersion = obj.GetResourceVersion()
+			}
+			continue
+		case watch.Added, watch.Modified, watch.Deleted:
+		default:
+			continue
+		}
+
+		obj, ok := event.Object.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+
+		w.retryDelay = minWatchRetryDelay
+		w.resourceVersion = obj.GetResourceVersion()
+
+		if !w.matches(obj.GetName()) {
+			continue
+		}
+
+		if event.Type == watch.Deleted {
+			if err := w.remove(obj); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := w.update(obj, false); err != nil {
+			return err
+		}
+	}
+}
+
+// update records the new state of a resource, and reports it unless silent is set.
+func (w *filterWatcher) update(obj *unstructured.Unstructured, silent bool) error {
+	name := obj.GetName()
+	resourceVersion := obj.GetResourceVersion()
+
+	res, included, err := w.process(obj)
+	if err != nil {
+		if w.request.StopOnError {
+			return err
+		}
+
+		w.logger.Warn("Failed to process resource", logs.Err(err), slog.String("name", name))
+		return nil
+	}
+
+	known, exists := w.known[name]
+
+	// Resources not (or no longer) selected are reported as deleted.
+	if !included {
+		if !exists {
+			return nil
+		}
+
+		delete(w.known, name)
+
+		return w.emit(WatchEvent{Type: watch.Deleted, Resource: known.resource})
+	}
+
+	w.known[name] = knownResource{resourceVersion: resourceVersion, resource: res}
+
+	if silent {
+		return nil
+	}
+
+	eventType := watch.Added
+	if exists {
+		eventType = watch.Modified
+	}
+
+	return w.emit(WatchEvent{Type: eventType, Resource: res})
+}
+
+func (w *filterWatcher) remove(obj *unstructured.Unstructured) error {
+	known, exists := w.known[obj.GetName()]
+	if !exists {
+		return nil
+	}
+
+	delete(w.known, obj.GetName())
+
+	res, included, err := w.process(obj)
+	if err != nil || !included {
+		res = known.resource
+	}
+
+	return w.emit(WatchEvent{Type: watch.Deleted, Resource: res})
+}
+
+// process converts an object into a resource and applies the processors.
+// It returns false if the resource is excluded.
+func (w *filterWatcher) process(obj *unstructured.Unstructured) (*resources.Resource, bool, error) {
+	res, err := resources.FromUnstructured(obj.DeepCopy())
+	if err != nil {
+		return nil, false, err
+	}
+
+	// TODO: this should be replaced by a more generic mechanism,
+	// e.g. label & annotation filters.
+	if !res.IsManaged() && w.request.ExcludeManaged {
+		return nil, false, nil
+	}
+
+	for _, processor := range w.request.Processors {
+		if err := processor.Process(res); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return res, true, nil
+}
+
+func (w *filterWatcher) matches(name string) bool {
+	return w.filter.Type == resources.FilterTypeAll || slices.Contains(w.filter.ResourceUIDs, name)
+}
+
+// wait waits before retrying, with an exponential backoff.
+// It returns false if the context was cancelled in the meantime.
+func (w *filterWatcher) wait(ctx context.Context) bool {
+	timer := time.NewTimer(w.retryDelay)
+	defer timer.Stop()
+
+	w.retryDelay = min(w.retryDelay*2, maxWatchRetryDelay)
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func isExpired(err error) bool {
+	return apierrors.IsResourceExpired(err) || apierrors.IsGone(err)
+}
@@ -0,0 +1,219 @@
+package remote_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/remote"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+type watchEvent struct {
+	Type watch.EventType
+	Name string
+}
+
+func dashboardVersion(name string, resourceVersion string) *unstructured.Unstructured {
+	obj := makeUnstructuredDashboard(name)
+	obj.SetResourceVersion(resourceVersion)
+
+	return &obj
+}
+
+func watchAll(
+	t *testing.T, client *mockPullClient, req remote.WatchRequest, expected int, onEvent func(remote.WatchEvent),
+) ([]watchEvent, error) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	desc := dashboardDescriptor()
+	puller := remote.NewPuller(client, &mockPullRegistry{descriptors: resources.Descriptors{desc}})
+
+	var events []watchEvent
+	req.Filters = resources.Filters{{Type: resources.FilterTypeAll, Descriptor: desc}}
+	req.OnEvent = func(event remote.WatchEvent) error {
+		events = append(events, watchEvent{Type: event.Type, Name: event.Resource.Name()})
+
+		if onEvent != nil {
+			onEvent(event)
+		}
+
+		if len(events) == expected {
+			cancel()
+		}
+
+		return nil
+	}
+
+	err := puller.Watch(ctx, req)
+
+	return events, err
+}
+
+func TestPuller_Watch_resumesFromLastResourceVersion(t *testing.T) {
+	req := require.New(t)
+
+	first := watch.NewFakeWithChanSize(10, false)
+	first.Add(dashboardVersion("dashboard-2", "11"))
+	first.Modify(dashboardVersion("dashboard-1", "12"))
+	first.Action(watch.Bookmark, dashboardVersion("", "15"))
+	first.Stop()
+
+	second := watch.NewFakeWithChanSize(10, false)
+	second.Delete(dashboardVersion("dashboard-2", "16"))
+
+	client := &mockPullClient{
+		listResults: map[string][]unstructured.Unstructured{
+			"dashboards": {*dashboardVersion("dashboard-1", "1")},
+		},
+		listVersion: "10",
+		watchers:    []watch.Interface{first, second},
+	}
+
+	events, err := watchAll(t, client, remote.WatchRequest{SendInitialEvents: true}, 4, nil)
+	req.NoError(err)
+
+	req.Equal([]watchEvent{
+		{Type: watch.Added, Name: "dashboard-1"},
+		{Type: watch.Added, Name: "dashboard-2"},
+		{Type: watch.Modified, Name: "dashboard-1"},
+		{Type: watch.Deleted, Name: "dashboard-2"},
+	}, events)
+
+	req.Len(client.watchOptions, 2)
+	req.Equal("10", client.watchOptions[0].ResourceVersion)
+	req.True(client.watchOptions[0].AllowWatchBookmarks)
+	req.Equal("15", client.watchOptions[1].ResourceVersion)
+}
+
+func TestPuller_Watch_relistsExpiredResourceVersion(t *testing.T) {
+	req := require.New(t)
+
+	expired := watch.NewFakeWithChanSize(10, false)
+	expired.Error(&apierrors.NewResourceExpired("too old resource version").ErrStatus)
+
+	client := &mockPullClient{
+		listResults: map[string][]unstructured.Unstructured{
+			"dashboards": {*dashboardVersion("dashboard-1", "1"), *dashboardVersion("dashboard-2", "2")},
+		},
+		listVersion: "10",
+		watchers:    []watch.Interface{expired},
+	}
+
+	// The resources changed while the watch was interrupted.
+	// Events are only sent once the resources are listed again.
+	updateResources := func(remote.WatchEvent) {
+		client.listResults["dashboards"] = []unstructured.Unstructured{
+			*dashboardVersion("dashboard-1", "20"),
+			*dashboardVersion("dashboard-3", "21"),
+		}
+		client.listVersion = "22"
+	}
+
+	events, err := watchAll(t, client, remote.WatchRequest{SendInitialEvents: true}, 5, updateResources)
+	req.NoError(err)
+
+	req.Equal([]watchEvent{
+		{Type: watch.Added, Name: "dashboard-1"},
+		{Type: watch.Added, Name: "dashboard-2"},
+		{Type: watch.Modified, Name: "dashboard-1"},
+		{Type: watch.Added, Name: "dashboard-3"},
+		{Type: watch.Deleted, Name: "dashboard-2"},
+	}, events)
+
+	req.Len(client.watchOptions, 2)
+	req.Equal("10", client.watchOptions[0].ResourceVersion)
+	req.Equal("22", client.watchOptions[1].ResourceVersion)
+}
+
+func TestPuller_Watch_withoutInitialEvents(t *testing.T) {
+	req := require.New(t)
+
+	changes := watch.NewFakeWithChanSize(10, false)
+	changes.Modify(dashboardVersion("dashboard-1", "11"))
+
+	client := &mockPullClient{
+		listResults: map[string][]unstructured.Unstructured{
+			"dashboards": {*dashboardVersion("dashboard-1", "1")},
+		},
+		listVersion: "10",
+		watchers:    []watch.Interface{changes},
+	}
+
+	events, err := watchAll(t, client, remote.WatchRequest{}, 1, nil)
+	req.NoError(err)
+
+	req.Equal([]watchEvent{
+		{Type: watch.Modified, Name: "dashboard-1"},
+	}, events)
+}
+
+func TestPuller_Watch_callbackError(t *testing.T) {
+	req := require.New(t)
+
+	client := &mockPullClient{
+		listResults: map[string][]unstructured.Unstructured{
+			"dashboards": {*dashboardVersion("dashboard-1", "1")},
+		},
+	}
+
+	desc := dashboardDescriptor()
+	puller := remote.NewPuller(client, &mockPullRegistry{descriptors: resources.Descriptors{desc}})
+
+	err := puller.Watch(t.Context(), remote.WatchRequest{
+		SendInitialEvents: true,
+		OnEvent: func(remote.WatchEvent) error {
+			return errors.New("disk full")
+		},
+	})
+	req.EqualError(err, "disk full")
+}
+
+func TestPuller_Watch_listError(t *testing.T) {
+	req := require.New(t)
+
+	client := &mockPullClient{
+		listErrors: map[string]error{"dashboards": errors.New("connection refused")},
+	}
+
+	desc := dashboardDescriptor()
+	puller := remote.NewPuller(client, &mockPullRegistry{descriptors: resources.Descriptors{desc}})
+
+	err := puller.Watch(t.Context(), remote.WatchRequest{
+		StopOnError: true,
+		OnEvent: func(remote.WatchEvent) error {
+			return nil
+		},
+	})
+	req.EqualError(err, "connection refused")
+}
+
+func TestPuller_Watch_legacyResources(t *testing.T) {
+	req := require.New(t)
+
+	desc := resources.Descriptor{
+		GroupVersion: schema.GroupVersion{Group: resources.LegacyGroup, Version: "v0"},
+		Kind:         "DataSource",
+		Singular:     "datasource",
+		Plural:       "datasources",
+	}
+	puller := remote.NewPuller(&mockPullClient{}, &mockPullRegistry{descriptors: resources.Descriptors{desc}})
+
+	// Legacy resources are never retried: they can't be watched.
+	err := puller.Watch(t.Context(), remote.WatchRequest{
+		Filters: resources.Filters{{Type: resources.FilterTypeAll, Descriptor: desc}},
+		OnEvent: func(remote.WatchEvent) error {
+			return nil
+		},
+	})
+	req.ErrorIs(err, remote.ErrWatchNotSupported)
+	req.ErrorContains(err, "DataSource")
+}
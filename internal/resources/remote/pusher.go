@@ -2,7 +2,9 @@ package remote
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/grafana/grafana-app-sdk/logging"
 	"github.com/grafana/grafanactl/internal/config"
@@ -10,6 +12,7 @@ import (
 	"github.com/grafana/grafanactl/internal/resources"
 	"github.com/grafana/grafanactl/internal/resources/discovery"
 	"github.com/grafana/grafanactl/internal/resources/dynamic"
+	"github.com/grafana/grafanactl/internal/resources/journal"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -34,6 +37,8 @@ type PushClient interface {
 	Get(
 		ctx context.Context, desc resources.Descriptor, name string, opts metav1.GetOptions,
 	) (*unstructured.Unstructured, error)
+
+	Delete(ctx context.Context, desc resources.Descriptor, name string, opts metav1.DeleteOptions) error
 }
 
 // Pusher takes care of pushing resources to Grafana API.
@@ -45,7 +50,12 @@ type Pusher struct {
 // NewDefaultPusher creates a new Pusher.
 // It uses the default namespaced dynamic client to push resources to Grafana.
 func NewDefaultPusher(ctx context.Context, cfg config.NamespacedRESTConfig) (*Pusher, error) {
-	client, err := dynamic.NewDefaultNamespacedClient(cfg)
+	kubernetes, err := dynamic.NewDefaultNamespacedClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := withLegacyClient(cfg, kubernetes)
 	if err != nil {
 		return nil, err
 	}
@@ -91,13 +101,66 @@ type PushRequest struct {
 
 	// Whether to include resources managed by other tools.
 	IncludeManaged bool
+
+	// OnPush, if set, is called for every resource successfully pushed.
+	// It can be called concurrently.
+	OnPush func(res *resources.Resource, action PushAction)
+
+	// Recorder, if set, records the previous state of every resource pushed.
+	// Nothing is recorded in dry-run mode.
+	Recorder ChangeRecorder
+
+	// If set to true, the push stops on the first failure and the resources
+	// already pushed are rolled back: updated resources are restored to their
+	// previous state, and created resources are deleted.
+	// Resources are then pushed one at a time, whatever MaxConcurrency.
+	// The rollback is described by OperationSummary.Rollback().
+	Atomic bool
 }
 
+// PushAction describes how a resource was pushed.
+type PushAction string
+
+const (
+	PushActionCreated PushAction = "created"
+	PushActionUpdated PushAction = "updated"
+	// PushActionSkipped is used for resources whose management by grafanactl
+	// is suspended in Grafana: they are left untouched.
+	PushActionSkipped PushAction = "skipped"
+	// PushActionNotValidated is used in dry-run mode for resources served by the
+	// legacy HTTP API: it does not support dry-runs, so they are not validated by Grafana.
+	PushActionNotValidated PushAction = "not validated"
+)
+
 // Push pushes resources to Grafana.
 // It pushes folders first (respecting parent-child hierarchy), then other resources.
 // This ensures that parent folders are created before their children,
 // and all folders are created before other resources that depend on them.
 func (p *Pusher) Push(ctx context.Context, request PushRequest) (*OperationSummary, error) {
+	// Nothing is changed in dry-run mode: there is nothing to roll back.
+	if !request.Atomic || request.DryRun {
+		return p.push(ctx, request)
+	}
+
+	snapshot := &journal.Operation{}
+	request.StopOnError = true
+	// Resources are pushed one at a time: concurrent pushes cancelled by a failure
+	// could be applied without being recorded, and would not be rolled back.
+	request.MaxConcurrency = 1
+	request.Recorder = recorders{snapshot, request.Recorder}
+
+	summary, err := p.push(ctx, request)
+	if err == nil && summary.FailedCount() == 0 {
+		return summary, nil
+	}
+
+	// The rollback must complete, even if the push was cancelled.
+	summary.recordRollback(p.rollback(context.WithoutCancel(ctx), snapshot.Changes))
+
+	return summary, err
+}
+
+func (p *Pusher) push(ctx context.Context, request PushRequest) (*OperationSummary, error) {
 	summary := &OperationSummary{}
 	supported := p.supportedDescriptors()
 
@@ -224,7 +287,8 @@ func (p *Pusher) pushSingleResource(
 		return nil
 	}
 
-	if err := p.upsertResource(ctx, desc, name, res, request.DryRun, logger); err != nil {
+	action, previous, err := p.upsertResource(ctx, desc, name, res, request.DryRun, logger)
+	if err != nil {
 		summary.RecordFailure(res, err)
 
 		if request.StopOnError {
@@ -237,14 +301,43 @@ func (p *Pusher) pushSingleResource(
 		return nil
 	}
 
+	if action == PushActionSkipped {
+		logger.Info("Skipping resource: its management by grafanactl is suspended")
+		return nil
+	}
+
+	if request.DryRun && desc.IsLegacy() {
+		logger.Info("Resource not validated: the legacy HTTP API does not support dry-runs")
+		summary.RecordUnvalidated()
+
+		if request.OnPush != nil {
+			request.OnPush(res, PushActionNotValidated)
+		}
+
+		return nil
+	}
+
 	logger.Info("Resource pushed")
 	summary.RecordSuccess()
+
+	if !request.DryRun {
+		if action == PushActionCreated {
+			recordChange(request.Recorder, journal.ActionCreated, res, nil)
+		} else {
+			recordChange(request.Recorder, journal.ActionUpdated, res, previous)
+		}
+	}
+
+	if request.OnPush != nil {
+		request.OnPush(res, action)
+	}
+
 	return nil
 }
 
 func (p *Pusher) upsertResource(
 	ctx context.Context, desc resources.Descriptor, name string, src *resources.Resource, dryRun bool, log logging.Logger,
-) error {
+) (PushAction, *unstructured.Unstructured, error) {
 	var dryRunOpts []string
 	if dryRun {
 		dryRunOpts = []string{"All"}
@@ -253,6 +346,10 @@ func (p *Pusher) upsertResource(
 	// Check if the resource already exists.
 	existing, err := p.client.Get(ctx, desc, name, metav1.GetOptions{})
 	if err == nil {
+		if isSuspended(existing) {
+			return PushActionSkipped, nil, nil
+		}
+
 		obj := src.ToUnstructured()
 
 		// Copy the resourceVersion from the existing resource so the API accepts the update.
@@ -261,11 +358,11 @@ func (p *Pusher) upsertResource(
 		if _, err := p.client.Update(ctx, desc, &obj, metav1.UpdateOptions{
 			DryRun: dryRunOpts,
 		}); err != nil {
-			return err
+			return "", nil, err
 		}
 
 		log.Info("Resource updated")
-		return nil
+		return PushActionUpdated, existing, nil
 	}
 
 	// If the resource does not exist, create it.
@@ -274,15 +371,93 @@ func (p *Pusher) upsertResource(
 		if _, err := p.client.Create(ctx, desc, &obj, metav1.CreateOptions{
 			DryRun: dryRunOpts,
 		}); err != nil {
-			return err
+			return "", nil, err
 		}
 
 		log.Info("Resource created")
-		return nil
+		return PushActionCreated, nil, nil
 	}
 
 	// Some unknown error occurred, return it.
-	return err
+	return "", nil, err
+}
+
+// rollback reverts the given changes, in the order they were made.
+// Changes are recorded in dependency order: previous states are restored
+// parents first, and created resources are deleted children first.
+func (p *Pusher) rollback(ctx context.Context, changes []journal.Change) *RollbackReport {
+	logger := logging.FromContext(ctx)
+	report := &RollbackReport{}
+	supported := p.supportedDescriptors()
+
+	revert := func(change journal.Change) {
+		res, err := change.Reverted()
+		if err != nil {
+			report.Failures = append(report.Failures, OperationFailure{Error: err})
+			return
+		}
+
+		// Resources were pushed: their kind is supported.
+		desc := supported[res.GroupVersionKind()]
+
+		if change.Action == journal.ActionCreated {
+			err = p.client.Delete(ctx, desc, res.Name(), metav1.DeleteOptions{})
+			if err == nil || apierrors.IsNotFound(err) {
+				report.Deleted = append(report.Deleted, res)
+				return
+			}
+		} else {
+			var action PushAction
+			action, _, err = p.upsertResource(ctx, desc, res.Name(), res, false, logger)
+			if err == nil && action != PushActionSkipped {
+				report.Restored = append(report.Restored, res)
+				return
+			}
+
+			if err == nil {
+				err = errors.New("its management by grafanactl is suspended")
+			}
+		}
+
+		logger.Warn("Failed to roll back resource", "gvk", res.GroupVersionKind(), "name", res.Name(), logs.Err(err))
+		report.Failures = append(report.Failures, OperationFailure{Resource: res, Error: err})
+	}
+
+	for _, change := range changes {
+		if change.Action != journal.ActionCreated {
+			revert(change)
+		}
+	}
+
+	for _, change := range slices.Backward(changes) {
+		if change.Action == journal.ActionCreated {
+			revert(change)
+		}
+	}
+
+	return report
+}
+
+// recorders records changes with several recorders.
+type recorders []ChangeRecorder
+
+func (r recorders) Record(change journal.Change) {
+	for _, recorder := range r {
+		if recorder != nil {
+			recorder.Record(change)
+		}
+	}
+}
+
+// isSuspended returns true if the management by grafanactl of a resource
+// existing in Grafana is suspended.
+func isSuspended(obj *unstructured.Unstructured) bool {
+	existing, err := resources.FromUnstructured(obj)
+	if err != nil {
+		return false
+	}
+
+	return existing.IsManaged() && existing.IsSuspended()
 }
 
 func (p *Pusher) supportedDescriptors() map[schema.GroupVersionKind]resources.Descriptor {
@@ -7,7 +7,10 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
 	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/journal"
+	"github.com/grafana/grafanactl/internal/resources/legacy"
 	"github.com/grafana/grafanactl/internal/resources/remote"
 	"github.com/stretchr/testify/require"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -498,6 +501,7 @@ func TestPusher_Push_UpdateExistingResource(t *testing.T) {
 		existingResources    map[string]*unstructured.Unstructured
 		wantOperations       []string
 		wantResourceVersions map[string]string
+		wantActions          map[string]remote.PushAction
 		wantPushedCount      int
 		wantFailedCount      int
 	}{
@@ -511,6 +515,7 @@ func TestPusher_Push_UpdateExistingResource(t *testing.T) {
 			},
 			wantOperations:       []string{"update-dashboard-1"},
 			wantResourceVersions: map[string]string{"dashboard-1": "42"},
+			wantActions:          map[string]remote.PushAction{"dashboard-1": remote.PushActionUpdated},
 			wantPushedCount:      1,
 			wantFailedCount:      0,
 		},
@@ -525,8 +530,12 @@ func TestPusher_Push_UpdateExistingResource(t *testing.T) {
 			},
 			wantOperations:       []string{"create-dashboard-new", "update-dashboard-existing"},
 			wantResourceVersions: map[string]string{"dashboard-existing": "99"},
-			wantPushedCount:      2,
-			wantFailedCount:      0,
+			wantActions: map[string]remote.PushAction{
+				"dashboard-new":      remote.PushActionCreated,
+				"dashboard-existing": remote.PushActionUpdated,
+			},
+			wantPushedCount: 2,
+			wantFailedCount: 0,
 		},
 		{
 			name: "update folder with resourceVersion",
@@ -538,6 +547,7 @@ func TestPusher_Push_UpdateExistingResource(t *testing.T) {
 			},
 			wantOperations:       []string{"update-folder-1"},
 			wantResourceVersions: map[string]string{"folder-1": "7"},
+			wantActions:          map[string]remote.PushAction{"folder-1": remote.PushActionUpdated},
 			wantPushedCount:      1,
 			wantFailedCount:      0,
 		},
@@ -573,16 +583,21 @@ func TestPusher_Push_UpdateExistingResource(t *testing.T) {
 			pusher := remote.NewPusher(mockClient, mockRegistry)
 			testResources := resources.NewResources(tc.localResources...)
 
+			actions := make(map[string]remote.PushAction)
 			summary, err := pusher.Push(t.Context(), remote.PushRequest{
 				Resources:      testResources,
 				MaxConcurrency: 1,
 				IncludeManaged: true,
+				OnPush: func(res *resources.Resource, action remote.PushAction) {
+					actions[res.Name()] = action
+				},
 			})
 
 			req.NoError(err)
 			req.Equal(tc.wantPushedCount, summary.SuccessCount())
 			req.Equal(tc.wantFailedCount, summary.FailedCount())
 			req.ElementsMatch(tc.wantOperations, mockClient.operations)
+			req.Equal(tc.wantActions, actions)
 
 			for name, expectedRV := range tc.wantResourceVersions {
 				updated, ok := mockClient.updatedObjects[name]
@@ -636,6 +651,266 @@ func TestPusher_Push_UpdateFailure(t *testing.T) {
 	req.Equal("dashboard-1", summary.Failures()[0].Resource.Name())
 }
 
+func TestPusher_Push_SuspendedResource(t *testing.T) {
+	req := require.New(t)
+
+	suspended := makeExistingDashboard("dashboard-1", "42")
+	suspended.SetAnnotations(map[string]string{
+		utils.AnnoKeyManagerKind:      string(resources.ResourceManagerKind),
+		utils.AnnoKeyManagerIdentity:  "grafanactl",
+		utils.AnnoKeyManagerSuspended: "true",
+	})
+
+	mockClient := &mockPushClient{
+		operations: []string{},
+		existingResources: map[string]*unstructured.Unstructured{
+			"dashboard-1": suspended,
+			"dashboard-2": makeExistingDashboard("dashboard-2", "12"),
+		},
+	}
+
+	mockRegistry := &mockPushRegistry{
+		supportedResources: []resources.Descriptor{
+			{
+				GroupVersion: schema.GroupVersion{Group: "dashboard.grafana.app", Version: "v1"},
+				Kind:         "Dashboard",
+				Singular:     "dashboard",
+				Plural:       "dashboards",
+			},
+		},
+	}
+
+	pusher := remote.NewPusher(mockClient, mockRegistry)
+
+	summary, err := pusher.Push(t.Context(), remote.PushRequest{
+		Resources: resources.NewResources(
+			createDashboardResource("dashboard-1"),
+			createDashboardResource("dashboard-2"),
+		),
+		MaxConcurrency: 1,
+	})
+
+	req.NoError(err)
+	req.Equal(1, summary.SuccessCount())
+	req.Equal(0, summary.FailedCount())
+	req.Equal([]string{"update-dashboard-2"}, mockClient.operations)
+}
+
+func TestPusher_Push_DryRunLegacy(t *testing.T) {
+	req := require.New(t)
+
+	mockClient := &mockPushClient{}
+	mockRegistry := &mockPushRegistry{
+		supportedResources: append(legacy.Descriptors(), resources.Descriptor{
+			GroupVersion: schema.GroupVersion{Group: "dashboard.grafana.app", Version: "v1"},
+			Kind:         "Dashboard",
+			Singular:     "dashboard",
+			Plural:       "dashboards",
+		}),
+	}
+
+	pusher := remote.NewPusher(mockClient, mockRegistry)
+
+	var actions []remote.PushAction
+	summary, err := pusher.Push(t.Context(), remote.PushRequest{
+		Resources: resources.NewResources(
+			createDashboardResource("dashboard-1"),
+			resources.MustFromObject(map[string]any{
+				"apiVersion": "legacy.grafana.app/v0",
+				"kind":       "DataSource",
+				"metadata":   map[string]any{"name": "prom"},
+				"spec":       map[string]any{"type": "prometheus"},
+			}, resources.SourceInfo{}),
+		),
+		MaxConcurrency: 1,
+		DryRun:         true,
+		IncludeManaged: true,
+		OnPush: func(_ *resources.Resource, action remote.PushAction) {
+			actions = append(actions, action)
+		},
+	})
+	req.NoError(err)
+
+	// The legacy HTTP API does not support dry-runs: the data source is not counted as pushed.
+	req.Equal(1, summary.SuccessCount())
+	req.Equal(1, summary.UnvalidatedCount())
+	req.ElementsMatch([]remote.PushAction{remote.PushActionCreated, remote.PushActionNotValidated}, actions)
+}
+
+func TestPusher_Push_RecordsChanges(t *testing.T) {
+	req := require.New(t)
+
+	mockClient := &mockPushClient{
+		existingResources: map[string]*unstructured.Unstructured{
+			"dashboard-existing": makeExistingDashboard("dashboard-existing", "12"),
+		},
+	}
+	mockRegistry := &mockPushRegistry{
+		supportedResources: []resources.Descriptor{{
+			GroupVersion: schema.GroupVersion{Group: "dashboard.grafana.app", Version: "v1"},
+			Kind:         "Dashboard",
+			Singular:     "dashboard",
+			Plural:       "dashboards",
+		}},
+	}
+
+	pusher := remote.NewPusher(mockClient, mockRegistry)
+	operation := &journal.Operation{}
+
+	_, err := pusher.Push(t.Context(), remote.PushRequest{
+		Resources: resources.NewResources(
+			createDashboardResource("dashboard-new"),
+			createDashboardResource("dashboard-existing"),
+		),
+		MaxConcurrency: 1,
+		Recorder:       operation,
+	})
+	req.NoError(err)
+
+	changes := make(map[string]journal.Change)
+	for _, change := range operation.Changes {
+		changes[change.Name] = change
+	}
+
+	req.Len(changes, 2)
+	req.Equal(journal.ActionCreated, changes["dashboard-new"].Action)
+	req.Nil(changes["dashboard-new"].Previous)
+	req.Equal(journal.ActionUpdated, changes["dashboard-existing"].Action)
+	req.Equal("12", changes["dashboard-existing"].Previous.GetResourceVersion())
+}
+
+func TestPusher_Push_Atomic(t *testing.T) {
+	req := require.New(t)
+
+	mockClient := &mockPushClient{
+		shouldFail:   map[string]bool{"dashboard-fail": true},
+		failureError: errors.New("invalid dashboard"),
+		existingResources: map[string]*unstructured.Unstructured{
+			"dashboard-existing": makeExistingDashboard("dashboard-existing", "5"),
+		},
+	}
+	mockRegistry := &mockPushRegistry{
+		supportedResources: []resources.Descriptor{
+			{
+				GroupVersion: schema.GroupVersion{Group: "folder.grafana.app", Version: "v1"},
+				Kind:         "Folder",
+				Singular:     "folder",
+				Plural:       "folders",
+			},
+			{
+				GroupVersion: schema.GroupVersion{Group: "dashboard.grafana.app", Version: "v1"},
+				Kind:         "Dashboard",
+				Singular:     "dashboard",
+				Plural:       "dashboards",
+			},
+		},
+	}
+
+	pusher := remote.NewPusher(mockClient, mockRegistry)
+
+	summary, err := pusher.Push(t.Context(), remote.PushRequest{
+		Resources: resources.NewResources(
+			createFolderResource("parent", "v1"),
+			createFolderWithParent("child", "parent"),
+			createDashboardResource("dashboard-existing"),
+			createDashboardResource("dashboard-fail"),
+		),
+		MaxConcurrency: 1,
+		Atomic:         true,
+	})
+	req.Error(err)
+	req.Equal(1, summary.FailedCount())
+
+	rollback := summary.Rollback()
+	req.NotNil(rollback)
+	req.True(rollback.Complete())
+	req.Equal(summary.SuccessCount(), len(rollback.Restored)+len(rollback.Deleted))
+
+	deleted := make([]string, 0, len(rollback.Deleted))
+	for _, res := range rollback.Deleted {
+		deleted = append(deleted, res.Name())
+	}
+
+	// Created folders are deleted children first.
+	req.Equal([]string{"child", "parent"}, deleted)
+	req.Equal([]string{"create-parent", "create-child"}, mockClient.operations[:2])
+	req.Equal([]string{"delete-child", "delete-parent"}, mockClient.operations[len(mockClient.operations)-2:])
+}
+
+func TestPusher_Push_Atomic_SuspendedRollback(t *testing.T) {
+	req := require.New(t)
+
+	mockClient := &mockPushClient{
+		shouldFail:   map[string]bool{"dashboard-fail": true},
+		failureError: errors.New("invalid dashboard"),
+		existingResources: map[string]*unstructured.Unstructured{
+			"dashboard-existing": makeExistingDashboard("dashboard-existing", "5"),
+		},
+		getUpdated: true,
+	}
+	mockRegistry := &mockPushRegistry{
+		supportedResources: []resources.Descriptor{{
+			GroupVersion: schema.GroupVersion{Group: "dashboard.grafana.app", Version: "v1"},
+			Kind:         "Dashboard",
+			Singular:     "dashboard",
+			Plural:       "dashboards",
+		}},
+	}
+
+	// The push suspends the management of the resource: restoring it is skipped.
+	obj := makeExistingDashboard("dashboard-existing", "")
+	obj.SetAnnotations(map[string]string{
+		utils.AnnoKeyManagerKind:      string(resources.ResourceManagerKind),
+		utils.AnnoKeyManagerIdentity:  "grafanactl",
+		utils.AnnoKeyManagerSuspended: "true",
+	})
+	suspended, err := resources.FromUnstructured(obj)
+	req.NoError(err)
+
+	pusher := remote.NewPusher(mockClient, mockRegistry)
+
+	summary, err := pusher.Push(t.Context(), remote.PushRequest{
+		Resources:      resources.NewResources(suspended, createDashboardResource("dashboard-fail")),
+		MaxConcurrency: 10,
+		IncludeManaged: true,
+		Atomic:         true,
+	})
+	req.Error(err)
+
+	rollback := summary.Rollback()
+	req.NotNil(rollback)
+	req.False(rollback.Complete())
+	req.Empty(rollback.Restored)
+	req.Len(rollback.Failures, 1)
+	req.Equal("dashboard-existing", rollback.Failures[0].Resource.Name())
+	req.ErrorContains(rollback.Failures[0].Error, "suspended")
+}
+
+func TestPusher_Push_Atomic_NoFailure(t *testing.T) {
+	req := require.New(t)
+
+	mockClient := &mockPushClient{}
+	mockRegistry := &mockPushRegistry{
+		supportedResources: []resources.Descriptor{{
+			GroupVersion: schema.GroupVersion{Group: "dashboard.grafana.app", Version: "v1"},
+			Kind:         "Dashboard",
+			Singular:     "dashboard",
+			Plural:       "dashboards",
+		}},
+	}
+
+	pusher := remote.NewPusher(mockClient, mockRegistry)
+
+	summary, err := pusher.Push(t.Context(), remote.PushRequest{
+		Resources: resources.NewResources(createDashboardResource("dashboard-1")),
+		Atomic:    true,
+	})
+	req.NoError(err)
+	req.Equal(1, summary.SuccessCount())
+	req.Nil(summary.Rollback())
+	req.Equal([]string{"create-dashboard-1"}, mockClient.operations)
+}
+
 func makeExistingDashboard(name, resourceVersion string) *unstructured.Unstructured {
 	return &unstructured.Unstructured{
 		Object: map[string]any{
@@ -679,6 +954,8 @@ type mockPushClient struct {
 	failureError      error
 	existingResources map[string]*unstructured.Unstructured
 	updatedObjects    map[string]*unstructured.Unstructured
+	// getUpdated makes Get return the last object sent with Update, if any.
+	getUpdated bool
 }
 
 func (m *mockPushClient) Create(
@@ -721,6 +998,15 @@ func (m *mockPushClient) Update(
 func (m *mockPushClient) Get(
 	_ context.Context, desc resources.Descriptor, name string, _ metav1.GetOptions,
 ) (*unstructured.Unstructured, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.getUpdated {
+		if updated, ok := m.updatedObjects[name]; ok {
+			return updated, nil
+		}
+	}
+
 	if m.existingResources != nil {
 		if existing, ok := m.existingResources[name]; ok {
 			return existing, nil
@@ -731,6 +1017,17 @@ func (m *mockPushClient) Get(
 	return nil, apierrors.NewNotFound(desc.GroupVersionResource().GroupResource(), name)
 }
 
+func (m *mockPushClient) Delete(
+	_ context.Context, _ resources.Descriptor, name string, _ metav1.DeleteOptions,
+) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.operations = append(m.operations, "delete-"+name)
+
+	return nil
+}
+
 type mockPushRegistry struct {
 	supportedResources []resources.Descriptor
 }
@@ -1,6 +1,12 @@
 package remote
 
-import "github.com/grafana/grafanactl/internal/resources"
+import (
+	"github.com/grafana/grafanactl/internal/config"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/journal"
+	"github.com/grafana/grafanactl/internal/resources/legacy"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
 
 // Processor can be used to modify a resource in-place,
 // before it is written or after it is read from local sources.
@@ -10,3 +16,38 @@ import "github.com/grafana/grafanactl/internal/resources"
 type Processor interface {
 	Process(res *resources.Resource) error
 }
+
+// ChangeRecorder records the changes made to resources in Grafana,
+// along with their previous state, so that they can be reverted.
+//
+// Record can be called concurrently.
+type ChangeRecorder interface {
+	Record(change journal.Change)
+}
+
+// withLegacyClient wraps a client of the Kubernetes-style API, so that
+// the resources served by the legacy HTTP API are handled by the legacy client.
+func withLegacyClient(cfg config.NamespacedRESTConfig, kubernetes legacy.ResourceClient) (*legacy.Router, error) {
+	legacyClient, err := legacy.NewDefaultClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return legacy.NewRouter(kubernetes, legacyClient), nil
+}
+
+func recordChange(
+	recorder ChangeRecorder, action journal.Action, res *resources.Resource, previous *unstructured.Unstructured,
+) {
+	if recorder == nil {
+		return
+	}
+
+	recorder.Record(journal.Change{
+		Action:     action,
+		APIVersion: res.APIVersion(),
+		Kind:       res.Kind(),
+		Name:       res.Name(),
+		Previous:   previous,
+	})
+}
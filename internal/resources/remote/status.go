@@ -0,0 +1,246 @@
+package remote
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafanactl/internal/config"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/discovery"
+	"github.com/grafana/grafanactl/internal/resources/dynamic"
+	"github.com/grafana/grafanactl/internal/resources/process"
+	"github.com/grafana/grafanactl/internal/secrets"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// SyncState describes how a local resource compares to the same resource in Grafana.
+type SyncState string
+
+const (
+	// SyncStateInSync is used when both resources have the same content.
+	SyncStateInSync SyncState = "in sync"
+	// SyncStateModifiedLocally is used when the local resource changed since it was last pushed.
+	SyncStateModifiedLocally SyncState = "modified locally"
+	// SyncStateModifiedRemotely is used when the resource changed in Grafana since it was last pushed.
+	SyncStateModifiedRemotely SyncState = "modified remotely"
+	// SyncStateConflict is used when both resources changed since the last push,
+	// or when they differ and the resource was never pushed by grafanactl.
+	SyncStateConflict SyncState = "conflict"
+	// SyncStateDiffers is used when resources of the legacy HTTP API differ: they hold
+	// no source checksum, so the side that changed since the last push is unknown.
+	SyncStateDiffers SyncState = "differs"
+	// SyncStateEncrypted is used for local resources holding values left encrypted, for
+	// lack of age identities: they can't be compared to the decrypted values pushed to Grafana.
+	SyncStateEncrypted SyncState = "encrypted"
+	// SyncStateMissingRemotely is used for local resources that don't exist in Grafana.
+	SyncStateMissingRemotely SyncState = "missing remotely"
+	// SyncStateOnlyRemote is used for resources of Grafana that don't exist locally.
+	SyncStateOnlyRemote SyncState = "only remote"
+)
+
+// StatusClient is a client that can list resources from Grafana.
+type StatusClient interface {
+	List(
+		ctx context.Context, desc resources.Descriptor, opts metav1.ListOptions,
+	) (*unstructured.UnstructuredList, error)
+}
+
+// StatusChecker compares local resources to the resources in Grafana.
+type StatusChecker struct {
+	client   StatusClient
+	registry PushRegistry
+}
+
+// NewStatusChecker creates a new StatusChecker.
+func NewStatusChecker(ctx context.Context, cfg config.NamespacedRESTConfig) (*StatusChecker, error) {
+	kubernetes, err := dynamic.NewDefaultNamespacedClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	cli, err := withLegacyClient(cfg, kubernetes)
+	if err != nil {
+		return nil, err
+	}
+
+	registry, err := discovery.NewDefaultRegistry(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StatusChecker{
+		client:   cli,
+		registry: registry,
+	}, nil
+}
+
+// NewStatusCheckerWithClient creates a new StatusChecker with the given client and registry.
+// This is primarily useful for testing.
+func NewStatusCheckerWithClient(client StatusClient, registry PushRegistry) *StatusChecker {
+	return &StatusChecker{
+		client:   client,
+		registry: registry,
+	}
+}
+
+// StatusRequest is a request for comparing local resources to the resources in Grafana.
+type StatusRequest struct {
+	// Local resources, as they would be pushed.
+	Resources *resources.Resources
+
+	// Filters designating the resources to compare.
+	// Resources of Grafana are compared if they match the filters, or if they
+	// are of the same kind as a local resource when there are no filters.
+	Filters resources.Filters
+}
+
+// ResourceStatus is the sync state of a resource.
+type ResourceStatus struct {
+	// Local is the local resource, if any.
+	Local *resources.Resource
+	// Remote is the resource in Grafana, if any.
+	Remote *resources.Resource
+	// State of the resource.
+	State SyncState
+}
+
+// Resource returns the local resource if it exists, or the one from Grafana.
+func (s ResourceStatus) Resource() *resources.Resource {
+	if s.Local != nil {
+		return s.Local
+	}
+
+	return s.Remote
+}
+
+// Status computes the sync state of every local resource, and of the
+// resources of Grafana that don't exist locally.
+// Results are sorted by kind and name.
+func (c *StatusChecker) Status(ctx context.Context, request StatusRequest) ([]ResourceStatus, error) {
+	supported := make(map[schema.GroupVersionKind]resources.Descriptor)
+	for _, desc := range c.registry.SupportedResources() {
+		supported[desc.GroupVersionKind()] = desc
+	}
+
+	local := make(map[schema.GroupVersionKind]map[string]*resources.Resource)
+	for _, res := range request.Resources.AsList() {
+		gvk := res.GroupVersionKind()
+		if local[gvk] == nil {
+			local[gvk] = make(map[string]*resources.Resource)
+		}
+
+		local[gvk][res.Name()] = res
+	}
+
+	kinds := make(map[schema.GroupVersionKind]struct{}, len(local))
+	for gvk := range local {
+		kinds[gvk] = struct{}{}
+	}
+
+	for _, filter := range request.Filters {
+		kinds[filter.Descriptor.GroupVersionKind()] = struct{}{}
+	}
+
+	var statuses []ResourceStatus
+
+	for gvk := range kinds {
+		desc, ok := supported[gvk]
+		if !ok {
+			return nil, fmt.Errorf("resource not supported by the API: %s", gvk)
+		}
+
+		list, err := c.client.List(ctx, desc, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", desc.Plural, err)
+		}
+
+		seen := make(map[string]struct{}, len(list.Items))
+
+		for i := range list.Items {
+			remote, err := resources.FromUnstructured(&list.Items[i])
+			if err != nil {
+				return nil, err
+			}
+
+			localRes, ok := local[gvk][remote.Name()]
+			if !ok {
+				if !request.Filters.IsEmpty() && !request.Filters.Matches(*remote) {
+					continue
+				}
+
+				statuses = append(statuses, ResourceStatus{Remote: remote, State: SyncStateOnlyRemote})
+				continue
+			}
+
+			seen[remote.Name()] = struct{}{}
+
+			state, err := CompareResources(localRes, remote)
+			if err != nil {
+				return nil, err
+			}
+
+			statuses = append(statuses, ResourceStatus{Local: localRes, Remote: remote, State: state})
+		}
+
+		for name, res := range local[gvk] {
+			if _, ok := seen[name]; !ok {
+				statuses = append(statuses, ResourceStatus{Local: res, State: SyncStateMissingRemotely})
+			}
+		}
+	}
+
+	slices.SortFunc(statuses, func(a, b ResourceStatus) int {
+		return cmp.Or(
+			cmp.Compare(a.Resource().Kind(), b.Resource().Kind()),
+			cmp.Compare(a.Resource().Name(), b.Resource().Name()),
+		)
+	})
+
+	return statuses, nil
+}
+
+// CompareResources computes the sync state of a local resource existing in Grafana.
+//
+// The checksum of both resources is compared to the source checksum recorded
+// in Grafana when the resource was last pushed, to determine which side changed.
+func CompareResources(local *resources.Resource, remote *resources.Resource) (SyncState, error) {
+	if secrets.HasEncryptedValues(local.Object.Object) {
+		return SyncStateEncrypted, nil
+	}
+
+	localChecksum, err := process.Checksum(local)
+	if err != nil {
+		return "", err
+	}
+
+	remoteChecksum, err := process.Checksum(remote)
+	if err != nil {
+		return "", err
+	}
+
+	if localChecksum == remoteChecksum {
+		return SyncStateInSync, nil
+	}
+
+	if remote.Group() == resources.LegacyGroup {
+		return SyncStateDiffers, nil
+	}
+
+	pushed := remote.Annotations()[utils.AnnoKeySourceChecksum]
+	localChanged := localChecksum != pushed
+	remoteChanged := remoteChecksum != pushed
+
+	switch {
+	case pushed == "" || (localChanged && remoteChanged):
+		return SyncStateConflict, nil
+	case localChanged:
+		return SyncStateModifiedLocally, nil
+	default:
+		return SyncStateModifiedRemotely, nil
+	}
+}
@@ -0,0 +1,187 @@
+package remote_test
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/process"
+	"github.com/grafana/grafanactl/internal/resources/remote"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func dashboardWithTitle(name string, title string) *resources.Resource {
+	res := createDashboardResource(name)
+	_ = unstructured.SetNestedField(res.Object.Object, title, "spec", "title")
+
+	return res
+}
+
+// pushedDashboard returns a dashboard as it would be in Grafana once pushed from the given source.
+func pushedDashboard(t *testing.T, pushed *resources.Resource, title string) *resources.Resource {
+	t.Helper()
+
+	checksum, err := process.Checksum(pushed)
+	require.NoError(t, err)
+
+	res := dashboardWithTitle(pushed.Name(), title)
+	res.Object.SetAnnotations(map[string]string{
+		utils.AnnoKeyManagerKind:     string(resources.ResourceManagerKind),
+		utils.AnnoKeyManagerIdentity: resources.ResourceManagerIdentity,
+		utils.AnnoKeySourceChecksum:  checksum,
+		utils.AnnoKeyUpdatedBy:       "user:admin",
+	})
+
+	return res
+}
+
+func legacyDataSource(name string, url string) *resources.Resource {
+	return resources.MustFromObject(map[string]any{
+		"apiVersion": "legacy.grafana.app/v0",
+		"kind":       "DataSource",
+		"metadata":   map[string]any{"name": name},
+		"spec":       map[string]any{"type": "prometheus", "url": url},
+	}, resources.SourceInfo{})
+}
+
+func TestCompareResources(t *testing.T) {
+	original := dashboardWithTitle("dash", "original")
+
+	tests := []struct {
+		name   string
+		local  *resources.Resource
+		remote *resources.Resource
+		want   remote.SyncState
+	}{
+		{
+			name:   "unchanged",
+			local:  original,
+			remote: pushedDashboard(t, original, "original"),
+			want:   remote.SyncStateInSync,
+		},
+		{
+			name:   "modified locally",
+			local:  dashboardWithTitle("dash", "local"),
+			remote: pushedDashboard(t, original, "original"),
+			want:   remote.SyncStateModifiedLocally,
+		},
+		{
+			name:   "modified remotely",
+			local:  original,
+			remote: pushedDashboard(t, original, "remote"),
+			want:   remote.SyncStateModifiedRemotely,
+		},
+		{
+			name:   "modified on both sides",
+			local:  dashboardWithTitle("dash", "local"),
+			remote: pushedDashboard(t, original, "remote"),
+			want:   remote.SyncStateConflict,
+		},
+		{
+			name:   "same modification on both sides",
+			local:  dashboardWithTitle("dash", "new"),
+			remote: pushedDashboard(t, original, "new"),
+			want:   remote.SyncStateInSync,
+		},
+		{
+			name:   "never pushed and identical",
+			local:  original,
+			remote: dashboardWithTitle("dash", "original"),
+			want:   remote.SyncStateInSync,
+		},
+		{
+			name:   "never pushed and different",
+			local:  original,
+			remote: dashboardWithTitle("dash", "other"),
+			want:   remote.SyncStateConflict,
+		},
+		{
+			name:   "encrypted locally",
+			local:  dashboardWithTitle("dash", "ENC[age,YWJj]"),
+			remote: pushedDashboard(t, original, "original"),
+			want:   remote.SyncStateEncrypted,
+		},
+		{
+			name:   "legacy and different",
+			local:  legacyDataSource("prom", "http://localhost:9090"),
+			remote: legacyDataSource("prom", "http://prometheus:9090"),
+			want:   remote.SyncStateDiffers,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := require.New(t)
+
+			state, err := remote.CompareResources(test.local, test.remote)
+			req.NoError(err)
+			req.Equal(test.want, state)
+		})
+	}
+}
+
+func TestStatusChecker_Status(t *testing.T) {
+	req := require.New(t)
+
+	dashboardDescriptor := resources.Descriptor{
+		GroupVersion: schema.GroupVersion{Group: "dashboard.grafana.app", Version: "v1"},
+		Kind:         "Dashboard",
+		Singular:     "dashboard",
+		Plural:       "dashboards",
+	}
+
+	synced := dashboardWithTitle("synced", "synced")
+	changed := dashboardWithTitle("changed", "before")
+
+	client := &mockPullClient{
+		listResults: map[string][]unstructured.Unstructured{
+			"dashboards": {
+				pushedDashboard(t, synced, "synced").ToUnstructured(),
+				pushedDashboard(t, changed, "before").ToUnstructured(),
+				createDashboardResource("remote-only").ToUnstructured(),
+			},
+		},
+	}
+	registry := &mockPushRegistry{
+		supportedResources: []resources.Descriptor{dashboardDescriptor},
+	}
+
+	checker := remote.NewStatusCheckerWithClient(client, registry)
+
+	statuses, err := checker.Status(t.Context(), remote.StatusRequest{
+		Resources: resources.NewResources(
+			synced,
+			dashboardWithTitle("changed", "after"),
+			createDashboardResource("local-only"),
+		),
+	})
+	req.NoError(err)
+
+	got := make(map[string]remote.SyncState, len(statuses))
+	names := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		got[status.Resource().Name()] = status.State
+		names = append(names, status.Resource().Name())
+	}
+
+	req.Equal([]string{"changed", "local-only", "remote-only", "synced"}, names)
+	req.Equal(map[string]remote.SyncState{
+		"synced":      remote.SyncStateInSync,
+		"changed":     remote.SyncStateModifiedLocally,
+		"local-only":  remote.SyncStateMissingRemotely,
+		"remote-only": remote.SyncStateOnlyRemote,
+	}, got)
+}
+
+func TestStatusChecker_Status_UnsupportedResource(t *testing.T) {
+	req := require.New(t)
+
+	checker := remote.NewStatusCheckerWithClient(&mockPullClient{}, &mockPushRegistry{})
+
+	_, err := checker.Status(t.Context(), remote.StatusRequest{
+		Resources: resources.NewResources(createDashboardResource("dash")),
+	})
+	req.ErrorContains(err, "resource not supported by the API")
+}
@@ -10,10 +10,12 @@ import (
 // OperationSummary tracks the results of a batch resource operation in a thread-safe manner.
 // It uses atomic counters for success/failure counts and a mutex-protected slice for failure details.
 type OperationSummary struct {
-	successCount atomic.Int64
-	failedCount  atomic.Int64
-	mu           sync.Mutex
-	failures     []OperationFailure
+	successCount     atomic.Int64
+	failedCount      atomic.Int64
+	unvalidatedCount atomic.Int64
+	mu               sync.Mutex
+	failures         []OperationFailure
+	rollback         *RollbackReport
 }
 
 // OperationFailure describes a single resource operation failure.
@@ -26,11 +28,34 @@ type OperationFailure struct {
 	Error error
 }
 
+// RollbackReport describes how the changes of an atomic operation were reverted,
+// after one of its resources failed.
+type RollbackReport struct {
+	// Restored lists the updated resources restored to their previous state.
+	Restored []*resources.Resource
+
+	// Deleted lists the created resources that were deleted.
+	Deleted []*resources.Resource
+
+	// Failures lists the resources that could not be rolled back.
+	Failures []OperationFailure
+}
+
+// Complete returns true if every change was rolled back.
+func (r *RollbackReport) Complete() bool {
+	return len(r.Failures) == 0
+}
+
 // RecordSuccess records a successful operation.
 func (s *OperationSummary) RecordSuccess() {
 	s.successCount.Add(1)
 }
 
+// RecordUnvalidated records a resource that could not be validated by a dry-run.
+func (s *OperationSummary) RecordUnvalidated() {
+	s.unvalidatedCount.Add(1)
+}
+
 // RecordFailure records a failed operation. res may be nil when the failure is not
 // associated with a specific resource (e.g., a filter-level pull failure).
 func (s *OperationSummary) RecordFailure(res *resources.Resource, err error) {
@@ -55,6 +80,11 @@ func (s *OperationSummary) FailedCount() int {
 	return int(s.failedCount.Load())
 }
 
+// UnvalidatedCount returns the number of resources that could not be validated by a dry-run.
+func (s *OperationSummary) UnvalidatedCount() int {
+	return int(s.unvalidatedCount.Load())
+}
+
 // Failures returns all recorded operation failures.
 func (s *OperationSummary) Failures() []OperationFailure {
 	s.mu.Lock()
@@ -62,3 +92,19 @@ func (s *OperationSummary) Failures() []OperationFailure {
 
 	return s.failures
 }
+
+// Rollback returns the report of the rollback of the operation,
+// or nil if the operation was not rolled back.
+func (s *OperationSummary) Rollback() *RollbackReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.rollback
+}
+
+func (s *OperationSummary) recordRollback(report *RollbackReport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rollback = report
+}
@@ -17,10 +17,33 @@ import (
 const (
 	// TODO: change once we have a proper manager kind for grafanactl.
 	ResourceManagerKind = utils.ManagerKindKubectl
+	// ResourceManagerIdentity identifies grafanactl as the manager of resources.
+	// TODO: use version information to set the identity.
+	ResourceManagerIdentity = "grafanactl"
 	// TODO: move this to grafana/grafana.
 	AnnotationSavedFromUI = "grafana.app/saved-from-ui"
+	// AnnotationAdoptedFrom records the manager of a resource before it was adopted by grafanactl.
+	AnnotationAdoptedFrom = "grafana.app/adoptedFrom"
 )
 
+// IsReservedAnnotation returns true if the annotation holds a manager or source
+// property. These annotations are set by the tools managing resources.
+func IsReservedAnnotation(key string) bool {
+	switch key {
+	case utils.AnnoKeyManagerKind,
+		utils.AnnoKeyManagerIdentity,
+		utils.AnnoKeyManagerAllowsEdits,
+		utils.AnnoKeyManagerSuspended,
+		utils.AnnoKeySourcePath,
+		utils.AnnoKeySourceChecksum,
+		utils.AnnoKeySourceTimestamp,
+		AnnotationAdoptedFrom:
+		return true
+	default:
+		return false
+	}
+}
+
 // ResourceRef is a unique identifier for a resource.
 type ResourceRef string
 
@@ -162,6 +185,13 @@ func (r *Resource) IsManaged() bool {
 	return r.GetManagerKind() == ResourceManagerKind
 }
 
+// IsSuspended returns true if the management of the resource is suspended:
+// its manager keeps ownership of the resource, but doesn't update it.
+func (r *Resource) IsSuspended() bool {
+	m, ok := r.Raw.GetManagerProperties()
+	return ok && m.Suspended
+}
+
 // GetManagerKind returns the kind of the manager that manages the resource.
 func (r *Resource) GetManagerKind() utils.ManagerKind {
 	m, ok := r.Raw.GetManagerProperties()
@@ -374,6 +404,44 @@ func SortUnstructured(items []unstructured.Unstructured) {
 type SourceInfo struct {
 	Path   string
 	Format format.Format
+	// Overlay is the path of the overlay file the resource was rendered from, if any.
+	// Rendered resources can differ from the content of the file at Path.
+	Overlay string
+	// Rendered is true when the file at Path was rendered as a template
+	// before being decoded: the file holds placeholders rather than the
+	// values of the resource.
+	Rendered bool
+	// Encrypted is true when the file at Path holds encrypted values that were
+	// decrypted in memory: they must never be written back in plaintext.
+	Encrypted bool
+	// Processed is true when the resource was modified by processors after
+	// being decoded: it differs from the content of the file at Path.
+	Processed bool
+}
+
+// Writable returns an error if the resource can not be written back to its
+// source: when the file at Path doesn't hold the resource as it was read.
+func (s *SourceInfo) Writable() error {
+	var reason string
+
+	switch {
+	case s.Path == "":
+		reason = "generated from a script"
+	case s.Overlay != "":
+		reason = "rendered from an overlay"
+	case s.Rendered:
+		reason = "rendered from a template"
+	case s.Processed:
+		reason = "modified by processors"
+	case s.Encrypted:
+		reason = "holding encrypted values"
+	case s.Format == format.CUE:
+		reason = "evaluated from CUE packages"
+	default:
+		return nil
+	}
+
+	return fmt.Errorf("resources %s can not be written back to their source", reason)
 }
 
 func (s *SourceInfo) String() string {
@@ -0,0 +1,37 @@
+package resources_test
+
+import (
+	"testing"
+
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceInfo_Writable(t *testing.T) {
+	tests := []struct {
+		name   string
+		source resources.SourceInfo
+		err    string
+	}{
+		{name: "file", source: resources.SourceInfo{Path: "dashboard.yaml", Format: format.YAML}},
+		{name: "script", source: resources.SourceInfo{}, err: "generated from a script"},
+		{name: "overlay", source: resources.SourceInfo{Path: "dashboard.yaml", Overlay: "overlay.yaml"}, err: "overlay"},
+		{name: "template", source: resources.SourceInfo{Path: "dashboard.yaml", Rendered: true}, err: "template"},
+		{name: "processors", source: resources.SourceInfo{Path: "dashboard.yaml", Processed: true}, err: "processors"},
+		{name: "encrypted", source: resources.SourceInfo{Path: "dashboard.yaml", Encrypted: true}, err: "encrypted"},
+		{name: "cue", source: resources.SourceInfo{Path: "dashboards.cue", Format: format.CUE}, err: "CUE"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.source.Writable()
+			if test.err == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"filippo.io/age"
+)
+
+// SOPSCommand is the command used to decrypt files encrypted with SOPS.
+const SOPSCommand = "sops"
+
+// Decrypter decrypts the secrets of resources, in memory.
+//
+// Values encrypted with age are decrypted with the identities configured in the
+// environment (see LoadIdentities), which are only loaded when encrypted values
+// are found. Files encrypted with SOPS are decrypted by the sops command.
+//
+// A Decrypter can be used concurrently.
+type Decrypter struct {
+	// Identities used to decrypt values.
+	// If nil, they are loaded from the environment on first use.
+	Identities []age.Identity
+
+	// DecryptSOPS decrypts a file encrypted with SOPS, in the given format.
+	// If nil, the sops command is used.
+	DecryptSOPS func(ctx context.Context, data []byte, format string) ([]byte, error)
+
+	// Optional leaves the values encrypted with age as-is when no identity is
+	// configured, instead of failing with ErrNoIdentity.
+	Optional bool
+
+	once sync.Once
+	err  error
+}
+
+// IsSOPS returns true if the object was read from a file encrypted with SOPS.
+func IsSOPS(object map[string]any) bool {
+	metadata, ok := object["sops"].(map[string]any)
+	if !ok {
+		return false
+	}
+
+	_, ok = metadata["mac"]
+
+	return ok
+}
+
+// Decrypt decrypts the values of an object encrypted with age, in place.
+func (d *Decrypter) Decrypt(object map[string]any) error {
+	if !HasEncryptedValues(object) {
+		return nil
+	}
+
+	identities, err := d.identities()
+	if d.Optional && errors.Is(err, ErrNoIdentity) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = Decrypt(object, identities)
+
+	return err
+}
+
+// DecryptFile decrypts the content of a file encrypted with SOPS.
+// The decrypted content is only kept in memory.
+func (d *Decrypter) DecryptFile(ctx context.Context, data []byte, format string) ([]byte, error) {
+	if d.DecryptSOPS != nil {
+		return d.DecryptSOPS(ctx, data, format)
+	}
+
+	return decryptWithSOPS(ctx, data, format)
+}
+
+func (d *Decrypter) identities() ([]age.Identity, error) {
+	d.once.Do(func() {
+		if d.Identities == nil {
+			d.Identities, d.err = LoadIdentities()
+		}
+	})
+
+	return d.Identities, d.err
+}
+
+// decryptWithSOPS decrypts data with the sops command. The decrypted content
+// is read from its standard output, so that it is never written to disk.
+//
+// The encrypted content is given to sops through a temporary file: reading
+// from /dev/stdin isn't portable.
+//
+// Keys configured for grafanactl are made available to sops.
+func decryptWithSOPS(ctx context.Context, data []byte, format string) ([]byte, error) {
+	if _, err := exec.LookPath(SOPSCommand); err != nil {
+		return nil, fmt.Errorf("the file is encrypted with SOPS, but the %s command was not found: %w", SOPSCommand, err)
+	}
+
+	input, err := writeTemp(data, format)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(input)
+
+	//nolint:gosec
+	cmd := exec.CommandContext(ctx, SOPSCommand,
+		"--decrypt", "--input-type", format, "--output-type", format, input,
+	)
+	cmd.Env = os.Environ()
+
+	if key := os.Getenv(KeyEnvVar); key != "" && os.Getenv(sopsKeyEnvVar) == "" {
+		cmd.Env = append(cmd.Env, sopsKeyEnvVar+"="+key)
+	}
+	if keyFile := os.Getenv(KeyFileEnvVar); keyFile != "" && os.Getenv(sopsKeyFileEnvVar) == "" {
+		cmd.Env = append(cmd.Env, sopsKeyFileEnvVar+"="+keyFile)
+	}
+
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	decrypted, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, errors.New(message)
+		}
+
+		return nil, err
+	}
+
+	return decrypted, nil
+}
+
+// writeTemp writes encrypted data to a temporary file, only readable by the
+// current user, and returns its path.
+func writeTemp(data []byte, format string) (string, error) {
+	file, err := os.CreateTemp("", "grafanactl-sops-*."+format)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return "", err
+	}
+
+	return file.Name(), nil
+}
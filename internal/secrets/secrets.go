@@ -0,0 +1,336 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/adrg/xdg"
+)
+
+const (
+	// FieldsAnnotation lists the fields of a resource holding secrets,
+	// as comma-separated paths, e.g. "spec.settings.url,spec.settings.password".
+	FieldsAnnotation = "grafanactl.grafana.app/secret-fields"
+
+	// KeyEnvVar is the environment variable holding age identities.
+	KeyEnvVar = "GRAFANACTL_AGE_KEY"
+	// KeyFileEnvVar is the environment variable holding the path of an age identities file.
+	KeyFileEnvVar = "GRAFANACTL_AGE_KEY_FILE"
+
+	sopsKeyEnvVar     = "SOPS_AGE_KEY"
+	sopsKeyFileEnvVar = "SOPS_AGE_KEY_FILE"
+
+	encryptedPrefix = "ENC[age,"
+	encryptedSuffix = "]"
+)
+
+// ErrNoIdentity is returned when encrypted values are found, but no age identity is configured.
+var ErrNoIdentity = errors.New("no age identity found: set " + KeyEnvVar + " or " + KeyFileEnvVar)
+
+// defaultFields are always considered secret.
+//
+//nolint:gochecknoglobals
+var defaultFields = []string{"spec.secureJsonData"}
+
+// IsEncrypted returns true if the value was encrypted with age.
+//
+// Values encrypted with age are strings of the form ENC[age,<base64 age ciphertext>]:
+// they can be committed as-is, and are only decrypted in memory, when
+// resources are sent to Grafana.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix) && strings.HasSuffix(value, encryptedSuffix)
+}
+
+// LoadIdentities loads the age identities configured in the environment.
+//
+// Identities are read from, in order: GRAFANACTL_AGE_KEY, GRAFANACTL_AGE_KEY_FILE,
+// SOPS_AGE_KEY, SOPS_AGE_KEY_FILE and $XDG_CONFIG_HOME/sops/age/keys.txt.
+// The first one set is used.
+func LoadIdentities() ([]age.Identity, error) {
+	for _, env := range []string{KeyEnvVar, sopsKeyEnvVar} {
+		if keys := os.Getenv(env); keys != "" {
+			identities, err := age.ParseIdentities(strings.NewReader(keys))
+			if err != nil {
+				return nil, fmt.Errorf("invalid age identities in %s: %w", env, err)
+			}
+
+			return identities, nil
+		}
+	}
+
+	paths := []string{os.Getenv(KeyFileEnvVar), os.Getenv(sopsKeyFileEnvVar)}
+	for _, path := range paths {
+		if path != "" {
+			return readIdentities(path)
+		}
+	}
+
+	defaultPath := filepath.Join(xdg.ConfigHome, "sops", "age", "keys.txt")
+	if _, err := os.Stat(defaultPath); err == nil {
+		return readIdentities(defaultPath)
+	}
+
+	return nil, ErrNoIdentity
+}
+
+func readIdentities(path string) ([]age.Identity, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	identities, err := age.ParseIdentities(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid age identities in %s: %w", path, err)
+	}
+
+	return identities, nil
+}
+
+// ParseRecipients parses age recipients, e.g. "age1...".
+func ParseRecipients(values []string) ([]age.Recipient, error) {
+	return age.ParseRecipients(strings.NewReader(strings.Join(values, "\n")))
+}
+
+// RecipientsOf returns the recipients matching the given identities,
+// so that values can be encrypted for the holder of the identities.
+func RecipientsOf(identities []age.Identity) []age.Recipient {
+	recipients := make([]age.Recipient, 0, len(identities))
+	for _, identity := range identities {
+		if x25519, ok := identity.(*age.X25519Identity); ok {
+			recipients = append(recipients, x25519.Recipient())
+		}
+	}
+
+	return recipients
+}
+
+// SecretFields returns the paths of the fields of an object holding secrets:
+// spec.secureJsonData, and the fields listed in the FieldsAnnotation annotation.
+func SecretFields(object map[string]any) []string {
+	fields := slices.Clone(defaultFields)
+
+	metadata, _ := object["metadata"].(map[string]any)
+	annotations, _ := metadata["annotations"].(map[string]any)
+	listed, _ := annotations[FieldsAnnotation].(string)
+
+	for field := range strings.SplitSeq(listed, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+
+	slices.Sort(fields)
+
+	return slices.Compact(fields)
+}
+
+// Encrypt encrypts the secret fields of an object for the given recipients.
+//
+// The given fields are marked as secret in the FieldsAnnotation annotation
+// of the object, along with the ones already listed. Values already encrypted
+// are left untouched. It returns the number of values encrypted.
+func Encrypt(object map[string]any, recipients []age.Recipient, fields []string) (int, error) {
+	if len(recipients) == 0 {
+		return 0, errors.New("no age recipient to encrypt values for")
+	}
+
+	if len(fields) != 0 {
+		markSecret(object, fields)
+	}
+
+	count := 0
+	for _, field := range SecretFields(object) {
+		parent, key, ok := lookup(object, field)
+		if !ok {
+			continue
+		}
+
+		encrypted, err := transform(parent[key], func(value string) (string, error) {
+			if IsEncrypted(value) {
+				return value, nil
+			}
+
+			count++
+
+			return encryptValue(value, recipients)
+		})
+		if err != nil {
+			return 0, fmt.Errorf("could not encrypt %s: %w", field, err)
+		}
+
+		parent[key] = encrypted
+	}
+
+	return count, nil
+}
+
+// Decrypt decrypts all the encrypted values of an object, wherever they are.
+// It returns the number of values decrypted.
+func Decrypt(object map[string]any, identities []age.Identity) (int, error) {
+	count := 0
+
+	// Maps are transformed in place: only their values change.
+	if _, err := transform(object, func(value string) (string, error) {
+		if !IsEncrypted(value) {
+			return value, nil
+		}
+
+		if len(identities) == 0 {
+			return "", ErrNoIdentity
+		}
+
+		count++
+
+		return decryptValue(value, identities)
+	}); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// HasEncryptedValues returns true if any value of the object is encrypted with age.
+func HasEncryptedValues(object map[string]any) bool {
+	found := false
+
+	_, _ = transform(object, func(value string) (string, error) {
+		found = found || IsEncrypted(value)
+		return value, nil
+	})
+
+	return found
+}
+
+func encryptValue(value string, recipients []age.Recipient) (string, error) {
+	buffer := &bytes.Buffer{}
+
+	writer, err := age.Encrypt(buffer, recipients...)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := io.WriteString(writer, value); err != nil {
+		return "", err
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(buffer.Bytes()) + encryptedSuffix, nil
+}
+
+func decryptValue(value string, identities []age.Identity) (string, error) {
+	encoded := strings.TrimSuffix(strings.TrimPrefix(value, encryptedPrefix), encryptedSuffix)
+
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted value: %w", err)
+	}
+
+	reader, err := age.Decrypt(bytes.NewReader(ciphertext), identities...)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// transform applies fn to all the string values found in value, recursively.
+// Maps and slices are modified in place.
+func transform(value any, fn func(string) (string, error)) (any, error) {
+	switch typed := value.(type) {
+	case string:
+		return fn(typed)
+	case map[string]any:
+		for key, item := range typed {
+			transformed, err := transform(item, fn)
+			if err != nil {
+				return nil, err
+			}
+
+			typed[key] = transformed
+		}
+	case []any:
+		for i, item := range typed {
+			transformed, err := transform(item, fn)
+			if err != nil {
+				return nil, err
+			}
+
+			typed[i] = transformed
+		}
+	}
+
+	return value, nil
+}
+
+// lookup returns the map holding the field at the given dotted path, and its key.
+func lookup(object map[string]any, path string) (map[string]any, string, bool) {
+	keys := strings.Split(path, ".")
+	parent := object
+
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := parent[key].(map[string]any)
+		if !ok {
+			return nil, "", false
+		}
+
+		parent = child
+	}
+
+	key := keys[len(keys)-1]
+	if _, ok := parent[key]; !ok {
+		return nil, "", false
+	}
+
+	return parent, key, true
+}
+
+func markSecret(object map[string]any, fields []string) {
+	metadata, _ := object["metadata"].(map[string]any)
+	annotations, _ := metadata["annotations"].(map[string]any)
+	listed, _ := annotations[FieldsAnnotation].(string)
+
+	marked := slices.DeleteFunc(strings.Split(listed, ","), func(field string) bool {
+		return strings.TrimSpace(field) == ""
+	})
+	for _, field := range fields {
+		if !slices.Contains(defaultFields, field) {
+			marked = append(marked, field)
+		}
+	}
+
+	if len(marked) == 0 {
+		return
+	}
+
+	slices.Sort(marked)
+
+	if metadata == nil {
+		metadata = make(map[string]any)
+		object["metadata"] = metadata
+	}
+
+	if annotations == nil {
+		annotations = make(map[string]any)
+		metadata["annotations"] = annotations
+	}
+
+	annotations[FieldsAnnotation] = strings.Join(slices.Compact(marked), ",")
+}
@@ -0,0 +1,162 @@
+package secrets_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/grafana/grafanactl/internal/secrets"
+	"github.com/stretchr/testify/require"
+)
+
+func newIdentity(t *testing.T) *age.X25519Identity {
+	t.Helper()
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	return identity
+}
+
+func datasource() map[string]any {
+	return map[string]any{
+		"apiVersion": "legacy.grafana.app/v0",
+		"kind":       "DataSource",
+		"metadata":   map[string]any{"name": "prom"},
+		"spec": map[string]any{
+			"url":            "http://prometheus:9090",
+			"basicAuthUser":  "admin",
+			"secureJsonData": map[string]any{"basicAuthPassword": "hunter2"},
+		},
+	}
+}
+
+func TestEncrypt_Decrypt(t *testing.T) {
+	req := require.New(t)
+	identity := newIdentity(t)
+
+	object := datasource()
+
+	count, err := secrets.Encrypt(object, []age.Recipient{identity.Recipient()}, []string{"spec.basicAuthUser"})
+	req.NoError(err)
+	req.Equal(2, count)
+
+	spec := object["spec"].(map[string]any)
+	req.True(secrets.IsEncrypted(spec["basicAuthUser"].(string)))
+	req.True(secrets.IsEncrypted(spec["secureJsonData"].(map[string]any)["basicAuthPassword"].(string)))
+	req.Equal("http://prometheus:9090", spec["url"])
+	req.Equal(map[string]any{
+		secrets.FieldsAnnotation: "spec.basicAuthUser",
+	}, object["metadata"].(map[string]any)["annotations"])
+	req.True(secrets.HasEncryptedValues(object))
+
+	// Values already encrypted are left untouched.
+	encrypted := spec["basicAuthUser"]
+
+	count, err = secrets.Encrypt(object, []age.Recipient{identity.Recipient()}, nil)
+	req.NoError(err)
+	req.Zero(count)
+	req.Equal(encrypted, spec["basicAuthUser"])
+
+	count, err = secrets.Decrypt(object, []age.Identity{identity})
+	req.NoError(err)
+	req.Equal(2, count)
+	req.Equal("admin", spec["basicAuthUser"])
+	req.Equal(map[string]any{"basicAuthPassword": "hunter2"}, spec["secureJsonData"])
+	req.False(secrets.HasEncryptedValues(object))
+}
+
+func TestDecrypt_wrongIdentity(t *testing.T) {
+	req := require.New(t)
+
+	object := datasource()
+	_, err := secrets.Encrypt(object, []age.Recipient{newIdentity(t).Recipient()}, nil)
+	req.NoError(err)
+
+	_, err = secrets.Decrypt(object, []age.Identity{newIdentity(t)})
+	req.ErrorAs(err, new(*age.NoIdentityMatchError))
+
+	_, err = secrets.Decrypt(object, nil)
+	req.ErrorIs(err, secrets.ErrNoIdentity)
+}
+
+func TestSecretFields(t *testing.T) {
+	req := require.New(t)
+
+	req.Equal([]string{"spec.secureJsonData"}, secrets.SecretFields(datasource()))
+
+	req.Equal([]string{"spec.secureJsonData", "spec.settings.password", "spec.settings.token"}, secrets.SecretFields(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]any{
+				secrets.FieldsAnnotation: "spec.settings.token, spec.settings.password",
+			},
+		},
+	}))
+}
+
+func TestLoadIdentities(t *testing.T) {
+	req := require.New(t)
+	identity := newIdentity(t)
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(secrets.KeyFileEnvVar, "")
+	t.Setenv("SOPS_AGE_KEY", "")
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+
+	t.Setenv(secrets.KeyEnvVar, identity.String())
+
+	identities, err := secrets.LoadIdentities()
+	req.NoError(err)
+	req.Equal([]age.Recipient{identity.Recipient()}, secrets.RecipientsOf(identities))
+
+	t.Setenv(secrets.KeyEnvVar, "not an identity")
+
+	_, err = secrets.LoadIdentities()
+	req.ErrorContains(err, secrets.KeyEnvVar)
+}
+
+func TestDecrypter(t *testing.T) {
+	req := require.New(t)
+	identity := newIdentity(t)
+
+	object := datasource()
+	_, err := secrets.Encrypt(object, []age.Recipient{identity.Recipient()}, nil)
+	req.NoError(err)
+
+	decrypter := &secrets.Decrypter{
+		Identities: []age.Identity{identity},
+		DecryptSOPS: func(_ context.Context, data []byte, format string) ([]byte, error) {
+			return []byte(strings.ToUpper(format) + ":" + string(data)), nil
+		},
+	}
+
+	req.NoError(decrypter.Decrypt(object))
+	req.Equal("hunter2", object["spec"].(map[string]any)["secureJsonData"].(map[string]any)["basicAuthPassword"])
+
+	decrypted, err := decrypter.DecryptFile(t.Context(), []byte("data"), "yaml")
+	req.NoError(err)
+	req.Equal("YAML:data", string(decrypted))
+
+	req.True(secrets.IsSOPS(map[string]any{"sops": map[string]any{"mac": "ENC[AES256_GCM,...]"}}))
+	req.False(secrets.IsSOPS(datasource()))
+}
+
+func TestDecrypter_optional(t *testing.T) {
+	req := require.New(t)
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(secrets.KeyEnvVar, "")
+	t.Setenv(secrets.KeyFileEnvVar, "")
+	t.Setenv("SOPS_AGE_KEY", "")
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+
+	object := datasource()
+	_, err := secrets.Encrypt(object, []age.Recipient{newIdentity(t).Recipient()}, nil)
+	req.NoError(err)
+
+	req.ErrorIs((&secrets.Decrypter{}).Decrypt(object), secrets.ErrNoIdentity)
+
+	req.NoError((&secrets.Decrypter{Optional: true}).Decrypt(object))
+	req.True(secrets.HasEncryptedValues(object))
+}
@@ -7,15 +7,14 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httputil"
-	"os"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/grafana/grafana-app-sdk/logging"
 	"github.com/grafana/grafana/pkg/apimachinery/utils"
 	"github.com/grafana/grafanactl/internal/config"
-	"github.com/grafana/grafanactl/internal/format"
 	"github.com/grafana/grafanactl/internal/httputils"
 	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/local"
 	"github.com/grafana/grafanactl/internal/server/grafana"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -179,8 +178,7 @@ func (c *DashboardProxy) dashboardJSONPostHandler() http.HandlerFunc {
 			return
 		}
 
-		if resource.SourcePath() == "" {
-			err := errors.New("resources generated from a script can not be persisted through grafanactl serve")
+		if err := resource.Source.Writable(); err != nil {
 			httputils.Error(r, w, err.Error(), err, http.StatusBadRequest)
 			return
 		}
@@ -208,19 +206,7 @@ func (c *DashboardProxy) dashboardJSONPostHandler() http.HandlerFunc {
 		// Reset the generation to 0.
 		object.SetGeneration(0)
 
-		file, err := os.OpenFile(resource.SourcePath(), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
-		if err != nil {
-			httputils.Error(r, w, err.Error(), err, http.StatusInternalServerError)
-			return
-		}
-		defer file.Close()
-
-		var codec format.Encoder = format.NewJSONCodec()
-		if resource.SourceFormat() == format.YAML {
-			codec = format.NewYAMLCodec()
-		}
-
-		if err := codec.Encode(file, object); err != nil {
+		if err := local.WriteSource(resource, object); err != nil {
 			httputils.Error(r, w, err.Error(), err, http.StatusInternalServerError)
 			return
 		}
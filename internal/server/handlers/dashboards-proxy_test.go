@@ -0,0 +1,169 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/go-chi/chi/v5"
+	"github.com/grafana/grafanactl/internal/config"
+	"github.com/grafana/grafanactl/internal/format"
+	"github.com/grafana/grafanactl/internal/resources"
+	"github.com/grafana/grafanactl/internal/resources/local"
+	"github.com/grafana/grafanactl/internal/resources/process"
+	"github.com/grafana/grafanactl/internal/secrets"
+	"github.com/grafana/grafanactl/internal/server/handlers"
+	"github.com/stretchr/testify/require"
+)
+
+const dashboardPath = "/apis/dashboard.grafana.app/v1/namespaces/default/dashboards/home"
+
+const savedDashboard = `{
+  "apiVersion": "dashboard.grafana.app/v1",
+  "kind": "Dashboard",
+  "metadata": {"name": "home"},
+  "spec": {"title": "Saved from the UI"}
+}`
+
+func readDashboard(t *testing.T, reader local.FSReader, content string) (*resources.Resources, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "dashboard.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	reader.Decoders = format.Codecs()
+	reader.StopOnError = true
+
+	res := resources.NewResources()
+	require.NoError(t, reader.Read(t.Context(), res, nil, []string{path}))
+
+	return res, path
+}
+
+func saveDashboard(t *testing.T, res *resources.Resources) *httptest.ResponseRecorder {
+	t.Helper()
+
+	router := chi.NewRouter()
+	for _, endpoint := range handlers.NewDashboardProxy(&config.Context{}, res).Endpoints(nil) {
+		router.Method(endpoint.Method, endpoint.URL, endpoint.Handler)
+	}
+
+	request := httptest.NewRequestWithContext(t.Context(), http.MethodPut, dashboardPath, strings.NewReader(savedDashboard))
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+
+	return response
+}
+
+func TestDashboardProxy_save(t *testing.T) {
+	req := require.New(t)
+
+	res, path := readDashboard(t, local.FSReader{}, `apiVersion: dashboard.grafana.app/v1
+kind: Dashboard
+metadata:
+  name: home
+spec:
+  title: Home
+`)
+
+	response := saveDashboard(t, res)
+	req.Equal(http.StatusOK, response.Code, response.Body.String())
+
+	content, err := os.ReadFile(path)
+	req.NoError(err)
+	req.Contains(string(content), "title: Saved from the UI")
+}
+
+func TestDashboardProxy_save_templated(t *testing.T) {
+	req := require.New(t)
+
+	source := `apiVersion: dashboard.grafana.app/v1
+kind: Dashboard
+metadata:
+  name: home
+spec:
+  title: {{ .Values.title }}
+`
+
+	res, path := readDashboard(t, local.FSReader{
+		Template: &local.Template{Values: map[string]any{"title": "Home"}},
+	}, source)
+
+	response := saveDashboard(t, res)
+	req.Equal(http.StatusBadRequest, response.Code)
+	req.Contains(response.Body.String(), "template")
+
+	content, err := os.ReadFile(path)
+	req.NoError(err)
+	req.Equal(source, string(content))
+}
+
+func TestDashboardProxy_save_encrypted(t *testing.T) {
+	req := require.New(t)
+
+	identity, err := age.GenerateX25519Identity()
+	req.NoError(err)
+
+	object := map[string]any{
+		"apiVersion": "dashboard.grafana.app/v1",
+		"kind":       "Dashboard",
+		"metadata":   map[string]any{"name": "home"},
+		"spec":       map[string]any{"title": "Home", "token": "hunter2"},
+	}
+	_, err = secrets.Encrypt(object, []age.Recipient{identity.Recipient()}, []string{"spec.token"})
+	req.NoError(err)
+
+	source, err := json.Marshal(object)
+	req.NoError(err)
+
+	res, path := readDashboard(t, local.FSReader{
+		Decrypter: &secrets.Decrypter{Identities: []age.Identity{identity}},
+	}, string(source))
+
+	response := saveDashboard(t, res)
+	req.Equal(http.StatusBadRequest, response.Code)
+	req.Contains(response.Body.String(), "encrypted")
+
+	content, err := os.ReadFile(path)
+	req.NoError(err)
+	req.Contains(string(content), "ENC[age,")
+	req.NotContains(string(content), "hunter2")
+}
+
+func TestDashboardProxy_save_processed(t *testing.T) {
+	req := require.New(t)
+
+	source := `apiVersion: dashboard.grafana.app/v1
+kind: Dashboard
+metadata:
+  name: home
+spec:
+  id: 42
+  title: Home
+`
+
+	res, path := readDashboard(t, local.FSReader{
+		Processors: []local.Processor{&process.IDStripper{}},
+	}, source)
+
+	response := saveDashboard(t, res)
+	req.Equal(http.StatusBadRequest, response.Code)
+	req.Contains(response.Body.String(), "processors")
+
+	content, err := os.ReadFile(path)
+	req.NoError(err)
+	req.Equal(source, string(content))
+
+	// Resources left untouched by processors can be saved.
+	res, _ = readDashboard(t, local.FSReader{
+		Processors: []local.Processor{&process.IDStripper{}},
+	}, strings.ReplaceAll(source, "  id: 42\n", ""))
+
+	response = saveDashboard(t, res)
+	req.Equal(http.StatusOK, response.Code, response.Body.String())
+}
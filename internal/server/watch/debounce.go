@@ -0,0 +1,80 @@
+package watch
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Debouncer groups files reported in quick succession, as editors and
+// generators tend to write several times to the same files.
+// The batch of files is flushed once no new file has been reported for the
+// configured delay. Batches are flushed one at a time, in order.
+type Debouncer struct {
+	delay time.Duration
+	flush func(files []string)
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	pending map[string]struct{}
+
+	// flushing ensures that batches are never processed concurrently.
+	flushing sync.Mutex
+}
+
+// NewDebouncer creates a Debouncer calling flush with the sorted list of
+// files reported during a burst.
+func NewDebouncer(delay time.Duration, flush func(files []string)) *Debouncer {
+	return &Debouncer{
+		delay:   delay,
+		flush:   flush,
+		pending: make(map[string]struct{}),
+	}
+}
+
+// Add reports a file, and delays the flush of the current batch.
+func (d *Debouncer) Add(file string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.pending[file] = struct{}{}
+
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+
+	d.timer = time.AfterFunc(d.delay, d.doFlush)
+}
+
+// Stop discards the pending files.
+func (d *Debouncer) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+
+	d.pending = make(map[string]struct{})
+}
+
+func (d *Debouncer) doFlush() {
+	d.flushing.Lock()
+	defer d.flushing.Unlock()
+
+	d.mu.Lock()
+	files := make([]string, 0, len(d.pending))
+	for file := range d.pending {
+		files = append(files, file)
+	}
+	d.pending = make(map[string]struct{})
+	d.mu.Unlock()
+
+	if len(files) == 0 {
+		return
+	}
+
+	slices.Sort(files)
+
+	d.flush(files)
+}
@@ -0,0 +1,61 @@
+package watch_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafanactl/internal/server/watch"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebouncer(t *testing.T) {
+	req := require.New(t)
+
+	batches := make(chan []string, 10)
+	debouncer := watch.NewDebouncer(50*time.Millisecond, func(files []string) {
+		batches <- files
+	})
+	t.Cleanup(debouncer.Stop)
+
+	// A burst of events is flushed as a single, de-duplicated batch.
+	debouncer.Add("b.yaml")
+	debouncer.Add("a.yaml")
+	debouncer.Add("b.yaml")
+
+	select {
+	case batch := <-batches:
+		req.Equal([]string{"a.yaml", "b.yaml"}, batch)
+	case <-time.After(time.Second):
+		req.Fail("the batch was not flushed")
+	}
+
+	// Events reported after a flush start a new batch.
+	debouncer.Add("c.yaml")
+
+	select {
+	case batch := <-batches:
+		req.Equal([]string{"c.yaml"}, batch)
+	case <-time.After(time.Second):
+		req.Fail("the batch was not flushed")
+	}
+
+	req.Empty(batches)
+}
+
+func TestDebouncer_Stop(t *testing.T) {
+	req := require.New(t)
+
+	flushed := make(chan []string, 1)
+	debouncer := watch.NewDebouncer(20*time.Millisecond, func(files []string) {
+		flushed <- files
+	})
+
+	debouncer.Add("a.yaml")
+	debouncer.Stop()
+
+	select {
+	case <-flushed:
+		req.Fail("discarded files were flushed")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
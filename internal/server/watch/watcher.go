@@ -18,6 +18,7 @@ type Watcher struct {
 	logger   logging.Logger
 	notifier *fsnotify.Watcher
 	callback func(string)
+	onRemove func(string)
 }
 
 func NewWatcher(ctx context.Context, callback func(string)) (*Watcher, error) {
@@ -33,6 +34,12 @@ func NewWatcher(ctx context.Context, callback func(string)) (*Watcher, error) {
 	}, nil
 }
 
+// OnRemove registers a callback called when a watched file or directory is
+// removed or renamed. Removals are ignored if no callback is registered.
+func (w *Watcher) OnRemove(callback func(string)) {
+	w.onRemove = callback
+}
+
 func (w *Watcher) Add(watchPaths ...string) error {
 	for _, watchPath := range watchPaths {
 		isDir, err := isDirectory(watchPath)
@@ -80,6 +87,9 @@ func (w *Watcher) Watch() {
 				if event.Has(fsnotify.Create) || event.Has(fsnotify.Write) {
 					w.logger.Debug("detected file change:", slog.String("file", event.Name), slog.String("op", event.Op.String()))
 					w.callback(event.Name)
+				} else if w.onRemove != nil && (event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename)) {
+					w.logger.Debug("detected file removal:", slog.String("file", event.Name), slog.String("op", event.Op.String()))
+					w.onRemove(event.Name)
 				}
 			case err, ok := <-w.notifier.Errors:
 				if !ok {